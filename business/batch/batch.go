@@ -0,0 +1,171 @@
+// Package batch parses uploaded files of bulk transfers.
+package batch
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+)
+
+// Supported upload formats.
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// MaxLines limits the number of transfers in a single batch.
+const MaxLines = 1000
+
+// csvColumns are the columns every CSV upload must have, in any order.
+var csvColumns = []string{"from_account_id", "to_account_id", "amount"}
+
+// Line is one transfer of a batch, numbered as it appears in the uploaded file.
+type Line struct {
+	LineNumber    int   `json:"line_number"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	Amount        int64 `json:"amount"`
+}
+
+// Parse reads the transfers of an upload. Every malformed line is reported in a *domain.BatchValidationError.
+func Parse(format string, r io.Reader) ([]Line, error) {
+	var lines []Line
+	var lineErrors []domain.BatchLineError
+	var err error
+	switch format {
+	case FormatCSV:
+		lines, lineErrors, err = parseCSV(r)
+	case FormatJSON:
+		lines, lineErrors, err = parseJSON(r)
+	default:
+		return nil, fmt.Errorf("unknown batch format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(lineErrors) > 0 {
+		return nil, &domain.BatchValidationError{Lines: lineErrors}
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("batch has no transfers")
+	}
+	if len(lines) > MaxLines {
+		return nil, fmt.Errorf("batch has %d transfers, at most %d are allowed", len(lines), MaxLines)
+	}
+	return lines, nil
+}
+
+// FormatOf guesses the upload format from a file name or content type.
+func FormatOf(filename, contentType string) string {
+	switch {
+	case strings.HasSuffix(strings.ToLower(filename), ".json"), strings.HasPrefix(contentType, "application/json"):
+		return FormatJSON
+	case strings.HasSuffix(strings.ToLower(filename), ".csv"), strings.HasPrefix(contentType, "text/csv"):
+		return FormatCSV
+	}
+	return ""
+}
+
+func parseCSV(r io.Reader) ([]Line, []domain.BatchLineError, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read csv header: %v", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range csvColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, nil, fmt.Errorf("csv header is missing the %s column", name)
+		}
+	}
+
+	var lines []Line
+	var lineErrors []domain.BatchLineError
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		lineNumber, _ := reader.FieldPos(0)
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, nil, fmt.Errorf("failed to read csv: %v", err)
+			}
+			lineErrors = append(lineErrors, domain.BatchLineError{Line: parseErr.Line, Error: parseErr.Err.Error()})
+			continue
+		}
+
+		line := Line{LineNumber: lineNumber}
+		var fieldErr error
+		if line.FromAccountID, fieldErr = parseField(record, columns, "from_account_id"); fieldErr == nil {
+			if line.ToAccountID, fieldErr = parseField(record, columns, "to_account_id"); fieldErr == nil {
+				line.Amount, fieldErr = parseField(record, columns, "amount")
+			}
+		}
+		if fieldErr != nil {
+			lineErrors = append(lineErrors, domain.BatchLineError{Line: lineNumber, Error: fieldErr.Error()})
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, lineErrors, nil
+}
+
+// parseField parses the integer in the named column of a CSV record.
+func parseField(record []string, columns map[string]int, name string) (int64, error) {
+	i := columns[name]
+	if i >= len(record) {
+		return 0, fmt.Errorf("missing %s", name)
+	}
+	value, err := strconv.ParseInt(strings.TrimSpace(record[i]), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", name, record[i])
+	}
+	return value, nil
+}
+
+func parseJSON(r io.Reader) ([]Line, []domain.BatchLineError, error) {
+	var raw []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, nil, fmt.Errorf("failed to read json: %v", err)
+	}
+
+	var lines []Line
+	var lineErrors []domain.BatchLineError
+	for i, item := range raw {
+		var transfer struct {
+			FromAccountID *int64 `json:"from_account_id"`
+			ToAccountID   *int64 `json:"to_account_id"`
+			Amount        *int64 `json:"amount"`
+		}
+		lineNumber := i + 1
+		if err := json.Unmarshal(item, &transfer); err != nil {
+			lineErrors = append(lineErrors, domain.BatchLineError{Line: lineNumber, Error: err.Error()})
+			continue
+		}
+		if transfer.FromAccountID == nil || transfer.ToAccountID == nil || transfer.Amount == nil {
+			lineErrors = append(lineErrors, domain.BatchLineError{Line: lineNumber, Error: "from_account_id, to_account_id and amount are required"})
+			continue
+		}
+		lines = append(lines, Line{
+			LineNumber:    lineNumber,
+			FromAccountID: *transfer.FromAccountID,
+			ToAccountID:   *transfer.ToAccountID,
+			Amount:        *transfer.Amount,
+		})
+	}
+	return lines, lineErrors, nil
+}
@@ -0,0 +1,61 @@
+package batch
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCSV(t *testing.T) {
+	input := "amount,from_account_id,to_account_id\n1000,1,2\n 250, 1, 3\n"
+
+	lines, err := Parse(FormatCSV, strings.NewReader(input))
+	require.NoError(t, err)
+	require.Equal(t, []Line{
+		{LineNumber: 2, FromAccountID: 1, ToAccountID: 2, Amount: 1000},
+		{LineNumber: 3, FromAccountID: 1, ToAccountID: 3, Amount: 250},
+	}, lines)
+}
+
+func TestParseCSVReportsEveryInvalidLine(t *testing.T) {
+	input := "from_account_id,to_account_id,amount\n1,2,ten\n1,2,100\nx,2,100\n"
+
+	_, err := Parse(FormatCSV, strings.NewReader(input))
+	var validationErr *domain.BatchValidationError
+	require.True(t, errors.As(err, &validationErr))
+	require.Len(t, validationErr.Lines, 2)
+	require.Equal(t, 2, validationErr.Lines[0].Line)
+	require.Equal(t, 4, validationErr.Lines[1].Line)
+}
+
+func TestParseCSVMissingColumn(t *testing.T) {
+	_, err := Parse(FormatCSV, strings.NewReader("from_account_id,amount\n1,100\n"))
+	require.EqualError(t, err, "csv header is missing the to_account_id column")
+}
+
+func TestParseJSON(t *testing.T) {
+	input := `[{"from_account_id": 1, "to_account_id": 2, "amount": 1000}, {"from_account_id": 1, "amount": 5}]`
+
+	_, err := Parse(FormatJSON, strings.NewReader(input))
+	var validationErr *domain.BatchValidationError
+	require.True(t, errors.As(err, &validationErr))
+	require.Equal(t, []domain.BatchLineError{{Line: 2, Error: "from_account_id, to_account_id and amount are required"}}, validationErr.Lines)
+
+	lines, err := Parse(FormatJSON, strings.NewReader(`[{"from_account_id": 1, "to_account_id": 2, "amount": 1000}]`))
+	require.NoError(t, err)
+	require.Equal(t, []Line{{LineNumber: 1, FromAccountID: 1, ToAccountID: 2, Amount: 1000}}, lines)
+}
+
+func TestParseEmpty(t *testing.T) {
+	_, err := Parse(FormatJSON, strings.NewReader(`[]`))
+	require.EqualError(t, err, "batch has no transfers")
+}
+
+func TestFormatOf(t *testing.T) {
+	require.Equal(t, FormatCSV, FormatOf("payroll.CSV", "application/octet-stream"))
+	require.Equal(t, FormatJSON, FormatOf("", "application/json; charset=utf-8"))
+	require.Equal(t, "", FormatOf("payroll.xlsx", ""))
+}
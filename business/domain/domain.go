@@ -1,9 +1,11 @@
 package domain
 
 import (
+	"fmt"
 	"time"
 
 	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 // LoginUserParams holds parameters for user login.
@@ -19,12 +21,52 @@ type LoginResponse struct {
 	User         db.User `json:"user"`
 }
 
+// Transfer statuses stored in transfers.status.
+const (
+	TransferStatusPending   = "pending"
+	TransferStatusCompleted = "completed"
+	TransferStatusFailed    = "failed"
+	TransferStatusReversed  = "reversed"
+)
+
+// transferTransitions lists the statuses a transfer may move to from each status.
+var transferTransitions = map[string][]string{
+	TransferStatusPending:   {TransferStatusCompleted, TransferStatusFailed},
+	TransferStatusCompleted: {TransferStatusReversed},
+}
+
+// CanTransitionTransfer reports whether a transfer may move from one status to another.
+func CanTransitionTransfer(from, to string) bool {
+	for _, next := range transferTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidTransferStatus returns true if the status is a known transfer status.
+func IsValidTransferStatus(status string) bool {
+	switch status {
+	case TransferStatusPending, TransferStatusCompleted, TransferStatusFailed, TransferStatusReversed:
+		return true
+	}
+	return false
+}
+
 type HandleFundsTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Description   string `json:"description"`
 }
 
+// MaxTransferDescriptionLength limits the free text of a transfer.
+const MaxTransferDescriptionLength = 255
+
+// LowBalanceAlertThreshold is the balance in minor units below which a transfer alerts the account owner.
+const LowBalanceAlertThreshold = 10000
+
 // HandleFundsTransferResult is the result of the transfer transaction
 type HandleFundsTransferResult struct {
 	Transfer    db.Transfer           `json:"transfer"`
@@ -32,6 +74,292 @@ type HandleFundsTransferResult struct {
 	ToAccount   db.Account            `json:"to_account"`
 	FromEntry   db.AccountTransaction `json:"from_entry"`
 	ToEntry     db.AccountTransaction `json:"to_entry"`
+	Fees        []FeeLine             `json:"fees"`
+	TotalFee    int64                 `json:"total_fee"`
+}
+
+// FeeLine is the fee charged on a transfer by one fee schedule.
+type FeeLine struct {
+	FeeScheduleID int64  `json:"fee_schedule_id"`
+	Name          string `json:"name"`
+	FeeAccountID  int64  `json:"fee_account_id"`
+	Amount        int64  `json:"amount"`
+}
+
+// QuoteTransferParams holds the transfer to quote fees for.
+type QuoteTransferParams struct {
+	FromAccountID int64 `json:"from_account_id" query:"from_account_id"`
+	Amount        int64 `json:"amount" query:"amount"`
+}
+
+// TransferQuote is the fee breakdown of a transfer that has not been executed.
+type TransferQuote struct {
+	Amount     int64     `json:"amount"`
+	Currency   string    `json:"currency"`
+	Fees       []FeeLine `json:"fees"`
+	TotalFee   int64     `json:"total_fee"`
+	TotalDebit int64     `json:"total_debit"`
+}
+
+// CreateFeeScheduleParams holds parameters for creating a fee schedule.
+// The fee is FlatFee plus PercentageBps of the amount clamped to [MinFee, MaxFee].
+type CreateFeeScheduleParams struct {
+	Name          string `json:"name" validate:"required"`
+	Currency      string `json:"currency" validate:"required"`
+	ProductType   string `json:"product_type"`
+	FlatFee       int64  `json:"flat_fee"`
+	PercentageBps int32  `json:"percentage_bps"`
+	MinFee        int64  `json:"min_fee"`
+	MaxFee        *int64 `json:"max_fee"`
+	FeeAccountID  int64  `json:"fee_account_id" validate:"required"`
+}
+
+// ListFeeSchedulesParams holds pagination parameters for listing fee schedules.
+type ListFeeSchedulesParams struct {
+	Limit     int32  `json:"limit" query:"limit"`
+	Offset    int32  `json:"offset" query:"offset"`
+	PageToken string `json:"page_token" query:"page_token"`
+}
+
+// ListTransfersParams holds the filters for listing the transfers of an account.
+type ListTransfersParams struct {
+	AccountID int64  `json:"account_id" query:"account_id"`
+	Status    string `json:"status" query:"status"`
+	Limit     int32  `json:"limit" query:"limit"`
+	Offset    int32  `json:"offset" query:"offset"`
+	PageToken string `json:"page_token" query:"page_token"`
+}
+
+// Scheduled transfer statuses stored in scheduled_transfers.status.
+const (
+	ScheduledTransferStatusScheduled = "scheduled"
+	ScheduledTransferStatusExecuted  = "executed"
+	ScheduledTransferStatusFailed    = "failed"
+	ScheduledTransferStatusCancelled = "cancelled"
+)
+
+// CreateScheduledTransferParams holds parameters for scheduling a future-dated transfer.
+type CreateScheduledTransferParams struct {
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	ExecuteAt     time.Time `json:"execute_at"`
+}
+
+// ListScheduledTransfersParams holds the paging parameters for listing scheduled transfers.
+type ListScheduledTransfersParams struct {
+	Limit     int32  `json:"limit" query:"limit"`
+	Offset    int32  `json:"offset" query:"offset"`
+	PageToken string `json:"page_token" query:"page_token"`
+}
+
+// Recurring transfer statuses stored in recurring_transfers.status.
+const (
+	RecurringTransferStatusActive    = "active"
+	RecurringTransferStatusPaused    = "paused"
+	RecurringTransferStatusCompleted = "completed"
+	RecurringTransferStatusDeleted   = "deleted"
+)
+
+// Recurring transfer occurrence statuses stored in recurring_transfer_occurrences.status.
+const (
+	OccurrenceStatusPending  = "pending"
+	OccurrenceStatusExecuted = "executed"
+	OccurrenceStatusFailed   = "failed"
+)
+
+// CreateRecurringTransferParams holds parameters for creating a standing order.
+// Schedule is a standard five field cron expression (or a descriptor such as @monthly) evaluated in UTC.
+type CreateRecurringTransferParams struct {
+	FromAccountID  int64      `json:"from_account_id"`
+	ToAccountID    int64      `json:"to_account_id"`
+	Amount         int64      `json:"amount"`
+	Schedule       string     `json:"schedule"`
+	StartAt        *time.Time `json:"start_at"`
+	EndAt          *time.Time `json:"end_at"`
+	MaxOccurrences *int32     `json:"max_occurrences"`
+}
+
+// ListRecurringTransfersParams holds the paging parameters for listing recurring transfers.
+type ListRecurringTransfersParams struct {
+	Limit     int32  `json:"limit" query:"limit"`
+	Offset    int32  `json:"offset" query:"offset"`
+	PageToken string `json:"page_token" query:"page_token"`
+}
+
+// Hold statuses stored in holds.status.
+const (
+	HoldStatusActive   = "active"
+	HoldStatusCaptured = "captured"
+	HoldStatusReleased = "released"
+	HoldStatusExpired  = "expired"
+)
+
+// CreateHoldParams holds parameters for reserving funds on an account.
+type CreateHoldParams struct {
+	AccountID   int64     `json:"account_id"`
+	ToAccountID int64     `json:"to_account_id"`
+	Amount      int64     `json:"amount"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// CaptureHoldParams holds parameters for capturing a hold. A zero amount captures the remaining amount.
+type CaptureHoldParams struct {
+	Amount int64 `json:"amount"`
+}
+
+// CaptureHoldResult is the result of capturing a hold.
+type CaptureHoldResult struct {
+	Hold     db.Hold                   `json:"hold"`
+	Transfer HandleFundsTransferResult `json:"transfer"`
+}
+
+// UpdateAccountPolicyParams holds the balance policy of an account.
+// A debit may take the balance down to MinBalance - OverdraftLimit unless UnlimitedOverdraft is set.
+type UpdateAccountPolicyParams struct {
+	MinBalance         int64 `json:"min_balance"`
+	OverdraftLimit     int64 `json:"overdraft_limit"`
+	UnlimitedOverdraft bool  `json:"unlimited_overdraft"`
+}
+
+// Account statuses stored in accounts.status.
+const (
+	AccountStatusActive = "active"
+	AccountStatusFrozen = "frozen"
+	AccountStatusClosed = "closed"
+)
+
+// Account lifecycle actions recorded in account_audit_logs.action.
+const (
+	AccountActionFreeze   = "freeze"
+	AccountActionUnfreeze = "unfreeze"
+	AccountActionClose    = "close"
+	AccountActionReopen   = "reopen"
+)
+
+// accountTransition is the status change made by an account lifecycle action.
+type accountTransition struct {
+	to string
+	// from maps each status the action applies to whether only an admin may apply it there.
+	from map[string]bool
+}
+
+// accountTransitions lists the status change made by each account lifecycle action.
+var accountTransitions = map[string]accountTransition{
+	AccountActionFreeze:   {to: AccountStatusFrozen, from: map[string]bool{AccountStatusActive: false}},
+	AccountActionUnfreeze: {to: AccountStatusActive, from: map[string]bool{AccountStatusFrozen: true}},
+	AccountActionClose:    {to: AccountStatusClosed, from: map[string]bool{AccountStatusActive: false, AccountStatusFrozen: true}},
+	AccountActionReopen:   {to: AccountStatusActive, from: map[string]bool{AccountStatusClosed: false}},
+}
+
+// TransitionAccount returns the status an account in status from moves to when action is applied.
+// Some transitions, such as unfreezing, are reserved for admins.
+func TransitionAccount(action, from string, admin bool) (string, error) {
+	transition, ok := accountTransitions[action]
+	if !ok {
+		return "", fmt.Errorf("unknown account action %q", action)
+	}
+	adminOnly, ok := transition.from[from]
+	if !ok {
+		return "", fmt.Errorf("cannot %s a %s account", action, from)
+	}
+	if adminOnly && !admin {
+		return "", fmt.Errorf("only an admin may %s a %s account", action, from)
+	}
+	return transition.to, nil
+}
+
+// Account product types stored in accounts.product_type.
+const (
+	AccountProductChecking = "checking"
+	AccountProductSavings  = "savings"
+	AccountProductEnvelope = "envelope"
+)
+
+// IsValidAccountProductType returns true if the product type is a known account product type.
+func IsValidAccountProductType(productType string) bool {
+	switch productType {
+	case AccountProductChecking, AccountProductSavings, AccountProductEnvelope:
+		return true
+	}
+	return false
+}
+
+// CreateAccountParams holds parameters for opening a named account.
+// The first account of an owner in a currency becomes the default account for that currency.
+type CreateAccountParams struct {
+	Currency    string `json:"currency" validate:"required"`
+	Nickname    string `json:"nickname"`
+	ProductType string `json:"product_type"`
+}
+
+// ListAccountsParams holds filters and pagination parameters for listing a user's accounts.
+type ListAccountsParams struct {
+	Currency    string `json:"currency" query:"currency"`
+	ProductType string `json:"product_type" query:"product_type"`
+	Status      string `json:"status" query:"status"`
+	Limit       int32  `json:"limit" query:"limit"`
+	Offset      int32  `json:"offset" query:"offset"`
+	PageToken   string `json:"page_token" query:"page_token"`
+}
+
+// UpdateInterestRateParams holds the annual interest rate of an account product type.
+type UpdateInterestRateParams struct {
+	AnnualRateBps int32 `json:"annual_rate_bps"`
+}
+
+// ChangeAccountStatusParams holds the optional reason recorded with an account lifecycle action.
+type ChangeAccountStatusParams struct {
+	Reason string `json:"reason"`
+}
+
+// ListAccountAuditLogsParams holds pagination parameters for listing an account's audit log.
+type ListAccountAuditLogsParams struct {
+	Limit     int32  `json:"limit" query:"limit"`
+	Offset    int32  `json:"offset" query:"offset"`
+	PageToken string `json:"page_token" query:"page_token"`
+}
+
+// Transfer limit scopes stored in transfer_limits.scope.
+const (
+	TransferLimitScopeRole    = "role"
+	TransferLimitScopeUser    = "user"
+	TransferLimitScopeAccount = "account"
+)
+
+// IsValidTransferLimitScope returns true if the scope is a known transfer limit scope.
+func IsValidTransferLimitScope(scope string) bool {
+	switch scope {
+	case TransferLimitScopeRole, TransferLimitScopeUser, TransferLimitScopeAccount:
+		return true
+	}
+	return false
+}
+
+// UpdateTransferLimitParams holds the velocity limits of a role, user or account.
+// A nil limit means unlimited.
+type UpdateTransferLimitParams struct {
+	PerTransferAmount *int64 `json:"per_transfer_amount"`
+	DailyAmount       *int64 `json:"daily_amount"`
+	DailyCount        *int32 `json:"daily_count"`
+	MonthlyAmount     *int64 `json:"monthly_amount"`
+	MonthlyCount      *int32 `json:"monthly_count"`
+}
+
+// ErrorCodeLimitExceeded is the error code returned to clients when a transfer breaks a limit.
+const ErrorCodeLimitExceeded = "limit_exceeded"
+
+// LimitExceededError is returned when a transfer would break a velocity limit.
+type LimitExceededError struct {
+	Scope   string `json:"scope"`
+	Subject string `json:"subject"`
+	Limit   string `json:"limit"`
+	Max     int64  `json:"max"`
+	Used    int64  `json:"used"`
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("%s: %s limit of %d for %s %s exceeded (already used %d)", ErrorCodeLimitExceeded, e.Limit, e.Max, e.Scope, e.Subject, e.Used)
 }
 
 type CreateUserResponse struct {
@@ -43,3 +371,315 @@ type CreateUserResponse struct {
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
 }
+
+// Transaction export statuses.
+const (
+	TransactionExportStatusPending    = "pending"
+	TransactionExportStatusProcessing = "processing"
+	TransactionExportStatusCompleted  = "completed"
+	TransactionExportStatusFailed     = "failed"
+)
+
+// ExportTransactionsParams selects the account transactions of an export over [From, To).
+type ExportTransactionsParams struct {
+	AccountID int64
+	Format    string
+	From      time.Time
+	To        time.Time
+}
+
+// TransactionExportResponse is a transaction export with the link its file can be downloaded from once completed.
+type TransactionExportResponse struct {
+	db.TransactionExport
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+// Transfer batch modes.
+const (
+	TransferBatchModeAllOrNothing = "all_or_nothing"
+	TransferBatchModeBestEffort   = "best_effort"
+)
+
+// IsValidTransferBatchMode returns true if the mode is a supported transfer batch mode.
+func IsValidTransferBatchMode(mode string) bool {
+	return mode == TransferBatchModeAllOrNothing || mode == TransferBatchModeBestEffort
+}
+
+// Transfer batch statuses.
+const (
+	TransferBatchStatusPending            = "pending"
+	TransferBatchStatusProcessing         = "processing"
+	TransferBatchStatusCompleted          = "completed"
+	TransferBatchStatusPartiallyCompleted = "partially_completed"
+	TransferBatchStatusFailed             = "failed"
+)
+
+// Transfer batch line statuses.
+const (
+	TransferBatchLineStatusPending   = "pending"
+	TransferBatchLineStatusCompleted = "completed"
+	TransferBatchLineStatusFailed    = "failed"
+)
+
+// ErrorCodeInvalidBatch is the error code of a rejected transfer batch.
+const ErrorCodeInvalidBatch = "invalid_batch"
+
+// BatchLineError describes why one line of a transfer batch was rejected.
+type BatchLineError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// BatchValidationError is returned when lines of a transfer batch are invalid.
+// A rejected batch is not stored, so none of its transfers are executed.
+type BatchValidationError struct {
+	Lines []BatchLineError `json:"lines"`
+}
+
+func (e *BatchValidationError) Error() string {
+	if len(e.Lines) == 1 {
+		return fmt.Sprintf("%s: line %d: %s", ErrorCodeInvalidBatch, e.Lines[0].Line, e.Lines[0].Error)
+	}
+	return fmt.Sprintf("%s: %d lines are invalid, first at line %d: %s", ErrorCodeInvalidBatch, len(e.Lines), e.Lines[0].Line, e.Lines[0].Error)
+}
+
+// ListTransferBatchesParams pages through the transfer batches of a user.
+type ListTransferBatchesParams struct {
+	Limit     int32  `json:"limit" query:"limit"`
+	Offset    int32  `json:"offset" query:"offset"`
+	PageToken string `json:"page_token" query:"page_token"`
+}
+
+// TransferBatchResult is a transfer batch with the result of every line.
+type TransferBatchResult struct {
+	db.TransferBatch
+	Lines []db.TransferBatchLine `json:"lines"`
+}
+
+// ListAccountTransactionsParams holds the paging parameters for listing the transactions of an account.
+type ListAccountTransactionsParams struct {
+	Limit     int32  `json:"limit" query:"limit"`
+	Offset    int32  `json:"offset" query:"offset"`
+	PageToken string `json:"page_token" query:"page_token"`
+}
+
+// TransferPage is one page of transfers and the token of the next page, empty on the last page.
+type TransferPage struct {
+	Transfers     []db.Transfer `json:"transfers"`
+	NextPageToken string        `json:"next_page_token"`
+}
+
+// AccountPage is one page of accounts and the token of the next page, empty on the last page.
+type AccountPage struct {
+	Accounts      []db.Account `json:"accounts"`
+	NextPageToken string       `json:"next_page_token"`
+}
+
+// AccountTransactionPage is one page of account transactions and the token of the next page, empty on the last page.
+type AccountTransactionPage struct {
+	Transactions  []db.AccountTransaction `json:"transactions"`
+	NextPageToken string                  `json:"next_page_token"`
+}
+
+// ScheduledTransferPage is one page of scheduled transfers and the token of the next page, empty on the last page.
+type ScheduledTransferPage struct {
+	ScheduledTransfers []db.ScheduledTransfer `json:"scheduled_transfers"`
+	NextPageToken      string                 `json:"next_page_token"`
+}
+
+// RecurringTransferPage is one page of recurring transfers and the token of the next page, empty on the last page.
+type RecurringTransferPage struct {
+	RecurringTransfers []db.RecurringTransfer `json:"recurring_transfers"`
+	NextPageToken      string                 `json:"next_page_token"`
+}
+
+// TransferBatchPage is one page of transfer batches and the token of the next page, empty on the last page.
+type TransferBatchPage struct {
+	TransferBatches []db.TransferBatch `json:"transfer_batches"`
+	NextPageToken   string             `json:"next_page_token"`
+}
+
+// AccountAuditLogPage is one page of account audit logs and the token of the next page, empty on the last page.
+type AccountAuditLogPage struct {
+	AuditLogs     []db.AccountAuditLog `json:"audit_logs"`
+	NextPageToken string               `json:"next_page_token"`
+}
+
+// WebhookDeliveryPage is one page of webhook deliveries and the token of the next page, empty on the last page.
+type WebhookDeliveryPage struct {
+	Deliveries    []db.WebhookDelivery `json:"deliveries"`
+	NextPageToken string               `json:"next_page_token"`
+}
+
+// FeeSchedulePage is one page of fee schedules and the token of the next page, empty on the last page.
+type FeeSchedulePage struct {
+	FeeSchedules  []db.FeeSchedule `json:"fee_schedules"`
+	NextPageToken string           `json:"next_page_token"`
+}
+
+// User roles stored in users.role.
+const (
+	RoleDepositor = "depositor"
+	RoleBusiness  = "business"
+	RoleAuditor   = "auditor"
+	RoleAdmin     = "admin"
+)
+
+// CanSearchAllTransactions returns true if the role may search the transactions of every account.
+func CanSearchAllTransactions(role string) bool {
+	return role == RoleAuditor || role == RoleAdmin
+}
+
+// Kinds of transaction search results.
+const (
+	TransactionKindTransfer = "transfer"
+	TransactionKindEntry    = "entry"
+)
+
+// TransactionStatusPosted is the status of every account transaction in search results.
+const TransactionStatusPosted = "posted"
+
+// Sort orders of transaction searches.
+const (
+	TransactionSortCreatedAt = "created_at"
+	TransactionSortAmount    = "amount"
+	SortOrderAsc             = "asc"
+	SortOrderDesc            = "desc"
+)
+
+// SearchTransactionsParams holds the filters of a transaction search. Zero values leave a filter unset;
+// amounts are compared by absolute value and the date range is [From, To).
+type SearchTransactionsParams struct {
+	AccountID             int64     `json:"account_id" query:"account_id"`
+	CounterpartyAccountID int64     `json:"counterparty_account_id" query:"counterparty_account_id"`
+	MinAmount             int64     `json:"min_amount" query:"min_amount"`
+	MaxAmount             int64     `json:"max_amount" query:"max_amount"`
+	From                  time.Time `json:"-" query:"-"`
+	To                    time.Time `json:"-" query:"-"`
+	Type                  string    `json:"type" query:"type"`
+	Status                string    `json:"status" query:"status"`
+	Query                 string    `json:"q" query:"q"`
+	Sort                  string    `json:"sort" query:"sort"`
+	Order                 string    `json:"order" query:"order"`
+	Limit                 int32     `json:"limit" query:"limit"`
+	PageToken             string    `json:"page_token" query:"page_token"`
+}
+
+// TransactionSearchPage is one page of search results and the token of the next page, empty on the last page.
+type TransactionSearchPage struct {
+	Results       []db.TransactionSearchResult `json:"results"`
+	NextPageToken string                       `json:"next_page_token"`
+}
+
+// Domain events recorded in the outbox and published as tasks of the same type.
+const (
+	EventUserCreated       = "user.created"
+	EventAccountCreated    = "account.created"
+	EventTransferCompleted = "transfer.completed"
+)
+
+// EventTypes lists the domain events webhooks can subscribe to.
+var EventTypes = []string{EventUserCreated, EventAccountCreated, EventTransferCompleted}
+
+// Aggregates the outbox keeps events in order for.
+const (
+	AggregateUser    = "user"
+	AggregateAccount = "account"
+)
+
+// UserCreatedEvent is recorded when a user signs up.
+type UserCreatedEvent struct {
+	Username string `json:"username"`
+	FullName string `json:"full_name"`
+	Email    string `json:"email"`
+}
+
+// AccountCreatedEvent is recorded when an account is opened.
+type AccountCreatedEvent struct {
+	AccountID   int64  `json:"account_id"`
+	Owner       string `json:"owner"`
+	Currency    string `json:"currency"`
+	ProductType string `json:"product_type"`
+}
+
+// TransferCompletedEvent is recorded when a transfer is completed; FromBalance is the balance
+// of the 'from' account after the transfer and its fees.
+type TransferCompletedEvent struct {
+	TransferID    int64     `json:"transfer_id"`
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	FromOwner     string    `json:"from_owner"`
+	ToOwner       string    `json:"to_owner"`
+	Amount        int64     `json:"amount"`
+	Fee           int64     `json:"fee"`
+	Currency      string    `json:"currency"`
+	FromBalance   int64     `json:"from_balance"`
+	Description   string    `json:"description"`
+	CompletedAt   time.Time `json:"completed_at"`
+}
+
+// Webhook subscription statuses stored in webhook_subscriptions.status.
+const (
+	WebhookStatusActive   = "active"
+	WebhookStatusDisabled = "disabled"
+)
+
+// Webhook delivery statuses stored in webhook_deliveries.status.
+const (
+	WebhookDeliveryStatusPending   = "pending"
+	WebhookDeliveryStatusSucceeded = "succeeded"
+	WebhookDeliveryStatusFailed    = "failed"
+)
+
+// CreateWebhookSubscriptionParams holds the parameters of a new webhook subscription.
+// EventTypes may contain patterns such as "transfer.*"; a secret is generated if none is given.
+type CreateWebhookSubscriptionParams struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Secret     string   `json:"secret"`
+}
+
+// WebhookSubscription is a webhook subscription without its secret.
+type WebhookSubscription struct {
+	ID                  int64              `json:"id"`
+	Owner               string             `json:"owner"`
+	URL                 string             `json:"url"`
+	EventTypes          []string           `json:"event_types"`
+	Status              string             `json:"status"`
+	ConsecutiveFailures int32              `json:"consecutive_failures"`
+	DisabledAt          pgtype.Timestamptz `json:"disabled_at"`
+	CreatedAt           time.Time          `json:"created_at"`
+}
+
+// NewWebhookSubscription hides the secret of a stored subscription.
+func NewWebhookSubscription(sub db.WebhookSubscription) WebhookSubscription {
+	return WebhookSubscription{
+		ID:                  sub.ID,
+		Owner:               sub.Owner,
+		URL:                 sub.Url,
+		EventTypes:          sub.EventTypes,
+		Status:              sub.Status,
+		ConsecutiveFailures: sub.ConsecutiveFailures,
+		DisabledAt:          sub.DisabledAt,
+		CreatedAt:           sub.CreatedAt,
+	}
+}
+
+// CreateWebhookSubscriptionResponse is returned once when a subscription is created and includes its secret.
+type CreateWebhookSubscriptionResponse struct {
+	WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+// ListWebhookDeliveriesParams holds the paging of a subscription's delivery log.
+type ListWebhookDeliveriesParams struct {
+	Limit     int32  `json:"limit" query:"limit"`
+	Offset    int32  `json:"offset" query:"offset"`
+	PageToken string `json:"page_token" query:"page_token"`
+}
+
+// ListArchivedTasksParams holds the paging of the archived tasks of a queue; pages start at 1.
+type ListArchivedTasksParams struct {
+	Page     int `json:"page" query:"page"`
+	PageSize int `json:"page_size" query:"page_size"`
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanTransitionTransfer(t *testing.T) {
+	testCases := []struct {
+		from string
+		to   string
+		ok   bool
+	}{
+		{TransferStatusPending, TransferStatusCompleted, true},
+		{TransferStatusPending, TransferStatusFailed, true},
+		{TransferStatusCompleted, TransferStatusReversed, true},
+		{TransferStatusPending, TransferStatusReversed, false},
+		{TransferStatusFailed, TransferStatusReversed, false},
+		{TransferStatusFailed, TransferStatusCompleted, false},
+		{TransferStatusReversed, TransferStatusCompleted, false},
+		{TransferStatusCompleted, TransferStatusPending, false},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.ok, CanTransitionTransfer(tc.from, tc.to), "%s -> %s", tc.from, tc.to)
+	}
+}
+
+func TestTransitionAccount(t *testing.T) {
+	testCases := []struct {
+		action string
+		from   string
+		admin  bool
+		to     string
+	}{
+		{AccountActionFreeze, AccountStatusActive, false, AccountStatusFrozen},
+		{AccountActionUnfreeze, AccountStatusFrozen, true, AccountStatusActive},
+		{AccountActionUnfreeze, AccountStatusFrozen, false, ""},
+		{AccountActionClose, AccountStatusActive, false, AccountStatusClosed},
+		{AccountActionClose, AccountStatusFrozen, false, ""},
+		{AccountActionClose, AccountStatusFrozen, true, AccountStatusClosed},
+		{AccountActionReopen, AccountStatusClosed, false, AccountStatusActive},
+		{AccountActionReopen, AccountStatusActive, true, ""},
+		{AccountActionFreeze, AccountStatusClosed, true, ""},
+		{"delete", AccountStatusActive, true, ""},
+	}
+
+	for _, tc := range testCases {
+		to, err := TransitionAccount(tc.action, tc.from, tc.admin)
+		if tc.to == "" {
+			require.Error(t, err, "%s %s (admin %t)", tc.action, tc.from, tc.admin)
+			continue
+		}
+		require.NoError(t, err, "%s %s (admin %t)", tc.action, tc.from, tc.admin)
+		require.Equal(t, tc.to, to)
+	}
+}
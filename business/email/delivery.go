@@ -0,0 +1,57 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/fadedreams/gofinanceflow/business/tasks"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+)
+
+// Users looks up the recipients of emails.
+type Users interface {
+	GetUser(ctx context.Context, username string) (db.User, error)
+}
+
+// Deliverer handles email delivery tasks by rendering the email and sending it through a Mailer.
+type Deliverer struct {
+	users  Users
+	mailer Mailer
+}
+
+// NewDeliverer creates a Deliverer that sends emails to users through mailer.
+func NewDeliverer(users Users, mailer Mailer) *Deliverer {
+	return &Deliverer{users: users, mailer: mailer}
+}
+
+// HandleEmailDeliveryTask renders and sends the email of a delivery task. Sending errors are retried with
+// asynq's backoff until the task goes to the archive, which serves as dead-letter queue; emails that can never
+// be sent are archived right away.
+func (d *Deliverer) HandleEmailDeliveryTask(ctx context.Context, p tasks.EmailDeliveryPayload) error {
+	user, err := d.users.GetUser(ctx, p.Username)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("user %s not found: %w", p.Username, asynq.SkipRetry)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get user: %v", err)
+	}
+
+	msg, err := Render(p.TemplateID, Recipient{
+		Username: user.Username,
+		FullName: user.FullName,
+		Email:    user.Email,
+	}, p.Data)
+	if err != nil {
+		return fmt.Errorf("%v: %w", err, asynq.SkipRetry)
+	}
+
+	if err := d.mailer.Send(ctx, msg); err != nil {
+		return err
+	}
+	log.Printf("Sent email %s to user %s", p.TemplateID, p.Username)
+	return nil
+}
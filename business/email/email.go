@@ -0,0 +1,153 @@
+// Package email renders the emails sent to users and delivers them through a Mailer.
+package email
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"slices"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// Templates of the emails sent to users.
+const (
+	TemplateWelcome                 = "welcome"
+	TemplateVerification            = "verification"
+	TemplatePasswordReset           = "password-reset"
+	TemplateTransferReceipt         = "transfer-receipt"
+	TemplateLowBalance              = "low-balance"
+	TemplateScheduledTransferFailed = "scheduled-transfer-failed"
+)
+
+var templateIDs = []string{
+	TemplateWelcome,
+	TemplateVerification,
+	TemplatePasswordReset,
+	TemplateTransferReceipt,
+	TemplateLowBalance,
+	TemplateScheduledTransferFailed,
+}
+
+// ErrUnknownTemplate is returned when rendering a template that does not exist.
+var ErrUnknownTemplate = errors.New("unknown email template")
+
+//go:embed templates
+var templateFS embed.FS
+
+// Every template has a text part, which also defines the subject, and an HTML part rendered into the layout.
+var (
+	textTemplates = texttemplate.Must(texttemplate.New("").Option("missingkey=zero").ParseFS(templateFS, "templates/*.txt.tmpl"))
+	htmlTemplates = parseHTMLTemplates()
+)
+
+func parseHTMLTemplates() map[string]*htmltemplate.Template {
+	templates := make(map[string]*htmltemplate.Template, len(templateIDs))
+	for _, id := range templateIDs {
+		templates[id] = htmltemplate.Must(htmltemplate.New("").Option("missingkey=zero").ParseFS(templateFS, "templates/layout.html.tmpl", "templates/"+id+".html.tmpl"))
+	}
+	return templates
+}
+
+// Message is a rendered email.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Recipient is the user an email is addressed to.
+type Recipient struct {
+	Username string
+	FullName string
+	Email    string
+}
+
+// TemplateData is passed to the templates; Data holds the template specific values.
+type TemplateData struct {
+	Recipient Recipient
+	Data      map[string]string
+}
+
+// IsValidTemplate reports whether templateID names an email template.
+func IsValidTemplate(templateID string) bool {
+	return slices.Contains(templateIDs, templateID)
+}
+
+// Render renders the email of a template addressed to the recipient.
+func Render(templateID string, recipient Recipient, data map[string]string) (Message, error) {
+	if !IsValidTemplate(templateID) {
+		return Message{}, fmt.Errorf("%w: %s", ErrUnknownTemplate, templateID)
+	}
+	td := TemplateData{Recipient: recipient, Data: data}
+
+	var subject, text, html bytes.Buffer
+	if err := textTemplates.ExecuteTemplate(&subject, templateID+".subject", td); err != nil {
+		return Message{}, fmt.Errorf("failed to render subject of %s: %v", templateID, err)
+	}
+	if err := textTemplates.ExecuteTemplate(&text, templateID+".txt.tmpl", td); err != nil {
+		return Message{}, fmt.Errorf("failed to render text of %s: %v", templateID, err)
+	}
+	if err := htmlTemplates[templateID].ExecuteTemplate(&html, "layout", td); err != nil {
+		return Message{}, fmt.Errorf("failed to render html of %s: %v", templateID, err)
+	}
+
+	return Message{
+		To:      recipient.Email,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		HTML:    html.String(),
+	}, nil
+}
+
+// Mailer sends rendered emails.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// writeMessage writes msg as a multipart/alternative MIME message from the given sender.
+func writeMessage(w io.Writer, from string, msg Message) error {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {part.contentType}})
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(pw, part.content); err != nil {
+			return err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return err
+	}
+
+	headers := []string{
+		"From: " + from,
+		"To: " + msg.To,
+		"Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/alternative; boundary=" + mw.Boundary(),
+	}
+	if _, err := io.WriteString(w, strings.Join(headers, "\r\n")+"\r\n\r\n"); err != nil {
+		return err
+	}
+	_, err := body.WriteTo(w)
+	return err
+}
@@ -0,0 +1,122 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fadedreams/gofinanceflow/business/tasks"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	recipient := Recipient{Username: "alice", FullName: "Alice <Admin>", Email: "alice@example.com"}
+	for _, id := range templateIDs {
+		t.Run(id, func(t *testing.T) {
+			msg, err := Render(id, recipient, map[string]string{"link": "https://example.com/?a=1&b=2"})
+			require.NoError(t, err)
+			require.Equal(t, "alice@example.com", msg.To)
+			require.NotEmpty(t, msg.Subject)
+			require.NotContains(t, msg.Subject, "\n")
+			require.Contains(t, msg.Text, "Alice <Admin>")
+			require.Contains(t, msg.HTML, "Alice &lt;Admin&gt;")
+			require.NotContains(t, msg.Text, "<no value>")
+			require.NotContains(t, msg.HTML, "<no value>")
+		})
+	}
+
+	msg, err := Render(TemplateTransferReceipt, recipient, map[string]string{
+		"transfer_id": "42",
+		"amount":      "12.50",
+		"currency":    "USD",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Transfer 42 completed", msg.Subject)
+	require.Contains(t, msg.Text, "12.50 USD")
+	require.NotContains(t, msg.Text, "Description")
+
+	_, err = Render("unknown", recipient, nil)
+	require.ErrorIs(t, err, ErrUnknownTemplate)
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "outbox")
+	mailer, err := NewFileMailer(dir, "bank@example.com")
+	require.NoError(t, err)
+
+	msg, err := Render(TemplateWelcome, Recipient{Username: "bob", Email: "bob@example.com"}, nil)
+	require.NoError(t, err)
+	require.NoError(t, mailer.Send(context.Background(), msg))
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	content, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	require.NoError(t, err)
+	require.Contains(t, string(content), "To: bob@example.com\r\n")
+	require.Contains(t, string(content), "multipart/alternative")
+	require.True(t, strings.Contains(string(content), "text/plain") && strings.Contains(string(content), "text/html"))
+}
+
+type fakeUsers map[string]db.User
+
+func (u fakeUsers) GetUser(ctx context.Context, username string) (db.User, error) {
+	user, ok := u[username]
+	if !ok {
+		return db.User{}, pgx.ErrNoRows
+	}
+	return user, nil
+}
+
+type fakeMailer struct {
+	sent []Message
+	err  error
+}
+
+func (m *fakeMailer) Send(ctx context.Context, msg Message) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func TestDeliverer(t *testing.T) {
+	users := fakeUsers{"carol": {Username: "carol", Email: "carol@example.com"}}
+	mailer := &fakeMailer{}
+	d := NewDeliverer(users, mailer)
+	ctx := context.Background()
+
+	require.NoError(t, d.HandleEmailDeliveryTask(ctx, tasks.EmailDeliveryPayload{Username: "carol", TemplateID: TemplateWelcome}))
+	require.Len(t, mailer.sent, 1)
+	require.Equal(t, "carol@example.com", mailer.sent[0].To)
+
+	err := d.HandleEmailDeliveryTask(ctx, tasks.EmailDeliveryPayload{Username: "dave", TemplateID: TemplateWelcome})
+	require.ErrorIs(t, err, asynq.SkipRetry)
+	err = d.HandleEmailDeliveryTask(ctx, tasks.EmailDeliveryPayload{Username: "carol", TemplateID: "unknown"})
+	require.ErrorIs(t, err, asynq.SkipRetry)
+
+	mailer.err = errors.New("connection refused")
+	err = d.HandleEmailDeliveryTask(ctx, tasks.EmailDeliveryPayload{Username: "carol", TemplateID: TemplateWelcome})
+	require.Error(t, err)
+	require.NotErrorIs(t, err, asynq.SkipRetry)
+}
+
+func TestDelivererDeadLetter(t *testing.T) {
+	mailer := &fakeMailer{err: errors.New("connection refused")}
+	tm := tasks.NewMemoryTaskManager(1)
+	tasks.Register(tm, tasks.TypeEmailDelivery, NewDeliverer(fakeUsers{"erin": {Username: "erin"}}, mailer).HandleEmailDeliveryTask)
+
+	require.NoError(t, tm.EnqueueEmailDeliveryTask("erin", TemplateWelcome, nil))
+	require.Error(t, tm.Drain(context.Background()))
+
+	archived := tm.Archived(tasks.TypeEmailDelivery)
+	require.Len(t, archived, 1)
+	require.Equal(t, tasks.EmailDeliveryMaxRetry, archived[0].Retried)
+}
@@ -0,0 +1,43 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// FileMailer writes emails as .eml files to an outbox directory instead of sending them,
+// so they can be inspected during local development.
+type FileMailer struct {
+	dir  string
+	from string
+	seq  atomic.Int64
+}
+
+// NewFileMailer creates a mailer that writes emails to dir, creating it if needed.
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create outbox %s: %v", dir, err)
+	}
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+// Send writes msg to a new file in the outbox.
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%d.eml", time.Now().UTC().Format("20060102T150405.000000000"), m.seq.Add(1))
+	file, err := os.Create(filepath.Join(m.dir, name))
+	if err != nil {
+		return fmt.Errorf("failed to create email file: %v", err)
+	}
+	if err := writeMessage(file, m.from, msg); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write email file: %v", err)
+	}
+	return file.Close()
+}
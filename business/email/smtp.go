@@ -0,0 +1,44 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+)
+
+// SMTPMailer sends emails through an SMTP server.
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPMailer creates a mailer that sends emails from the given address through host:port,
+// authenticating with PLAIN auth if a username is given.
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	m := &SMTPMailer{
+		addr: net.JoinHostPort(host, fmt.Sprint(port)),
+		from: from,
+	}
+	if username != "" {
+		m.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return m
+}
+
+// Send sends msg. net/smtp does not support contexts, so ctx is only checked before sending.
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := writeMessage(&buf, m.from, msg); err != nil {
+		return fmt.Errorf("failed to write email: %v", err)
+	}
+	if err := smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to send email to %s: %v", msg.To, err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/fadedreams/gofinanceflow/foundation/sdk"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+)
+
+// camt053Writer writes an ISO 20022 camt.053.001.02 bank-to-customer statement.
+type camt053Writer struct {
+	w      *bufio.Writer
+	header Header
+}
+
+func newCamt053Writer(w io.Writer) *camt053Writer {
+	return &camt053Writer{w: bufio.NewWriter(w)}
+}
+
+func (cw *camt053Writer) WriteHeader(h Header) error {
+	cw.header = h
+	currency := h.Account.Currency
+	id := fmt.Sprintf("STMT-%d-%s", h.Account.ID, h.GeneratedAt.UTC().Format("20060102150405"))
+
+	_, err := fmt.Fprintf(cw.w, `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+<BkToCstmrStmt>
+<GrpHdr><MsgId>%s</MsgId><CreDtTm>%s</CreDtTm></GrpHdr>
+<Stmt>
+<Id>%s</Id>
+<CreDtTm>%s</CreDtTm>
+<FrToDt><FrDtTm>%s</FrDtTm><ToDtTm>%s</ToDtTm></FrToDt>
+<Acct><Id><Othr><Id>%d</Id></Othr></Id><Ccy>%s</Ccy></Acct>
+%s
+%s
+`, id, camtTime(h.GeneratedAt), id, camtTime(h.GeneratedAt), camtTime(h.From), camtTime(h.To), h.Account.ID, currency,
+		camtBalance("OPBD", h.OpeningBalance, currency, h.From),
+		camtBalance("CLBD", h.ClosingBalance, currency, h.To))
+	return err
+}
+
+func (cw *camt053Writer) WriteEntry(entry db.AccountTransaction) error {
+	currency := cw.header.Account.Currency
+	_, err := fmt.Fprintf(cw.w, `<Ntry><NtryRef>%d</NtryRef><Amt Ccy="%s">%s</Amt><CdtDbtInd>%s</CdtDbtInd><Sts>BOOK</Sts><BookgDt><DtTm>%s</DtTm></BookgDt><ValDt><DtTm>%s</DtTm></ValDt><BkTxCd><Prtry><Cd>%s</Cd></Prtry></BkTxCd></Ntry>
+`, entry.ID, currency, sdk.FormatAmount(abs(entry.Amount), currency), creditDebit(entry.Amount),
+		camtTime(entry.CreatedAt), camtTime(entry.CreatedAt), description(entry.Amount))
+	return err
+}
+
+func (cw *camt053Writer) Close() error {
+	if _, err := fmt.Fprint(cw.w, "</Stmt>\n</BkToCstmrStmt>\n</Document>\n"); err != nil {
+		return err
+	}
+	return cw.w.Flush()
+}
+
+// camtBalance formats a statement balance of the given type, such as OPBD or CLBD.
+func camtBalance(balanceType string, amount int64, currency string, at time.Time) string {
+	return fmt.Sprintf(`<Bal><Tp><CdOrPrtry><Cd>%s</Cd></CdOrPrtry></Tp><Amt Ccy="%s">%s</Amt><CdtDbtInd>%s</CdtDbtInd><Dt><DtTm>%s</DtTm></Dt></Bal>`,
+		balanceType, currency, sdk.FormatAmount(abs(amount), currency), creditDebit(amount), camtTime(at))
+}
+
+// creditDebit returns the ISO 20022 credit/debit indicator of an amount.
+func creditDebit(amount int64) string {
+	if amount < 0 {
+		return "DBIT"
+	}
+	return "CRDT"
+}
+
+// camtTime formats a time as an ISO 20022 date time in UTC.
+func camtTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05Z")
+}
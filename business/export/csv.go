@@ -0,0 +1,41 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/fadedreams/gofinanceflow/foundation/sdk"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+)
+
+// csvWriter writes one row per account transaction.
+type csvWriter struct {
+	w        *csv.Writer
+	currency string
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (cw *csvWriter) WriteHeader(h Header) error {
+	cw.currency = h.Account.Currency
+	return cw.w.Write([]string{"date", "id", "description", "amount", "currency"})
+}
+
+func (cw *csvWriter) WriteEntry(entry db.AccountTransaction) error {
+	return cw.w.Write([]string{
+		entry.CreatedAt.UTC().Format(time.RFC3339),
+		strconv.FormatInt(entry.ID, 10),
+		description(entry.Amount),
+		sdk.FormatAmount(entry.Amount, cw.currency),
+		cw.currency,
+	})
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
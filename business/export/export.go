@@ -0,0 +1,98 @@
+// Package export writes account transactions in formats accounting software can import.
+package export
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+)
+
+// Supported export formats.
+const (
+	FormatCSV     = "csv"
+	FormatOFX     = "ofx"
+	FormatCamt053 = "camt053"
+)
+
+// Header describes the account and period of an export.
+type Header struct {
+	Account        db.Account
+	From           time.Time
+	To             time.Time
+	OpeningBalance int64
+	ClosingBalance int64
+	GeneratedAt    time.Time
+}
+
+// Writer writes an export one account transaction at a time, so entries can be
+// streamed straight from the database.
+type Writer interface {
+	WriteHeader(h Header) error
+	WriteEntry(entry db.AccountTransaction) error
+	// Close writes the trailer and flushes buffered output without closing the underlying writer.
+	Close() error
+}
+
+// NewWriter returns a Writer producing the given format on w.
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatOFX:
+		return newOFXWriter(w), nil
+	case FormatCamt053:
+		return newCamt053Writer(w), nil
+	}
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+// IsValidFormat returns true if the format is a supported export format.
+func IsValidFormat(format string) bool {
+	switch format {
+	case FormatCSV, FormatOFX, FormatCamt053:
+		return true
+	}
+	return false
+}
+
+// ContentType returns the MIME type of an export format.
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv"
+	case FormatOFX:
+		return "application/x-ofx"
+	case FormatCamt053:
+		return "application/xml"
+	}
+	return "application/octet-stream"
+}
+
+// FileExtension returns the file name extension of an export format.
+func FileExtension(format string) string {
+	switch format {
+	case FormatOFX:
+		return ".ofx"
+	case FormatCamt053:
+		return ".xml"
+	}
+	return ".csv"
+}
+
+// description names the direction of an account transaction.
+func description(amount int64) string {
+	if amount < 0 {
+		return "Debit"
+	}
+	return "Credit"
+}
+
+// abs returns the absolute value of an amount.
+func abs(amount int64) int64 {
+	if amount < 0 {
+		return -amount
+	}
+	return amount
+}
@@ -0,0 +1,101 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestExport(t *testing.T, format string) string {
+	from := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	header := Header{
+		Account:        db.Account{ID: 7, Owner: "alice", Currency: "USD", ProductType: "savings"},
+		From:           from,
+		To:             from.AddDate(0, 1, 0),
+		OpeningBalance: 5000,
+		ClosingBalance: 5750,
+		GeneratedAt:    from.AddDate(0, 1, 1),
+	}
+	entries := []db.AccountTransaction{
+		{ID: 1, AccountID: 7, Amount: 1000, CreatedAt: from.Add(time.Hour)},
+		{ID: 2, AccountID: 7, Amount: -250, CreatedAt: from.Add(2 * time.Hour)},
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteHeader(header))
+	for _, entry := range entries {
+		require.NoError(t, w.WriteEntry(entry))
+	}
+	require.NoError(t, w.Close())
+	return buf.String()
+}
+
+func TestCSVExport(t *testing.T) {
+	records, err := csv.NewReader(strings.NewReader(writeTestExport(t, FormatCSV))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	require.Equal(t, []string{"2024-05-01T02:00:00Z", "2", "Debit", "-2.50", "USD"}, records[2])
+}
+
+func TestOFXExport(t *testing.T) {
+	out := writeTestExport(t, FormatOFX)
+
+	require.True(t, strings.HasPrefix(out, `<?xml version="1.0"`))
+	require.Contains(t, out, `<ACCTTYPE>SAVINGS</ACCTTYPE>`)
+	require.Contains(t, out, `<TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20240501020000.000[0:UTC]</DTPOSTED><TRNAMT>-2.50</TRNAMT><FITID>2</FITID>`)
+	require.Contains(t, out, `<BALAMT>57.50</BALAMT>`)
+	requireWellFormed(t, out)
+}
+
+func TestCamt053Export(t *testing.T) {
+	out := writeTestExport(t, FormatCamt053)
+
+	var doc struct {
+		Stmt struct {
+			Bal []struct {
+				Cd  string `xml:"Tp>CdOrPrtry>Cd"`
+				Amt string `xml:"Amt"`
+			} `xml:"Bal"`
+			Ntry []struct {
+				Amt struct {
+					Value string `xml:",chardata"`
+					Ccy   string `xml:"Ccy,attr"`
+				} `xml:"Amt"`
+				CdtDbtInd string `xml:"CdtDbtInd"`
+			} `xml:"Ntry"`
+		} `xml:"BkToCstmrStmt>Stmt"`
+	}
+	require.NoError(t, xml.Unmarshal([]byte(out), &doc))
+	require.Len(t, doc.Stmt.Bal, 2)
+	require.Equal(t, "OPBD", doc.Stmt.Bal[0].Cd)
+	require.Equal(t, "57.50", doc.Stmt.Bal[1].Amt)
+	require.Len(t, doc.Stmt.Ntry, 2)
+	require.Equal(t, "2.50", doc.Stmt.Ntry[1].Amt.Value)
+	require.Equal(t, "USD", doc.Stmt.Ntry[1].Amt.Ccy)
+	require.Equal(t, "DBIT", doc.Stmt.Ntry[1].CdtDbtInd)
+}
+
+func TestNewWriterUnknownFormat(t *testing.T) {
+	_, err := NewWriter("qif", &bytes.Buffer{})
+	require.Error(t, err)
+}
+
+// requireWellFormed checks that the document parses as XML.
+func requireWellFormed(t *testing.T, doc string) {
+	decoder := xml.NewDecoder(strings.NewReader(doc))
+	for {
+		_, err := decoder.Token()
+		if err != nil {
+			require.Equal(t, "EOF", err.Error())
+			return
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	"github.com/fadedreams/gofinanceflow/foundation/sdk"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+)
+
+// ofxBankID identifies this institution in OFX files, which allow at most 9 characters.
+const ofxBankID = "GOFINFLOW"
+
+// ofxWriter writes an OFX 2.2 bank statement response.
+type ofxWriter struct {
+	w      *bufio.Writer
+	header Header
+}
+
+func newOFXWriter(w io.Writer) *ofxWriter {
+	return &ofxWriter{w: bufio.NewWriter(w)}
+}
+
+func (ow *ofxWriter) WriteHeader(h Header) error {
+	ow.header = h
+	accountType := "CHECKING"
+	if h.Account.ProductType == domain.AccountProductSavings {
+		accountType = "SAVINGS"
+	}
+
+	_, err := fmt.Fprintf(ow.w, `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS><DTSERVER>%s</DTSERVER><LANGUAGE>ENG</LANGUAGE></SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1><STMTTRNRS><TRNUID>0</TRNUID><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>
+<STMTRS><CURDEF>%s</CURDEF>
+<BANKACCTFROM><BANKID>%s</BANKID><ACCTID>%d</ACCTID><ACCTTYPE>%s</ACCTTYPE></BANKACCTFROM>
+<BANKTRANLIST><DTSTART>%s</DTSTART><DTEND>%s</DTEND>
+`, ofxTime(h.GeneratedAt), h.Account.Currency, ofxBankID, h.Account.ID, accountType, ofxTime(h.From), ofxTime(h.To))
+	return err
+}
+
+func (ow *ofxWriter) WriteEntry(entry db.AccountTransaction) error {
+	transactionType := "CREDIT"
+	if entry.Amount < 0 {
+		transactionType = "DEBIT"
+	}
+	_, err := fmt.Fprintf(ow.w, "<STMTTRN><TRNTYPE>%s</TRNTYPE><DTPOSTED>%s</DTPOSTED><TRNAMT>%s</TRNAMT><FITID>%d</FITID><NAME>%s</NAME></STMTTRN>\n",
+		transactionType, ofxTime(entry.CreatedAt), sdk.FormatAmount(entry.Amount, ow.header.Account.Currency), entry.ID, description(entry.Amount))
+	return err
+}
+
+func (ow *ofxWriter) Close() error {
+	_, err := fmt.Fprintf(ow.w, `</BANKTRANLIST>
+<LEDGERBAL><BALAMT>%s</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`, sdk.FormatAmount(ow.header.ClosingBalance, ow.header.Account.Currency), ofxTime(ow.header.To))
+	if err != nil {
+		return err
+	}
+	return ow.w.Flush()
+}
+
+// ofxTime formats a time as an OFX datetime in UTC.
+func ofxTime(t time.Time) string {
+	return t.UTC().Format("20060102150405.000") + "[0:UTC]"
+}
@@ -0,0 +1,48 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/fadedreams/gofinanceflow/foundation/sdk"
+)
+
+// WriteCSV writes the statement as CSV with one row per account transaction,
+// framed by opening and closing balance rows.
+func WriteCSV(w io.Writer, s Statement) error {
+	cw := csv.NewWriter(w)
+	format := func(amount int64) string {
+		return sdk.FormatAmount(amount, s.Currency)
+	}
+
+	records := [][]string{
+		{"date", "id", "description", "amount", "balance", "currency"},
+		{s.From.UTC().Format(time.RFC3339), "", "Opening balance", "", format(s.OpeningBalance), s.Currency},
+	}
+	for _, line := range s.Lines {
+		records = append(records, []string{
+			line.CreatedAt.UTC().Format(time.RFC3339),
+			strconv.FormatInt(line.ID, 10),
+			description(line.Amount),
+			format(line.Amount),
+			format(line.RunningBalance),
+			s.Currency,
+		})
+	}
+	records = append(records, []string{s.LastDay().UTC().Format(time.DateOnly), "", "Closing balance", "", format(s.ClosingBalance), s.Currency})
+
+	if err := cw.WriteAll(records); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// description names the direction of an account transaction.
+func description(amount int64) string {
+	if amount < 0 {
+		return "Debit"
+	}
+	return "Credit"
+}
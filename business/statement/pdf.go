@@ -0,0 +1,128 @@
+package statement
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/fadedreams/gofinanceflow/foundation/sdk"
+)
+
+// PDF layout on an A4 page in points, using the built-in Courier font so columns line up.
+const (
+	pdfPageWidth    = 595
+	pdfPageHeight   = 842
+	pdfMargin       = 50
+	pdfFontSize     = 9
+	pdfLeading      = 13
+	pdfLinesPerPage = (pdfPageHeight - 2*pdfMargin) / pdfLeading
+)
+
+// WritePDF writes the statement as a self-contained PDF document.
+func WritePDF(w io.Writer, s Statement) error {
+	pages := paginate(pdfLines(s), pdfLinesPerPage)
+
+	// Objects 1-3 are the catalog, the page tree and the font, then a page and its content per page
+	var buf bytes.Buffer
+	offsets := make([]int, 0, 3+2*len(pages))
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>")
+
+	for i, page := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 5+2*i))
+
+		var content strings.Builder
+		fmt.Fprintf(&content, "BT /F1 %d Tf %d TL %d %d Td\n", pdfFontSize, pdfLeading, pdfMargin, pdfPageHeight-pdfMargin)
+		for _, line := range page {
+			fmt.Fprintf(&content, "(%s) Tj T*\n", pdfEscape(line))
+		}
+		fmt.Fprintf(&content, "(%s) Tj\nET", pdfEscape(fmt.Sprintf("Page %d of %d", i+1, len(pages))))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// pdfLines lays the statement out as fixed-width text lines.
+func pdfLines(s Statement) []string {
+	format := func(amount int64) string {
+		return sdk.FormatAmount(amount, s.Currency)
+	}
+	const row = "%-20s %-10s %-8s %18s %18s"
+
+	lines := []string{
+		"Account statement",
+		"",
+		fmt.Sprintf("Account:  %d (%s)", s.AccountID, s.Currency),
+		fmt.Sprintf("Owner:    %s", s.Owner),
+		fmt.Sprintf("Period:   %s - %s", s.From.UTC().Format(time.DateOnly), s.LastDay().UTC().Format(time.DateOnly)),
+		"",
+		fmt.Sprintf(row, "Date", "Id", "Type", "Amount", "Balance"),
+		fmt.Sprintf(row, s.From.UTC().Format(time.DateTime), "", "Opening", "", format(s.OpeningBalance)),
+	}
+	for _, line := range s.Lines {
+		lines = append(lines, fmt.Sprintf(row,
+			line.CreatedAt.UTC().Format(time.DateTime),
+			fmt.Sprint(line.ID),
+			description(line.Amount),
+			format(line.Amount),
+			format(line.RunningBalance),
+		))
+	}
+	return append(lines,
+		fmt.Sprintf(row, s.LastDay().UTC().Format(time.DateOnly), "", "Closing", "", format(s.ClosingBalance)),
+		"",
+		fmt.Sprintf("Total credits: %s", format(s.TotalCredits)),
+		fmt.Sprintf("Total debits:  %s", format(s.TotalDebits)),
+	)
+}
+
+// paginate splits lines into pages, leaving room for the page number on each page.
+func paginate(lines []string, perPage int) [][]string {
+	perPage--
+	pages := [][]string{}
+	for len(lines) > perPage {
+		pages = append(pages, lines[:perPage])
+		lines = lines[perPage:]
+	}
+	return append(pages, lines)
+}
+
+// pdfEscape escapes a string for use in a PDF literal string, replacing non-ASCII characters.
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 32 || r > 126:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
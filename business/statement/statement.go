@@ -0,0 +1,67 @@
+// Package statement builds account statements and renders them as CSV or PDF.
+package statement
+
+import (
+	"time"
+
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+)
+
+// Line is one account transaction on a statement with the balance after it.
+type Line struct {
+	ID             int64     `json:"id"`
+	Amount         int64     `json:"amount"`
+	RunningBalance int64     `json:"running_balance"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// Statement lists the account transactions of an account in [From, To).
+type Statement struct {
+	AccountID      int64     `json:"account_id"`
+	Owner          string    `json:"owner"`
+	Currency       string    `json:"currency"`
+	From           time.Time `json:"from"`
+	To             time.Time `json:"to"`
+	OpeningBalance int64     `json:"opening_balance"`
+	ClosingBalance int64     `json:"closing_balance"`
+	TotalCredits   int64     `json:"total_credits"`
+	TotalDebits    int64     `json:"total_debits"`
+	Lines          []Line    `json:"lines"`
+}
+
+// LastDay returns the last day the statement covers, as To is exclusive.
+func (s Statement) LastDay() time.Time {
+	return s.To.AddDate(0, 0, -1)
+}
+
+// New builds the statement of an account from its balance at from and its
+// account transactions in [from, to), which must be ordered by time.
+func New(account db.Account, from, to time.Time, openingBalance int64, entries []db.AccountTransaction) Statement {
+	s := Statement{
+		AccountID:      account.ID,
+		Owner:          account.Owner,
+		Currency:       account.Currency,
+		From:           from,
+		To:             to,
+		OpeningBalance: openingBalance,
+		Lines:          make([]Line, 0, len(entries)),
+	}
+
+	balance := openingBalance
+	for _, entry := range entries {
+		balance += entry.Amount
+		if entry.Amount >= 0 {
+			s.TotalCredits += entry.Amount
+		} else {
+			s.TotalDebits -= entry.Amount
+		}
+		s.Lines = append(s.Lines, Line{
+			ID:             entry.ID,
+			Amount:         entry.Amount,
+			RunningBalance: balance,
+			CreatedAt:      entry.CreatedAt,
+		})
+	}
+	s.ClosingBalance = balance
+	return s
+}
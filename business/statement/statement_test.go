@@ -0,0 +1,64 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func testStatement(entries int) Statement {
+	from := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	account := db.Account{ID: 7, Owner: "alice", Currency: "USD"}
+
+	transactions := make([]db.AccountTransaction, entries)
+	for i := range transactions {
+		amount := int64(1000)
+		if i%2 == 1 {
+			amount = -250
+		}
+		transactions[i] = db.AccountTransaction{ID: int64(i + 1), AccountID: 7, Amount: amount, CreatedAt: from.Add(time.Duration(i) * time.Hour)}
+	}
+	return New(account, from, from.AddDate(0, 1, 0), 5000, transactions)
+}
+
+func TestNew(t *testing.T) {
+	s := testStatement(3)
+
+	require.Len(t, s.Lines, 3)
+	require.Equal(t, int64(6000), s.Lines[0].RunningBalance)
+	require.Equal(t, int64(5750), s.Lines[1].RunningBalance)
+	require.Equal(t, int64(6750), s.Lines[2].RunningBalance)
+	require.Equal(t, int64(6750), s.ClosingBalance)
+	require.Equal(t, int64(2000), s.TotalCredits)
+	require.Equal(t, int64(250), s.TotalDebits)
+	require.Equal(t, s.OpeningBalance+s.TotalCredits-s.TotalDebits, s.ClosingBalance)
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, testStatement(2)))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 5)
+	require.Equal(t, []string{"2024-05-01T00:00:00Z", "", "Opening balance", "", "50.00", "USD"}, records[1])
+	require.Equal(t, []string{"2024-05-01T01:00:00Z", "2", "Debit", "-2.50", "57.50", "USD"}, records[3])
+	require.Equal(t, []string{"2024-05-31", "", "Closing balance", "", "57.50", "USD"}, records[4])
+}
+
+func TestWritePDF(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WritePDF(&buf, testStatement(150)))
+
+	pdf := buf.String()
+	require.True(t, strings.HasPrefix(pdf, "%PDF-1.4\n"))
+	require.True(t, strings.HasSuffix(pdf, "%%EOF\n"))
+	require.Contains(t, pdf, "/Count 3")
+	require.Contains(t, pdf, "Page 3 of 3")
+	require.Contains(t, pdf, "Period:   2024-05-01 - 2024-05-31")
+}
@@ -0,0 +1,266 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event emitters selectable with TASK_EMITTER.
+const (
+	EmitterSimple = "simple"
+	EmitterAsync  = "async"
+)
+
+// NewEventEmitter creates the event emitter for mode. The listener timeout of the async emitter matches
+// asynq's default task timeout, so the deadline of the task context is what ends a handler.
+func NewEventEmitter(mode string) (EventEmitter, error) {
+	switch mode {
+	case "", EmitterSimple:
+		return NewSimpleEventEmitter(), nil
+	case EmitterAsync:
+		return NewAsyncEventEmitter(ListenerTimeout(defaultTimeout)), nil
+	default:
+		return nil, fmt.Errorf("unknown event emitter %q", mode)
+	}
+}
+
+// ErrListenerTimeout is returned for a listener that did not finish within the listener timeout.
+var ErrListenerTimeout = errors.New("listener timed out")
+
+// ListenerError is the error of one listener of an emitted event.
+type ListenerError struct {
+	Pattern string
+	Err     error
+}
+
+func (e *ListenerError) Error() string {
+	return fmt.Sprintf("listener %s: %v", e.Pattern, e.Err)
+}
+
+func (e *ListenerError) Unwrap() error {
+	return e.Err
+}
+
+// AsyncEventEmitter is an EventEmitter that runs the listeners of an event concurrently.
+// Every listener runs in its own goroutine and sees events one at a time, in the order they were emitted,
+// so a slow or failing listener never blocks or aborts the others. Listeners are registered for an event
+// pattern whose segments are separated by dots: "*" matches one segment and a trailing "**" matches the rest,
+// e.g. "transfer.*" matches "transfer.completed". Listeners may call On, Subscribe and Off, but must not
+// wait for an event they listen to themselves.
+type AsyncEventEmitter struct {
+	timeout time.Duration
+
+	mu            sync.RWMutex
+	subscriptions []*Subscription
+}
+
+// AsyncEventEmitterOption configures an AsyncEventEmitter.
+type AsyncEventEmitterOption func(*AsyncEventEmitter)
+
+// ListenerTimeout cancels the context of a listener and reports ErrListenerTimeout when it runs longer than d.
+func ListenerTimeout(d time.Duration) AsyncEventEmitterOption {
+	return func(e *AsyncEventEmitter) { e.timeout = d }
+}
+
+// NewAsyncEventEmitter creates a new AsyncEventEmitter, with a listener timeout of 30 seconds unless configured otherwise.
+func NewAsyncEventEmitter(opts ...AsyncEventEmitterOption) *AsyncEventEmitter {
+	e := &AsyncEventEmitter{timeout: 30 * time.Second}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Subscription is a listener registered on an AsyncEventEmitter.
+type Subscription struct {
+	emitter  *AsyncEventEmitter
+	pattern  string
+	listener func(ctx context.Context, payload []byte) error
+
+	mu     sync.Mutex
+	closed bool
+	queue  []delivery
+	ready  chan struct{}
+}
+
+type delivery struct {
+	ctx     context.Context
+	payload []byte
+	result  chan error
+}
+
+// Off unsubscribes the listener. Events emitted before are still delivered to it.
+func (s *Subscription) Off() {
+	s.emitter.Off(s)
+}
+
+// On registers a listener for an event pattern.
+func (e *AsyncEventEmitter) On(pattern string, listener func(ctx context.Context, payload []byte) error) {
+	e.Subscribe(pattern, listener)
+}
+
+// Subscribe registers a listener for an event pattern and returns the subscription to unsubscribe it.
+func (e *AsyncEventEmitter) Subscribe(pattern string, listener func(ctx context.Context, payload []byte) error) *Subscription {
+	s := &Subscription{
+		emitter:  e,
+		pattern:  pattern,
+		listener: listener,
+		ready:    make(chan struct{}, 1),
+	}
+	go s.run()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.subscriptions = append(e.subscriptions, s)
+	return s
+}
+
+// Off unsubscribes a listener.
+func (e *AsyncEventEmitter) Off(s *Subscription) {
+	e.mu.Lock()
+	for i, sub := range e.subscriptions {
+		if sub == s {
+			e.subscriptions = append(e.subscriptions[:i:i], e.subscriptions[i+1:]...)
+			break
+		}
+	}
+	e.mu.Unlock()
+
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.wake()
+}
+
+// Emit delivers an event to every matching listener and waits for them to finish.
+// It returns the errors of all failed listeners joined together.
+func (e *AsyncEventEmitter) Emit(event string, ctx context.Context, payload []byte) error {
+	return <-e.EmitAsync(event, ctx, payload)
+}
+
+// EmitAsync delivers an event to every matching listener without waiting for them.
+// The returned channel receives the joined errors of the failed listeners once all of them finished.
+func (e *AsyncEventEmitter) EmitAsync(event string, ctx context.Context, payload []byte) <-chan error {
+	e.mu.RLock()
+	var matching []*Subscription
+	for _, s := range e.subscriptions {
+		if MatchEvent(s.pattern, event) {
+			matching = append(matching, s)
+		}
+	}
+	e.mu.RUnlock()
+
+	// Queue the event on every listener before waiting, so each listener sees events in emit order
+	results := make([]chan error, 0, len(matching))
+	for _, s := range matching {
+		if result, ok := s.deliver(ctx, payload); ok {
+			results = append(results, result)
+		}
+	}
+
+	// All listeners share one deadline, so the event is settled at most one listener timeout after it was emitted
+	deadline, cancel := context.WithTimeout(context.Background(), e.timeout)
+	done := make(chan error, 1)
+	go func() {
+		defer cancel()
+		var errs []error
+		for i, result := range results {
+			if err := wait(result, deadline.Done()); err != nil {
+				errs = append(errs, &ListenerError{Pattern: matching[i].pattern, Err: err})
+			}
+		}
+		done <- errors.Join(errs...)
+	}()
+	return done
+}
+
+// wait waits for the result of a delivery until deadline is closed.
+// A result that is already there wins over an expired deadline.
+func wait(result chan error, deadline <-chan struct{}) error {
+	select {
+	case err := <-result:
+		return err
+	default:
+	}
+	select {
+	case err := <-result:
+		return err
+	case <-deadline:
+		return ErrListenerTimeout
+	}
+}
+
+// deliver queues an event for the listener unless it was unsubscribed.
+func (s *Subscription) deliver(ctx context.Context, payload []byte) (chan error, bool) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil, false
+	}
+	result := make(chan error, 1)
+	s.queue = append(s.queue, delivery{ctx: ctx, payload: payload, result: result})
+	s.mu.Unlock()
+	s.wake()
+	return result, true
+}
+
+// wake signals run that the queue changed.
+func (s *Subscription) wake() {
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+}
+
+// run calls the listener for every queued event until the subscription is closed and its queue is empty.
+func (s *Subscription) run() {
+	for {
+		s.mu.Lock()
+		if len(s.queue) == 0 {
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return
+			}
+			<-s.ready
+			continue
+		}
+		d := s.queue[0]
+		s.queue = s.queue[1:]
+		s.mu.Unlock()
+
+		d.result <- s.call(d)
+	}
+}
+
+// call calls the listener with the listener timeout, turning a panic into an error.
+func (s *Subscription) call(d delivery) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	ctx, cancel := context.WithTimeout(d.ctx, s.emitter.timeout)
+	defer cancel()
+	return s.listener(ctx, d.payload)
+}
+
+// MatchEvent reports whether an event matches a pattern: "*" matches one dot-separated segment
+// and a trailing "**" matches all remaining segments.
+func MatchEvent(pattern, event string) bool {
+	patternSegments := strings.Split(pattern, ".")
+	eventSegments := strings.Split(event, ".")
+	for i, p := range patternSegments {
+		if p == "**" && i == len(patternSegments)-1 {
+			return len(eventSegments) > i
+		}
+		if i >= len(eventSegments) || (p != "*" && p != eventSegments[i]) {
+			return false
+		}
+	}
+	return len(patternSegments) == len(eventSegments)
+}
@@ -0,0 +1,183 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchEvent(t *testing.T) {
+	tests := []struct {
+		pattern string
+		event   string
+		want    bool
+	}{
+		{"transfer.completed", "transfer.completed", true},
+		{"transfer.completed", "transfer.failed", false},
+		{"transfer.*", "transfer.completed", true},
+		{"transfer.*", "transfer", false},
+		{"transfer.*", "transfer.batch.completed", false},
+		{"*.completed", "transfer.completed", true},
+		{"transfer.**", "transfer.batch.completed", true},
+		{"transfer.**", "transfer", false},
+		{"**", "user.created", true},
+		{"user", "user.created", false},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, MatchEvent(tt.pattern, tt.event), "%s ~ %s", tt.pattern, tt.event)
+	}
+}
+
+func TestAsyncEventEmitterRunsListenersConcurrently(t *testing.T) {
+	e := NewAsyncEventEmitter()
+	// Each listener only returns once the other one started, which deadlocks if they run one after another
+	a, b := make(chan struct{}), make(chan struct{})
+	e.On("transfer.completed", func(ctx context.Context, payload []byte) error {
+		close(a)
+		<-b
+		return nil
+	})
+	e.On("transfer.*", func(ctx context.Context, payload []byte) error {
+		close(b)
+		<-a
+		return nil
+	})
+	require.NoError(t, e.Emit("transfer.completed", context.Background(), nil))
+}
+
+func TestAsyncEventEmitterAggregatesErrors(t *testing.T) {
+	e := NewAsyncEventEmitter(ListenerTimeout(50 * time.Millisecond))
+	errFailed := errors.New("failed")
+	var called atomic.Int32
+	e.On("user.created", func(ctx context.Context, payload []byte) error {
+		called.Add(1)
+		return errFailed
+	})
+	e.On("user.created", func(ctx context.Context, payload []byte) error {
+		called.Add(1)
+		panic("boom")
+	})
+	e.On("user.*", func(ctx context.Context, payload []byte) error {
+		called.Add(1)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	e.On("user.created", func(ctx context.Context, payload []byte) error {
+		called.Add(1)
+		return nil
+	})
+
+	err := e.Emit("user.created", context.Background(), nil)
+	require.ErrorIs(t, err, errFailed)
+	require.ErrorContains(t, err, "panic: boom")
+	require.True(t, errors.Is(err, ErrListenerTimeout) || errors.Is(err, context.DeadlineExceeded))
+	var listenerErr *ListenerError
+	require.ErrorAs(t, err, &listenerErr)
+	require.Equal(t, int32(4), called.Load())
+}
+
+func TestAsyncEventEmitterSharesDeadline(t *testing.T) {
+	e := NewAsyncEventEmitter(ListenerTimeout(50 * time.Millisecond))
+	release := make(chan struct{})
+	defer close(release)
+	// The listeners ignore their context, so only the deadline of Emit ends the wait
+	for range 5 {
+		e.On("user.created", func(ctx context.Context, payload []byte) error {
+			<-release
+			return nil
+		})
+	}
+
+	start := time.Now()
+	err := e.Emit("user.created", context.Background(), nil)
+	require.ErrorIs(t, err, ErrListenerTimeout)
+	require.Len(t, err.(interface{ Unwrap() []error }).Unwrap(), 5)
+	// A timer per listener would wait 5 timeouts
+	require.Less(t, time.Since(start), 200*time.Millisecond)
+}
+
+func TestAsyncEventEmitterOff(t *testing.T) {
+	e := NewAsyncEventEmitter()
+	var calls atomic.Int32
+	sub := e.Subscribe("transfer.*", func(ctx context.Context, payload []byte) error {
+		calls.Add(1)
+		return nil
+	})
+	require.NoError(t, e.Emit("transfer.completed", context.Background(), nil))
+	sub.Off()
+	sub.Off()
+	require.NoError(t, e.Emit("transfer.completed", context.Background(), nil))
+	require.Equal(t, int32(1), calls.Load())
+}
+
+func TestAsyncEventEmitterListenerMaySubscribe(t *testing.T) {
+	e := NewAsyncEventEmitter()
+	var sub *Subscription
+	sub = e.Subscribe("user.created", func(ctx context.Context, payload []byte) error {
+		e.On("user.deleted", func(ctx context.Context, payload []byte) error { return nil })
+		sub.Off()
+		return nil
+	})
+	require.NoError(t, e.Emit("user.created", context.Background(), nil))
+	require.NoError(t, e.Emit("user.deleted", context.Background(), nil))
+}
+
+func TestAsyncEventEmitterOrdersEventsPerListener(t *testing.T) {
+	e := NewAsyncEventEmitter()
+	var mu sync.Mutex
+	var seen []byte
+	var running atomic.Int32
+	e.On("account.*", func(ctx context.Context, payload []byte) error {
+		if running.Add(1) != 1 {
+			t.Error("listener runs concurrently with itself")
+		}
+		defer running.Add(-1)
+		mu.Lock()
+		seen = append(seen, payload[0])
+		mu.Unlock()
+		return nil
+	})
+	// A second, slow listener must not hold back the first one
+	e.On("account.updated", func(ctx context.Context, payload []byte) error {
+		time.Sleep(time.Millisecond)
+		return nil
+	})
+
+	var results []<-chan error
+	for i := 0; i < 100; i++ {
+		results = append(results, e.EmitAsync("account.updated", context.Background(), []byte{byte(i)}))
+	}
+	for _, result := range results {
+		require.NoError(t, <-result)
+	}
+
+	require.Len(t, seen, 100)
+	for i, b := range seen {
+		require.Equal(t, byte(i), b)
+	}
+
+	// Concurrent emitters are safe as well
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, e.Emit("account.updated", context.Background(), []byte{0}))
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSimpleEventEmitterListenerMayRegister(t *testing.T) {
+	e := NewSimpleEventEmitter()
+	e.On("user.created", func(ctx context.Context, payload []byte) error {
+		e.On("user.created", func(ctx context.Context, payload []byte) error { return nil })
+		return nil
+	})
+	require.NoError(t, e.Emit("user.created", context.Background(), nil))
+}
@@ -0,0 +1,168 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+// Errors returned by an Inspector.
+var (
+	ErrQueueNotFound = errors.New("queue not found")
+	ErrTaskNotFound  = errors.New("task not found")
+)
+
+// QueueInfo holds the number of tasks in each state of a queue.
+type QueueInfo struct {
+	Queue     string `json:"queue"`
+	Size      int    `json:"size"`
+	Pending   int    `json:"pending"`
+	Active    int    `json:"active"`
+	Scheduled int    `json:"scheduled"`
+	Retry     int    `json:"retry"`
+	Archived  int    `json:"archived"`
+	Paused    bool   `json:"paused"`
+}
+
+// TaskInfo describes a task of a queue.
+type TaskInfo struct {
+	ID           string    `json:"id"`
+	Queue        string    `json:"queue"`
+	Type         string    `json:"type"`
+	Payload      []byte    `json:"payload"`
+	State        string    `json:"state"`
+	MaxRetry     int       `json:"max_retry"`
+	Retried      int       `json:"retried"`
+	LastErr      string    `json:"last_error"`
+	LastFailedAt time.Time `json:"last_failed_at"`
+}
+
+// Inspector inspects and manages the queues of a TaskManager.
+// Archived tasks failed for good; they form the dead-letter queue.
+type Inspector interface {
+	Queues() ([]QueueInfo, error)
+	ListArchivedTasks(queue string, page, pageSize int) ([]TaskInfo, error)
+	GetTaskInfo(queue, id string) (TaskInfo, error)
+	RetryArchivedTask(queue, id string) error
+	DeleteArchivedTask(queue, id string) error
+	PauseQueue(queue string) error
+	ResumeQueue(queue string) error
+}
+
+var _ Inspector = (*taskManager)(nil)
+
+// Queues returns the state of every queue known to Redis.
+func (tm *taskManager) Queues() ([]QueueInfo, error) {
+	queues, err := tm.inspector.Queues()
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]QueueInfo, 0, len(queues))
+	for _, queue := range queues {
+		info, err := tm.inspector.GetQueueInfo(queue)
+		if err != nil {
+			return nil, inspectorError(err)
+		}
+		infos = append(infos, QueueInfo{
+			Queue:     info.Queue,
+			Size:      info.Size,
+			Pending:   info.Pending,
+			Active:    info.Active,
+			Scheduled: info.Scheduled,
+			Retry:     info.Retry,
+			Archived:  info.Archived,
+			Paused:    info.Paused,
+		})
+	}
+	return infos, nil
+}
+
+// ListArchivedTasks returns a page of the archived tasks of a queue, starting at page 1.
+func (tm *taskManager) ListArchivedTasks(queue string, page, pageSize int) ([]TaskInfo, error) {
+	tasks, err := tm.inspector.ListArchivedTasks(queue, asynq.Page(page), asynq.PageSize(pageSize))
+	if err != nil {
+		return nil, inspectorError(err)
+	}
+	infos := make([]TaskInfo, 0, len(tasks))
+	for _, task := range tasks {
+		infos = append(infos, asynqTaskInfo(task))
+	}
+	return infos, nil
+}
+
+// GetTaskInfo returns a task of a queue.
+func (tm *taskManager) GetTaskInfo(queue, id string) (TaskInfo, error) {
+	task, err := tm.inspector.GetTaskInfo(queue, id)
+	if err != nil {
+		return TaskInfo{}, inspectorError(err)
+	}
+	return asynqTaskInfo(task), nil
+}
+
+// RetryArchivedTask moves an archived task back to the queue to be processed again.
+func (tm *taskManager) RetryArchivedTask(queue, id string) error {
+	if err := tm.archivedTask(queue, id); err != nil {
+		return err
+	}
+	return inspectorError(tm.inspector.RunTask(queue, id))
+}
+
+// DeleteArchivedTask deletes an archived task.
+func (tm *taskManager) DeleteArchivedTask(queue, id string) error {
+	if err := tm.archivedTask(queue, id); err != nil {
+		return err
+	}
+	return inspectorError(tm.inspector.DeleteTask(queue, id))
+}
+
+// PauseQueue stops processing the tasks of a queue; they can still be enqueued.
+func (tm *taskManager) PauseQueue(queue string) error {
+	return inspectorError(tm.inspector.PauseQueue(queue))
+}
+
+// ResumeQueue resumes processing the tasks of a paused queue.
+func (tm *taskManager) ResumeQueue(queue string) error {
+	return inspectorError(tm.inspector.UnpauseQueue(queue))
+}
+
+// archivedTask checks that a task exists and is archived.
+func (tm *taskManager) archivedTask(queue, id string) error {
+	task, err := tm.inspector.GetTaskInfo(queue, id)
+	if err != nil {
+		return inspectorError(err)
+	}
+	if task.State != asynq.TaskStateArchived {
+		return fmt.Errorf("%w: task %s is %s, not archived", ErrTaskNotFound, id, task.State)
+	}
+	return nil
+}
+
+func asynqTaskInfo(task *asynq.TaskInfo) TaskInfo {
+	return TaskInfo{
+		ID:           task.ID,
+		Queue:        task.Queue,
+		Type:         task.Type,
+		Payload:      task.Payload,
+		State:        task.State.String(),
+		MaxRetry:     task.MaxRetry,
+		Retried:      task.Retried,
+		LastErr:      task.LastErr,
+		LastFailedAt: task.LastFailedAt,
+	}
+}
+
+// inspectorError maps the errors of the asynq inspector to ErrQueueNotFound and ErrTaskNotFound.
+func inspectorError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, asynq.ErrQueueNotFound):
+		return fmt.Errorf("%w: %v", ErrQueueNotFound, err)
+	case errors.Is(err, asynq.ErrTaskNotFound):
+		return fmt.Errorf("%w: %v", ErrTaskNotFound, err)
+	default:
+		return err
+	}
+}
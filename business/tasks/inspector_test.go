@@ -0,0 +1,100 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+)
+
+func queueInfo(t *testing.T, i Inspector, queue string) QueueInfo {
+	queues, err := i.Queues()
+	require.NoError(t, err)
+	for _, info := range queues {
+		if info.Queue == queue {
+			return info
+		}
+	}
+	t.Fatalf("queue %s not found", queue)
+	return QueueInfo{}
+}
+
+func TestMemoryInspectorArchivedTasks(t *testing.T) {
+	tm := NewMemoryTaskManager(1)
+	fail := true
+	tm.On("test:dead", func(ctx context.Context, payload []byte) error {
+		if fail {
+			return asynq.SkipRetry
+		}
+		return nil
+	})
+
+	require.NoError(t, tm.Enqueue("test:dead", []byte("a"), Queue(QueueLow), UniqueKey("a")))
+	require.NoError(t, tm.Enqueue("test:dead", []byte("b"), Queue(QueueLow), UniqueKey("b")))
+	require.Error(t, tm.Drain(context.Background()))
+	require.Equal(t, 2, queueInfo(t, tm, QueueLow).Archived)
+
+	archived, err := tm.ListArchivedTasks(QueueLow, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, archived, 2)
+	require.Equal(t, "test:dead", archived[0].Type)
+	require.Equal(t, "archived", archived[0].State)
+	require.NotEmpty(t, archived[0].LastErr)
+
+	page, err := tm.ListArchivedTasks(QueueLow, 2, 1)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, archived[1].ID, page[0].ID)
+
+	info, err := tm.GetTaskInfo(QueueLow, archived[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, []byte("a"), info.Payload)
+	_, err = tm.GetTaskInfo(QueueDefault, archived[0].ID)
+	require.ErrorIs(t, err, ErrTaskNotFound)
+
+	// Deleting releases the unique key, retrying processes the task again
+	require.NoError(t, tm.DeleteArchivedTask(QueueLow, archived[0].ID))
+	require.ErrorIs(t, tm.DeleteArchivedTask(QueueLow, archived[0].ID), ErrTaskNotFound)
+	require.NoError(t, tm.Enqueue("test:dead", nil, UniqueKey("a"), Queue(QueueLow)))
+
+	fail = false
+	require.NoError(t, tm.RetryArchivedTask(QueueLow, archived[1].ID))
+	require.NoError(t, tm.Drain(context.Background()))
+	require.Zero(t, queueInfo(t, tm, QueueLow).Archived)
+	require.Empty(t, tm.Archived())
+}
+
+func TestMemoryInspectorPauseQueue(t *testing.T) {
+	tm := NewMemoryTaskManager(1)
+	var calls int
+	tm.On("test:pause", func(ctx context.Context, payload []byte) error {
+		calls++
+		return nil
+	})
+
+	require.NoError(t, tm.PauseQueue(QueueDefault))
+	require.NoError(t, tm.Enqueue("test:pause", nil))
+	require.NoError(t, tm.Enqueue("test:pause", nil, Queue(QueueCritical)))
+	require.NoError(t, tm.Drain(context.Background()))
+	require.Equal(t, 1, calls)
+
+	info := queueInfo(t, tm, QueueDefault)
+	require.True(t, info.Paused)
+	require.Equal(t, 1, info.Pending)
+	require.Equal(t, 1, info.Size)
+
+	require.NoError(t, tm.ResumeQueue(QueueDefault))
+	require.NoError(t, tm.Drain(context.Background()))
+	require.Equal(t, 2, calls)
+	require.False(t, queueInfo(t, tm, QueueDefault).Paused)
+}
+
+func TestInspectorError(t *testing.T) {
+	require.ErrorIs(t, inspectorError(asynq.ErrQueueNotFound), ErrQueueNotFound)
+	require.ErrorIs(t, inspectorError(asynq.ErrTaskNotFound), ErrTaskNotFound)
+	require.NoError(t, inspectorError(nil))
+	other := errors.New("other")
+	require.Equal(t, other, inspectorError(other))
+}
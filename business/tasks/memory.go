@@ -0,0 +1,524 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/robfig/cron/v3"
+)
+
+// Task backends selectable with TASK_BACKEND.
+const (
+	BackendRedis  = "redis"
+	BackendMemory = "memory"
+)
+
+// Defaults applied by asynq to tasks enqueued without MaxRetry or Timeout.
+const (
+	defaultMaxRetry = 25
+	defaultTimeout  = 30 * time.Minute
+)
+
+// Task is a task enqueued on a MemoryTaskManager.
+type Task struct {
+	ID        int64
+	Type      string
+	Payload   []byte
+	Options   EnqueueOptions
+	ProcessAt time.Time
+	Retried   int
+	LastErr   error
+}
+
+// MemoryTaskManager is a TaskManager that processes tasks in-process instead of through Redis.
+// Tasks are retried with asynq's backoff, SkipRetry archives a task right away and up to
+// concurrency tasks are processed at the same time. It is meant for tests and local development:
+// enqueued tasks are lost when the process exits.
+type MemoryTaskManager struct {
+	enqueuer
+	concurrency  int
+	eventEmitter EventEmitter
+
+	mu            sync.Mutex
+	nextID        int64
+	handlerTypes  []string
+	pending       []*Task
+	enqueued      []Task
+	archived      []Task
+	uniqueKeys    map[string]bool
+	active        map[string]int
+	paused        map[string]bool
+	periodicTasks []memoryPeriodicTask
+	wake          chan struct{}
+	done          chan struct{}
+	closeOnce     sync.Once
+}
+
+var (
+	_ TaskManager = (*MemoryTaskManager)(nil)
+	_ Inspector   = (*MemoryTaskManager)(nil)
+)
+
+type memoryPeriodicTask struct {
+	cronspec string
+	taskType string
+}
+
+// NewMemoryTaskManager creates a MemoryTaskManager that processes up to concurrency tasks at a time.
+func NewMemoryTaskManager(concurrency int, opts ...ManagerOption) *MemoryTaskManager {
+	o := NewManagerOptions(opts...)
+	if concurrency <= 0 {
+		concurrency = 10
+	}
+	tm := &MemoryTaskManager{
+		concurrency:  concurrency,
+		eventEmitter: o.EventEmitter,
+		uniqueKeys:   make(map[string]bool),
+		active:       make(map[string]int),
+		paused:       make(map[string]bool),
+		wake:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	tm.enqueuer = enqueuer{tm}
+	return tm
+}
+
+// Enqueue enqueues a task of the given type with a raw payload.
+func (tm *MemoryTaskManager) Enqueue(taskType string, payload []byte, opts ...EnqueueOption) error {
+	o := NewEnqueueOptions(opts...)
+	processAt := time.Now()
+	if !o.ProcessAt.IsZero() {
+		processAt = o.ProcessAt
+	}
+	if o.Delay > 0 {
+		processAt = time.Now().Add(o.Delay)
+	}
+
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if o.UniqueKey != "" {
+		if tm.uniqueKeys[o.UniqueKey] {
+			return fmt.Errorf("%w: %s", ErrDuplicateTask, o.UniqueKey)
+		}
+		tm.uniqueKeys[o.UniqueKey] = true
+	}
+
+	tm.nextID++
+	task := &Task{
+		ID:        tm.nextID,
+		Type:      taskType,
+		Payload:   slices.Clone(payload),
+		Options:   o,
+		ProcessAt: processAt,
+	}
+	tm.pending = append(tm.pending, task)
+	tm.enqueued = append(tm.enqueued, *task)
+	tm.signal()
+	return nil
+}
+
+// On registers a handler that is called for every processed task of the given type.
+func (tm *MemoryTaskManager) On(taskType string, handler func(ctx context.Context, payload []byte) error) {
+	tm.mu.Lock()
+	if !slices.Contains(tm.handlerTypes, taskType) {
+		tm.handlerTypes = append(tm.handlerTypes, taskType)
+	}
+	tm.mu.Unlock()
+	tm.eventEmitter.On(taskType, handler)
+}
+
+// Run processes tasks as they become due until Shutdown is called.
+func (tm *MemoryTaskManager) Run() error {
+	var wg sync.WaitGroup
+	for i := 0; i < tm.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tm.work()
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// work processes due tasks until the manager is shut down.
+func (tm *MemoryTaskManager) work() {
+	for {
+		select {
+		case <-tm.done:
+			return
+		default:
+		}
+
+		task, wait, wake := tm.next(time.Now(), false)
+		if task != nil {
+			tm.process(context.Background(), task, false)
+			continue
+		}
+
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if wait >= 0 {
+			timer = time.NewTimer(wait)
+			timeout = timer.C
+		}
+		select {
+		case <-tm.done:
+		case <-wake:
+		case <-timeout:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// Drain synchronously processes every pending task, including delayed tasks and retries,
+// without waiting for them to become due. It returns the errors of the tasks that were archived.
+// Drain must not be used while Run is processing tasks.
+func (tm *MemoryTaskManager) Drain(ctx context.Context) error {
+	var errs []error
+	for {
+		task, _, _ := tm.next(time.Now(), true)
+		if task == nil {
+			return errors.Join(errs...)
+		}
+		if err := tm.process(ctx, task, true); err != nil {
+			errs = append(errs, fmt.Errorf("task %d (%s): %w", task.ID, task.Type, err))
+		}
+	}
+}
+
+// next removes and returns the next due task, preferring higher priority queues. If no task is due,
+// it returns how long until the next one is, or -1 if there is none, and a channel that is closed
+// when a task is enqueued. Tasks of paused queues are skipped. With ignoreSchedule every pending task is due.
+func (tm *MemoryTaskManager) next(now time.Time, ignoreSchedule bool) (*Task, time.Duration, <-chan struct{}) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	best := -1
+	wait := time.Duration(-1)
+	for i, task := range tm.pending {
+		if tm.paused[task.Options.Queue] {
+			continue
+		}
+		if !ignoreSchedule && task.ProcessAt.After(now) {
+			if d := task.ProcessAt.Sub(now); wait < 0 || d < wait {
+				wait = d
+			}
+			continue
+		}
+		if best < 0 || queuePriorities[task.Options.Queue] > queuePriorities[tm.pending[best].Options.Queue] ||
+			(queuePriorities[task.Options.Queue] == queuePriorities[tm.pending[best].Options.Queue] && task.ProcessAt.Before(tm.pending[best].ProcessAt)) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return nil, wait, tm.wake
+	}
+
+	task := tm.pending[best]
+	tm.pending = slices.Delete(tm.pending, best, best+1)
+	tm.active[task.Options.Queue]++
+	return task, 0, nil
+}
+
+// process runs a task and reschedules or archives it when it fails.
+// It returns the error of the task if it was archived.
+func (tm *MemoryTaskManager) process(ctx context.Context, task *Task, retryNow bool) error {
+	timeout := task.Options.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	start := time.Now()
+	err := tm.execute(ctx, task)
+	cancel()
+	observeTask(task.Type, start, err)
+
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.active[task.Options.Queue]--
+	if err == nil {
+		delete(tm.uniqueKeys, task.Options.UniqueKey)
+		return nil
+	}
+
+	task.LastErr = err
+	maxRetry := defaultMaxRetry
+	if task.Options.MaxRetry != nil {
+		maxRetry = *task.Options.MaxRetry
+	}
+	if errors.Is(err, asynq.SkipRetry) || task.Retried >= maxRetry {
+		// Like asynq, the unique key stays taken by the archived task
+		log.Printf("Archiving task %d (%s) after %d retries: %v", task.ID, task.Type, task.Retried, err)
+		tm.archived = append(tm.archived, *task)
+		return err
+	}
+
+	task.Retried++
+	task.ProcessAt = time.Now()
+	if !retryNow {
+		task.ProcessAt = task.ProcessAt.Add(asynq.DefaultRetryDelayFunc(task.Retried, err, asynq.NewTask(task.Type, task.Payload)))
+	}
+	tm.pending = append(tm.pending, task)
+	tm.signal()
+	return nil
+}
+
+// execute calls the handlers of a task, turning a panic into an error as asynq does.
+func (tm *MemoryTaskManager) execute(ctx context.Context, task *Task) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	tm.mu.Lock()
+	registered := slices.Contains(tm.handlerTypes, task.Type)
+	tm.mu.Unlock()
+	if !registered {
+		return fmt.Errorf("handler not found for task %q", task.Type)
+	}
+	return tm.eventEmitter.Emit(task.Type, ctx, task.Payload)
+}
+
+// signal wakes up idle workers. It must be called with mu held.
+func (tm *MemoryTaskManager) signal() {
+	close(tm.wake)
+	tm.wake = make(chan struct{})
+}
+
+// RegisterPeriodicTask enqueues a task of the given type on every tick of cronspec once RunPeriodic is running.
+func (tm *MemoryTaskManager) RegisterPeriodicTask(cronspec string, taskType string) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.periodicTasks = append(tm.periodicTasks, memoryPeriodicTask{cronspec: cronspec, taskType: taskType})
+}
+
+// RunPeriodic enqueues the registered periodic tasks until Shutdown is called.
+func (tm *MemoryTaskManager) RunPeriodic() error {
+	tm.mu.Lock()
+	periodicTasks := slices.Clone(tm.periodicTasks)
+	tm.mu.Unlock()
+
+	c := cron.New()
+	for _, pt := range periodicTasks {
+		key := fmt.Sprintf("periodic:%s:%s", pt.taskType, pt.cronspec)
+		if _, err := c.AddFunc(pt.cronspec, func() {
+			if err := tm.Enqueue(pt.taskType, nil, UniqueKey(key)); err != nil && !errors.Is(err, ErrDuplicateTask) {
+				log.Printf("Failed to enqueue periodic task %s: %v", pt.taskType, err)
+			}
+		}); err != nil {
+			return fmt.Errorf("invalid cronspec %q for %s: %v", pt.cronspec, pt.taskType, err)
+		}
+	}
+
+	c.Start()
+	<-tm.done
+	<-c.Stop().Done()
+	return nil
+}
+
+// Shutdown stops Run and RunPeriodic once the tasks being processed have finished.
+func (tm *MemoryTaskManager) Shutdown() {
+	tm.closeOnce.Do(func() {
+		close(tm.done)
+	})
+}
+
+// Close does nothing, a MemoryTaskManager holds no connections.
+func (tm *MemoryTaskManager) Close() error {
+	return nil
+}
+
+// Enqueued returns every task enqueued so far, in order, optionally only those of the given types.
+func (tm *MemoryTaskManager) Enqueued(taskTypes ...string) []Task {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	return filterTasks(tm.enqueued, taskTypes)
+}
+
+// Archived returns the tasks that failed for good, optionally only those of the given types.
+func (tm *MemoryTaskManager) Archived(taskTypes ...string) []Task {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	return filterTasks(tm.archived, taskTypes)
+}
+
+// Pending returns the number of tasks waiting to be processed.
+func (tm *MemoryTaskManager) Pending() int {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	return len(tm.pending)
+}
+
+func filterTasks(tasks []Task, taskTypes []string) []Task {
+	result := []Task{}
+	for _, task := range tasks {
+		if len(taskTypes) == 0 || slices.Contains(taskTypes, task.Type) {
+			result = append(result, task)
+		}
+	}
+	return result
+}
+
+// Queues returns the state of the task server queues and of every other queue a task was enqueued on.
+func (tm *MemoryTaskManager) Queues() ([]QueueInfo, error) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	infos := make(map[string]*QueueInfo)
+	info := func(queue string) *QueueInfo {
+		if infos[queue] == nil {
+			infos[queue] = &QueueInfo{Queue: queue, Paused: tm.paused[queue]}
+		}
+		return infos[queue]
+	}
+	for queue := range queuePriorities {
+		info(queue)
+	}
+	now := time.Now()
+	for _, task := range tm.pending {
+		qi := info(task.Options.Queue)
+		switch {
+		case task.ProcessAt.After(now) && task.Retried > 0:
+			qi.Retry++
+		case task.ProcessAt.After(now):
+			qi.Scheduled++
+		default:
+			qi.Pending++
+		}
+	}
+	for queue, n := range tm.active {
+		info(queue).Active += n
+	}
+	for _, task := range tm.archived {
+		info(task.Options.Queue).Archived++
+	}
+
+	result := make([]QueueInfo, 0, len(infos))
+	for _, qi := range infos {
+		qi.Size = qi.Pending + qi.Active + qi.Scheduled + qi.Retry + qi.Archived
+		result = append(result, *qi)
+	}
+	slices.SortFunc(result, func(a, b QueueInfo) int { return strings.Compare(a.Queue, b.Queue) })
+	return result, nil
+}
+
+// ListArchivedTasks returns a page of the archived tasks of a queue, starting at page 1.
+func (tm *MemoryTaskManager) ListArchivedTasks(queue string, page, pageSize int) ([]TaskInfo, error) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	infos := []TaskInfo{}
+	for _, task := range tm.archived {
+		if task.Options.Queue == queue {
+			infos = append(infos, memoryTaskInfo(task, "archived"))
+		}
+	}
+	if page < 1 {
+		page = 1
+	}
+	start := min((page-1)*pageSize, len(infos))
+	end := min(start+pageSize, len(infos))
+	return infos[start:end], nil
+}
+
+// GetTaskInfo returns a pending or archived task of a queue.
+func (tm *MemoryTaskManager) GetTaskInfo(queue, id string) (TaskInfo, error) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	for _, task := range tm.pending {
+		if task.Options.Queue == queue && strconv.FormatInt(task.ID, 10) == id {
+			return memoryTaskInfo(*task, "pending"), nil
+		}
+	}
+	if i := tm.archivedIndex(queue, id); i >= 0 {
+		return memoryTaskInfo(tm.archived[i], "archived"), nil
+	}
+	return TaskInfo{}, fmt.Errorf("%w: %s", ErrTaskNotFound, id)
+}
+
+// RetryArchivedTask moves an archived task back to the queue to be processed again.
+func (tm *MemoryTaskManager) RetryArchivedTask(queue, id string) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	i := tm.archivedIndex(queue, id)
+	if i < 0 {
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, id)
+	}
+	task := tm.archived[i]
+	tm.archived = slices.Delete(tm.archived, i, i+1)
+	task.ProcessAt = time.Now()
+	tm.pending = append(tm.pending, &task)
+	tm.signal()
+	return nil
+}
+
+// DeleteArchivedTask deletes an archived task and releases its unique key.
+func (tm *MemoryTaskManager) DeleteArchivedTask(queue, id string) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	i := tm.archivedIndex(queue, id)
+	if i < 0 {
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, id)
+	}
+	delete(tm.uniqueKeys, tm.archived[i].Options.UniqueKey)
+	tm.archived = slices.Delete(tm.archived, i, i+1)
+	return nil
+}
+
+// PauseQueue stops processing the tasks of a queue; they can still be enqueued.
+func (tm *MemoryTaskManager) PauseQueue(queue string) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.paused[queue] = true
+	return nil
+}
+
+// ResumeQueue resumes processing the tasks of a paused queue.
+func (tm *MemoryTaskManager) ResumeQueue(queue string) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	delete(tm.paused, queue)
+	tm.signal()
+	return nil
+}
+
+// archivedIndex returns the index of an archived task, or -1. It must be called with mu held.
+func (tm *MemoryTaskManager) archivedIndex(queue, id string) int {
+	return slices.IndexFunc(tm.archived, func(task Task) bool {
+		return task.Options.Queue == queue && strconv.FormatInt(task.ID, 10) == id
+	})
+}
+
+func memoryTaskInfo(task Task, state string) TaskInfo {
+	info := TaskInfo{
+		ID:       strconv.FormatInt(task.ID, 10),
+		Queue:    task.Options.Queue,
+		Type:     task.Type,
+		Payload:  task.Payload,
+		State:    state,
+		MaxRetry: defaultMaxRetry,
+		Retried:  task.Retried,
+	}
+	if task.Options.MaxRetry != nil {
+		info.MaxRetry = *task.Options.MaxRetry
+	}
+	if task.LastErr != nil {
+		info.LastErr = task.LastErr.Error()
+	}
+	return info
+}
@@ -0,0 +1,139 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+)
+
+type testPayload struct {
+	N int
+}
+
+func TestMemoryTaskManagerDrainRetries(t *testing.T) {
+	tm := NewMemoryTaskManager(1)
+	var calls int
+	Register(tm, "test:retry", func(ctx context.Context, p testPayload) error {
+		calls++
+		if calls < 3 {
+			return errors.New("temporary failure")
+		}
+		require.Equal(t, 7, p.N)
+		return nil
+	})
+
+	require.NoError(t, Enqueue(tm, "test:retry", testPayload{N: 7}, Delay(time.Hour)))
+	require.NoError(t, tm.Drain(context.Background()))
+	require.Equal(t, 3, calls)
+	require.Empty(t, tm.Archived())
+	require.Zero(t, tm.Pending())
+}
+
+func TestMemoryTaskManagerArchives(t *testing.T) {
+	tm := NewMemoryTaskManager(1)
+	var calls int
+	tm.On("test:skip", func(ctx context.Context, payload []byte) error {
+		calls++
+		return asynq.SkipRetry
+	})
+	tm.On("test:fail", func(ctx context.Context, payload []byte) error {
+		calls++
+		return errors.New("permanent failure")
+	})
+
+	require.NoError(t, tm.Enqueue("test:skip", nil))
+	require.NoError(t, tm.Enqueue("test:fail", nil, MaxRetry(2)))
+	require.NoError(t, tm.Enqueue("test:unknown", nil, MaxRetry(0)))
+	err := tm.Drain(context.Background())
+	require.Error(t, err)
+	require.ErrorIs(t, err, asynq.SkipRetry)
+	require.Equal(t, 1+3, calls)
+
+	archived := tm.Archived()
+	require.Len(t, archived, 3)
+	require.Equal(t, 2, tm.Archived("test:fail")[0].Retried)
+}
+
+func TestMemoryTaskManagerUniqueKey(t *testing.T) {
+	tm := NewMemoryTaskManager(1)
+	tm.On("test:unique", func(ctx context.Context, payload []byte) error { return nil })
+
+	require.NoError(t, tm.Enqueue("test:unique", nil, UniqueKey("k")))
+	require.ErrorIs(t, tm.Enqueue("test:unique", nil, UniqueKey("k")), ErrDuplicateTask)
+	require.NoError(t, tm.Drain(context.Background()))
+	require.NoError(t, tm.Enqueue("test:unique", nil, UniqueKey("k")))
+	require.Len(t, tm.Enqueued("test:unique"), 2)
+}
+
+func TestMemoryTaskManagerRun(t *testing.T) {
+	tm := NewMemoryTaskManager(4)
+	var processed atomic.Int32
+	done := make(chan struct{})
+	tm.On("test:run", func(ctx context.Context, payload []byte) error {
+		if processed.Add(1) == 5 {
+			close(done)
+		}
+		return nil
+	})
+	tm.On("test:panic", func(ctx context.Context, payload []byte) error {
+		panic("boom")
+	})
+
+	stopped := make(chan error)
+	go func() { stopped <- tm.Run() }()
+
+	require.NoError(t, tm.Enqueue("test:panic", nil, MaxRetry(0)))
+	for i := 0; i < 4; i++ {
+		require.NoError(t, tm.Enqueue("test:run", nil))
+	}
+	require.NoError(t, tm.Enqueue("test:run", nil, Delay(50*time.Millisecond)))
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tasks were not processed")
+	}
+	require.Eventually(t, func() bool {
+		return len(tm.Archived("test:panic")) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	tm.Shutdown()
+	require.NoError(t, <-stopped)
+}
+
+func TestMemoryTaskManagerAsyncEventEmitter(t *testing.T) {
+	tm := NewMemoryTaskManager(1, WithEventEmitter(NewAsyncEventEmitter()))
+	// Each handler only returns once the other one started, which deadlocks if they run one after another
+	a, b := make(chan struct{}), make(chan struct{})
+	tm.On("test:async", func(ctx context.Context, payload []byte) error {
+		close(a)
+		<-b
+		return nil
+	})
+	tm.On("test:async", func(ctx context.Context, payload []byte) error {
+		close(b)
+		<-a
+		return nil
+	})
+
+	require.NoError(t, tm.Enqueue("test:async", nil))
+	require.NoError(t, tm.Drain(context.Background()))
+	require.Empty(t, tm.Archived())
+}
+
+func TestNewEventEmitter(t *testing.T) {
+	emitter, err := NewEventEmitter("")
+	require.NoError(t, err)
+	require.IsType(t, &SimpleEventEmitter{}, emitter)
+
+	emitter, err = NewEventEmitter(EmitterAsync)
+	require.NoError(t, err)
+	require.IsType(t, &AsyncEventEmitter{}, emitter)
+
+	_, err = NewEventEmitter("unknown")
+	require.Error(t, err)
+}
@@ -0,0 +1,68 @@
+package tasks
+
+import (
+	"log"
+	"time"
+
+	"github.com/fadedreams/gofinanceflow/foundation/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// observeTask records the outcome and duration of a processed task.
+func observeTask(taskType string, start time.Time, err error) {
+	outcome := metrics.TaskSucceeded
+	if err != nil {
+		outcome = metrics.TaskFailed
+	}
+	metrics.TasksProcessed.WithLabelValues(taskType, outcome).Inc()
+	metrics.TaskDuration.WithLabelValues(taskType).Observe(time.Since(start).Seconds())
+}
+
+// queueCollector exposes the number of tasks in each state of every queue, read on every scrape.
+type queueCollector struct {
+	inspector Inspector
+	tasks     *prometheus.Desc
+	paused    *prometheus.Desc
+}
+
+// NewQueueCollector creates a collector for the queue depths reported by an Inspector.
+func NewQueueCollector(inspector Inspector) prometheus.Collector {
+	return &queueCollector{
+		inspector: inspector,
+		tasks: prometheus.NewDesc("gofinanceflow_task_queue_tasks",
+			"Tasks in a queue by state: pending, active, scheduled, retry or archived.", []string{"queue", "state"}, nil),
+		paused: prometheus.NewDesc("gofinanceflow_task_queue_paused",
+			"Whether a queue is paused.", []string{"queue"}, nil),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *queueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.tasks
+	ch <- c.paused
+}
+
+// Collect implements prometheus.Collector.
+func (c *queueCollector) Collect(ch chan<- prometheus.Metric) {
+	queues, err := c.inspector.Queues()
+	if err != nil {
+		log.Printf("Failed to collect queue metrics: %v", err)
+		return
+	}
+	for _, q := range queues {
+		for state, n := range map[string]int{
+			"pending":   q.Pending,
+			"active":    q.Active,
+			"scheduled": q.Scheduled,
+			"retry":     q.Retry,
+			"archived":  q.Archived,
+		} {
+			ch <- prometheus.MustNewConstMetric(c.tasks, prometheus.GaugeValue, float64(n), q.Queue, state)
+		}
+		paused := 0.0
+		if q.Paused {
+			paused = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.paused, prometheus.GaugeValue, paused, q.Queue)
+	}
+}
@@ -0,0 +1,73 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fadedreams/gofinanceflow/foundation/metrics"
+	"github.com/hibiken/asynq"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskOutcomeMetrics(t *testing.T) {
+	tm := NewMemoryTaskManager(1)
+	tm.On("test:metrics", func(ctx context.Context, payload []byte) error {
+		if string(payload) == "fail" {
+			return asynq.SkipRetry
+		}
+		return nil
+	})
+
+	// The counters are global, so only what this run added is compared
+	succeeded := testutil.ToFloat64(metrics.TasksProcessed.WithLabelValues("test:metrics", metrics.TaskSucceeded))
+	failed := testutil.ToFloat64(metrics.TasksProcessed.WithLabelValues("test:metrics", metrics.TaskFailed))
+
+	require.NoError(t, tm.Enqueue("test:metrics", nil))
+	require.NoError(t, tm.Enqueue("test:metrics", []byte("fail")))
+	require.True(t, errors.Is(tm.Drain(context.Background()), asynq.SkipRetry))
+
+	require.Equal(t, succeeded+1, testutil.ToFloat64(metrics.TasksProcessed.WithLabelValues("test:metrics", metrics.TaskSucceeded)))
+	require.Equal(t, failed+1, testutil.ToFloat64(metrics.TasksProcessed.WithLabelValues("test:metrics", metrics.TaskFailed)))
+}
+
+func TestQueueCollector(t *testing.T) {
+	tm := NewMemoryTaskManager(1)
+	require.NoError(t, tm.PauseQueue(QueueLow))
+	require.NoError(t, tm.Enqueue("test:queued", nil, Queue(QueueLow)))
+	require.NoError(t, tm.Enqueue("test:queued", nil, Queue(QueueLow)))
+
+	expected := `
+# HELP gofinanceflow_task_queue_paused Whether a queue is paused.
+# TYPE gofinanceflow_task_queue_paused gauge
+gofinanceflow_task_queue_paused{queue="critical"} 0
+gofinanceflow_task_queue_paused{queue="default"} 0
+gofinanceflow_task_queue_paused{queue="low"} 1
+`
+	collector := NewQueueCollector(tm)
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected), "gofinanceflow_task_queue_paused"))
+	require.Equal(t, 3*6, testutil.CollectAndCount(collector))
+
+	pending := `
+# HELP gofinanceflow_task_queue_tasks Tasks in a queue by state: pending, active, scheduled, retry or archived.
+# TYPE gofinanceflow_task_queue_tasks gauge
+gofinanceflow_task_queue_tasks{queue="critical",state="active"} 0
+gofinanceflow_task_queue_tasks{queue="critical",state="archived"} 0
+gofinanceflow_task_queue_tasks{queue="critical",state="pending"} 0
+gofinanceflow_task_queue_tasks{queue="critical",state="retry"} 0
+gofinanceflow_task_queue_tasks{queue="critical",state="scheduled"} 0
+gofinanceflow_task_queue_tasks{queue="default",state="active"} 0
+gofinanceflow_task_queue_tasks{queue="default",state="archived"} 0
+gofinanceflow_task_queue_tasks{queue="default",state="pending"} 0
+gofinanceflow_task_queue_tasks{queue="default",state="retry"} 0
+gofinanceflow_task_queue_tasks{queue="default",state="scheduled"} 0
+gofinanceflow_task_queue_tasks{queue="low",state="active"} 0
+gofinanceflow_task_queue_tasks{queue="low",state="archived"} 0
+gofinanceflow_task_queue_tasks{queue="low",state="pending"} 2
+gofinanceflow_task_queue_tasks{queue="low",state="retry"} 0
+gofinanceflow_task_queue_tasks{queue="low",state="scheduled"} 0
+`
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(pending), "gofinanceflow_task_queue_tasks"))
+}
@@ -0,0 +1,128 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+// Queues processed by the task server, see queuePriorities.
+const (
+	QueueCritical = "critical"
+	QueueDefault  = "default"
+	QueueLow      = "low"
+)
+
+// queuePriorities weighs how often each queue is polled.
+var queuePriorities = map[string]int{
+	QueueCritical: 6,
+	QueueDefault:  3,
+	QueueLow:      1,
+}
+
+// ErrDuplicateTask is returned when a task with the same unique key is still enqueued.
+var ErrDuplicateTask = errors.New("task already enqueued")
+
+// EnqueueOptions controls how and when a task is processed.
+type EnqueueOptions struct {
+	Queue     string
+	ProcessAt time.Time
+	Delay     time.Duration
+	MaxRetry  *int
+	Timeout   time.Duration
+	UniqueKey string
+}
+
+// EnqueueOption sets one of the EnqueueOptions.
+type EnqueueOption func(*EnqueueOptions)
+
+// Queue enqueues the task on the named queue instead of QueueDefault.
+func Queue(name string) EnqueueOption {
+	return func(o *EnqueueOptions) { o.Queue = name }
+}
+
+// ProcessAt processes the task no earlier than t.
+func ProcessAt(t time.Time) EnqueueOption {
+	return func(o *EnqueueOptions) { o.ProcessAt = t }
+}
+
+// Delay processes the task no earlier than d from now.
+func Delay(d time.Duration) EnqueueOption {
+	return func(o *EnqueueOptions) { o.Delay = d }
+}
+
+// MaxRetry limits how often a failing task is retried.
+func MaxRetry(n int) EnqueueOption {
+	return func(o *EnqueueOptions) { o.MaxRetry = &n }
+}
+
+// Timeout cancels the context of a task that runs longer than d.
+func Timeout(d time.Duration) EnqueueOption {
+	return func(o *EnqueueOptions) { o.Timeout = d }
+}
+
+// UniqueKey rejects the task with ErrDuplicateTask while another task with the same key is enqueued.
+func UniqueKey(key string) EnqueueOption {
+	return func(o *EnqueueOptions) { o.UniqueKey = key }
+}
+
+// NewEnqueueOptions applies opts to the default options.
+func NewEnqueueOptions(opts ...EnqueueOption) EnqueueOptions {
+	o := EnqueueOptions{Queue: QueueDefault}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Register registers a handler that receives the decoded JSON payload of every task of the given type.
+// Tasks without a payload receive the zero value; payloads that cannot be decoded are not retried.
+func Register[T any](tm TaskManager, taskType string, handler func(ctx context.Context, payload T) error) {
+	tm.On(taskType, func(ctx context.Context, data []byte) error {
+		var payload T
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &payload); err != nil {
+				return fmt.Errorf("json.Unmarshal failed: %v: %w", err, asynq.SkipRetry)
+			}
+		}
+		return handler(ctx, payload)
+	})
+}
+
+// Enqueue enqueues a task of the given type with a JSON-encoded payload.
+func Enqueue[T any](tm TaskManager, taskType string, payload T, opts ...EnqueueOption) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return tm.Enqueue(taskType, data, opts...)
+}
+
+// ManagerOptions configures a TaskManager.
+type ManagerOptions struct {
+	EventEmitter EventEmitter
+}
+
+// ManagerOption sets one of the ManagerOptions.
+type ManagerOption func(*ManagerOptions)
+
+// WithEventEmitter dispatches tasks to their handlers through e instead of a SimpleEventEmitter.
+func WithEventEmitter(e EventEmitter) ManagerOption {
+	return func(o *ManagerOptions) { o.EventEmitter = e }
+}
+
+// NewManagerOptions applies opts to the default options.
+func NewManagerOptions(opts ...ManagerOption) ManagerOptions {
+	o := ManagerOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.EventEmitter == nil {
+		o.EventEmitter = NewSimpleEventEmitter()
+	}
+	return o
+}
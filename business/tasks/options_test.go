@@ -0,0 +1,22 @@
+package tasks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEnqueueOptions(t *testing.T) {
+	o := NewEnqueueOptions()
+	require.Equal(t, QueueDefault, o.Queue)
+	require.Nil(t, o.MaxRetry)
+
+	o = NewEnqueueOptions(Queue(QueueCritical), Delay(time.Minute), MaxRetry(0), Timeout(time.Second), UniqueKey("k"))
+	require.Equal(t, QueueCritical, o.Queue)
+	require.Equal(t, time.Minute, o.Delay)
+	require.NotNil(t, o.MaxRetry)
+	require.Zero(t, *o.MaxRetry)
+	require.Equal(t, time.Second, o.Timeout)
+	require.Equal(t, "k", o.UniqueKey)
+}
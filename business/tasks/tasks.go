@@ -2,16 +2,30 @@ package tasks
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
 )
 
 const (
-	TypeEmailDelivery = "email:deliver"
+	TypeEmailDelivery               = "email:deliver"
+	TypeScheduledTransfer           = "transfer:scheduled"
+	TypeRecurringTransferDispatch   = "transfer:recurring:dispatch"
+	TypeRecurringTransferOccurrence = "transfer:recurring:occurrence"
+	TypeHoldExpiry                  = "hold:expire"
+	TypeInterestAccrual             = "interest:accrue"
+	TypeInterestPosting             = "interest:post"
+	TypeTransactionExport           = "transaction:export"
+	TypeTransactionExportCleanup    = "transaction:export:cleanup"
+	TypeTransferBatch               = "transfer:batch"
+	TypeOutboxCleanup               = "outbox:cleanup"
+	TypeWebhookDelivery             = "webhook:deliver"
+	TypeWebhookSweep                = "webhook:sweep"
 )
 
 // EventEmitter defines the methods for an event emitter.
@@ -40,11 +54,13 @@ func (e *SimpleEventEmitter) On(event string, listener func(ctx context.Context,
 	e.listeners[event] = append(e.listeners[event], listener)
 }
 
-// Emit emits an event to all registered listeners.
+// Emit calls the listeners of an event one after another and stops at the first error.
+// The listeners are called without holding the lock, so they may register listeners themselves.
 func (e *SimpleEventEmitter) Emit(event string, ctx context.Context, payload []byte) error {
 	e.mu.RLock()
-	defer e.mu.RUnlock()
-	for _, listener := range e.listeners[event] {
+	listeners := e.listeners[event]
+	e.mu.RUnlock()
+	for _, listener := range listeners {
 		if err := listener(ctx, payload); err != nil {
 			return err
 		}
@@ -54,70 +70,275 @@ func (e *SimpleEventEmitter) Emit(event string, ctx context.Context, payload []b
 
 // TaskManager defines the methods for managing tasks.
 type TaskManager interface {
-	EnqueueEmailDeliveryTask(userID int, tmplID string) error
+	EnqueueEmailDeliveryTask(username string, tmplID string, data map[string]string) error
+	EnqueueScheduledTransferTask(scheduledTransferID int64, processAt time.Time) error
+	EnqueueRecurringTransferOccurrenceTask(occurrenceID int64) error
+	EnqueueTransactionExportTask(exportID int64) error
+	EnqueueTransferBatchTask(batchID int64) error
+	EnqueueWebhookDeliveryTask(deliveryID int64, attempt int, delay time.Duration) error
+	Enqueue(taskType string, payload []byte, opts ...EnqueueOption) error
+	On(taskType string, handler func(ctx context.Context, payload []byte) error)
+	RegisterPeriodicTask(cronspec string, taskType string)
 	Run() error
+	RunPeriodic() error
+	Shutdown()
+	Close() error
 }
 
 // taskManager is a concrete implementation of TaskManager.
 type taskManager struct {
-	client       *asynq.Client
-	server       *asynq.Server
-	eventEmitter EventEmitter
+	enqueuer
+	client        *asynq.Client
+	server        *asynq.Server
+	inspector     *asynq.Inspector
+	redis         redis.UniversalClient
+	redisOpt      asynq.RedisClientOpt
+	eventEmitter  EventEmitter
+	handlersMu    sync.Mutex
+	handlerTypes  []string
+	periodicMu    sync.Mutex
+	periodicTasks []*asynq.PeriodicTaskConfig
+	done          chan struct{}
+	closeOnce     sync.Once
 }
 
 // NewTaskManager creates a new TaskManager with the given Redis options.
-func NewTaskManager(redisOpt asynq.RedisClientOpt) TaskManager {
+func NewTaskManager(redisOpt asynq.RedisClientOpt, opts ...ManagerOption) TaskManager {
+	o := NewManagerOptions(opts...)
 	client := asynq.NewClient(redisOpt)
 	server := asynq.NewServer(redisOpt, asynq.Config{
 		Concurrency: 10,
+		Queues:      queuePriorities,
 	})
 
-	eventEmitter := NewSimpleEventEmitter()
 	tm := &taskManager{
 		client:       client,
 		server:       server,
-		eventEmitter: eventEmitter,
+		inspector:    asynq.NewInspector(redisOpt),
+		redis:        redisOpt.MakeRedisClient().(redis.UniversalClient),
+		redisOpt:     redisOpt,
+		eventEmitter: o.EventEmitter,
+		done:         make(chan struct{}),
 	}
-
-	// Register default task handlers
-	tm.eventEmitter.On(TypeEmailDelivery, HandleEmailDeliveryTask)
-
+	tm.enqueuer = enqueuer{tm}
 	return tm
 }
 
+// EmailDeliveryMaxRetry is how often a failed email is retried before it goes to the archive.
+const EmailDeliveryMaxRetry = 10
+
 // EmailDeliveryPayload defines the payload for email delivery tasks.
 type EmailDeliveryPayload struct {
-	UserID     int
+	Username   string
 	TemplateID string
+	Data       map[string]string
 }
 
-// EnqueueEmailDeliveryTask enqueues a task to deliver an email.
-func (tm *taskManager) EnqueueEmailDeliveryTask(userID int, tmplID string) error {
-	payload, err := json.Marshal(EmailDeliveryPayload{UserID: userID, TemplateID: tmplID})
-	if err != nil {
-		return err
+// enqueuer implements the typed Enqueue methods of TaskManager on top of Enqueue,
+// so every backend schedules its tasks the same way.
+type enqueuer struct {
+	tm TaskManager
+}
+
+// EnqueueEmailDeliveryTask enqueues a task to deliver the email of a template to the given user.
+func (e enqueuer) EnqueueEmailDeliveryTask(username string, tmplID string, data map[string]string) error {
+	return Enqueue(e.tm, TypeEmailDelivery, EmailDeliveryPayload{Username: username, TemplateID: tmplID, Data: data},
+		MaxRetry(EmailDeliveryMaxRetry),
+	)
+}
+
+// ScheduledTransferPayload defines the payload for scheduled transfer tasks.
+type ScheduledTransferPayload struct {
+	ScheduledTransferID int64
+}
+
+// EnqueueScheduledTransferTask enqueues a task that executes a scheduled transfer at processAt.
+func (e enqueuer) EnqueueScheduledTransferTask(scheduledTransferID int64, processAt time.Time) error {
+	return Enqueue(e.tm, TypeScheduledTransfer, ScheduledTransferPayload{ScheduledTransferID: scheduledTransferID},
+		ProcessAt(processAt),
+		UniqueKey(fmt.Sprintf("%s:%d", TypeScheduledTransfer, scheduledTransferID)),
+	)
+}
+
+// RecurringTransferOccurrencePayload defines the payload for recurring transfer occurrence tasks.
+type RecurringTransferOccurrencePayload struct {
+	OccurrenceID int64
+}
+
+// EnqueueRecurringTransferOccurrenceTask enqueues a task that executes one occurrence of a recurring transfer.
+// An occurrence that is already queued is not enqueued again.
+func (e enqueuer) EnqueueRecurringTransferOccurrenceTask(occurrenceID int64) error {
+	err := Enqueue(e.tm, TypeRecurringTransferOccurrence, RecurringTransferOccurrencePayload{OccurrenceID: occurrenceID},
+		UniqueKey(fmt.Sprintf("%s:%d", TypeRecurringTransferOccurrence, occurrenceID)),
+	)
+	if errors.Is(err, ErrDuplicateTask) {
+		return nil
 	}
-	task := asynq.NewTask(TypeEmailDelivery, payload)
-	_, err = tm.client.Enqueue(task)
 	return err
 }
 
-// HandleEmailDeliveryTask handles the email delivery task.
-func HandleEmailDeliveryTask(ctx context.Context, payload []byte) error {
-	var p EmailDeliveryPayload
-	if err := json.Unmarshal(payload, &p); err != nil {
-		return fmt.Errorf("json.Unmarshal failed: %v: %w", err, asynq.SkipRetry)
+// TransactionExportPayload defines the payload for transaction export tasks.
+type TransactionExportPayload struct {
+	ExportID int64
+}
+
+// EnqueueTransactionExportTask enqueues a task that generates the file of a transaction export.
+func (e enqueuer) EnqueueTransactionExportTask(exportID int64) error {
+	return Enqueue(e.tm, TypeTransactionExport, TransactionExportPayload{ExportID: exportID},
+		Queue(QueueLow),
+		UniqueKey(fmt.Sprintf("%s:%d", TypeTransactionExport, exportID)),
+		Timeout(30*time.Minute),
+	)
+}
+
+// TransferBatchPayload defines the payload for transfer batch tasks.
+type TransferBatchPayload struct {
+	BatchID int64
+}
+
+// EnqueueTransferBatchTask enqueues a task that executes the transfers of a batch.
+func (e enqueuer) EnqueueTransferBatchTask(batchID int64) error {
+	return Enqueue(e.tm, TypeTransferBatch, TransferBatchPayload{BatchID: batchID},
+		UniqueKey(fmt.Sprintf("%s:%d", TypeTransferBatch, batchID)),
+		Timeout(30*time.Minute),
+	)
+}
+
+// WebhookDeliveryPayload defines the payload for webhook delivery tasks.
+type WebhookDeliveryPayload struct {
+	DeliveryID int64
+	Attempt    int
+}
+
+// EnqueueWebhookDeliveryTask enqueues an attempt to deliver a webhook after delay.
+// Failed attempts are rescheduled by the handler rather than retried by the task manager.
+func (e enqueuer) EnqueueWebhookDeliveryTask(deliveryID int64, attempt int, delay time.Duration) error {
+	return Enqueue(e.tm, TypeWebhookDelivery, WebhookDeliveryPayload{DeliveryID: deliveryID, Attempt: attempt},
+		Delay(delay),
+		UniqueKey(fmt.Sprintf("%s:%d:%d", TypeWebhookDelivery, deliveryID, attempt)),
+		Timeout(time.Minute),
+	)
+}
+
+// Enqueue enqueues a task of the given type with a raw payload.
+func (tm *taskManager) Enqueue(taskType string, payload []byte, opts ...EnqueueOption) error {
+	o := NewEnqueueOptions(opts...)
+	asynqOpts := []asynq.Option{asynq.Queue(o.Queue)}
+	if !o.ProcessAt.IsZero() {
+		asynqOpts = append(asynqOpts, asynq.ProcessAt(o.ProcessAt))
 	}
-	log.Printf("Sending Email to User: user_id=%d, template_id=%s", p.UserID, p.TemplateID)
-	// Email delivery code ...
-	return nil
+	if o.Delay > 0 {
+		asynqOpts = append(asynqOpts, asynq.ProcessIn(o.Delay))
+	}
+	if o.MaxRetry != nil {
+		asynqOpts = append(asynqOpts, asynq.MaxRetry(*o.MaxRetry))
+	}
+	if o.Timeout > 0 {
+		asynqOpts = append(asynqOpts, asynq.Timeout(o.Timeout))
+	}
+	if o.UniqueKey != "" {
+		asynqOpts = append(asynqOpts, asynq.TaskID(o.UniqueKey))
+	}
+
+	_, err := tm.client.Enqueue(asynq.NewTask(taskType, payload), asynqOpts...)
+	if errors.Is(err, asynq.ErrTaskIDConflict) {
+		return fmt.Errorf("%w: %s", ErrDuplicateTask, o.UniqueKey)
+	}
+	return err
 }
 
-// Run starts the asynq server to process tasks.
+// On registers a handler that is called for every processed task of the given type.
+func (tm *taskManager) On(taskType string, handler func(ctx context.Context, payload []byte) error) {
+	tm.handlersMu.Lock()
+	if !slices.Contains(tm.handlerTypes, taskType) {
+		tm.handlerTypes = append(tm.handlerTypes, taskType)
+	}
+	tm.handlersMu.Unlock()
+	tm.eventEmitter.On(taskType, handler)
+}
+
+// Run starts the asynq server to process every task type a handler was registered for
+// and blocks until Shutdown is called.
 func (tm *taskManager) Run() error {
+	tm.handlersMu.Lock()
+	taskTypes := slices.Clone(tm.handlerTypes)
+	tm.handlersMu.Unlock()
+
 	mux := asynq.NewServeMux()
-	mux.HandleFunc(TypeEmailDelivery, func(ctx context.Context, t *asynq.Task) error {
-		return tm.eventEmitter.Emit(TypeEmailDelivery, ctx, t.Payload())
+	for _, taskType := range taskTypes {
+		mux.HandleFunc(taskType, func(ctx context.Context, t *asynq.Task) error {
+			start := time.Now()
+			err := tm.eventEmitter.Emit(taskType, ctx, t.Payload())
+			observeTask(taskType, start, err)
+			return err
+		})
+	}
+	if err := tm.server.Start(mux); err != nil {
+		return err
+	}
+	<-tm.done
+	return nil
+}
+
+// RegisterPeriodicTask enqueues a task of the given type on every tick of cronspec once RunPeriodic is running.
+func (tm *taskManager) RegisterPeriodicTask(cronspec string, taskType string) {
+	tm.periodicMu.Lock()
+	defer tm.periodicMu.Unlock()
+	tm.periodicTasks = append(tm.periodicTasks, &asynq.PeriodicTaskConfig{
+		Cronspec: cronspec,
+		Task:     asynq.NewTask(taskType, nil),
+		Opts:     []asynq.Option{asynq.Unique(time.Minute)},
+	})
+}
+
+// GetConfigs implements asynq.PeriodicTaskConfigProvider.
+func (tm *taskManager) GetConfigs() ([]*asynq.PeriodicTaskConfig, error) {
+	tm.periodicMu.Lock()
+	defer tm.periodicMu.Unlock()
+	return append([]*asynq.PeriodicTaskConfig(nil), tm.periodicTasks...), nil
+}
+
+// RunPeriodic starts the asynq periodic task manager that enqueues the registered periodic tasks
+// until Shutdown is called.
+func (tm *taskManager) RunPeriodic() error {
+	mgr, err := asynq.NewPeriodicTaskManager(asynq.PeriodicTaskManagerOpts{
+		RedisConnOpt:               tm.redisOpt,
+		PeriodicTaskConfigProvider: tm,
+		SyncInterval:               time.Minute,
 	})
-	return tm.server.Run(mux)
+	if err != nil {
+		return err
+	}
+	if err := mgr.Start(); err != nil {
+		return err
+	}
+	<-tm.done
+	mgr.Shutdown()
+	return nil
+}
+
+// Shutdown stops RunPeriodic and stops Run once the tasks being processed have finished.
+// Unlike asynq's own Run, the task manager does not handle signals; the caller decides when to stop.
+func (tm *taskManager) Shutdown() {
+	tm.closeOnce.Do(func() {
+		close(tm.done)
+		tm.server.Shutdown()
+	})
+}
+
+// Pinger is implemented by task managers that depend on a server, to check it is reachable.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+var _ Pinger = (*taskManager)(nil)
+
+// Ping checks that Redis is reachable.
+func (tm *taskManager) Ping(ctx context.Context) error {
+	return tm.redis.Ping(ctx).Err()
+}
+
+// Close releases the Redis connections used to enqueue and inspect tasks.
+func (tm *taskManager) Close() error {
+	return errors.Join(tm.client.Close(), tm.inspector.Close(), tm.redis.Close())
 }
@@ -0,0 +1,42 @@
+// Package taskstest provides helpers for tests that enqueue tasks.
+package taskstest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/fadedreams/gofinanceflow/business/tasks"
+	"github.com/stretchr/testify/require"
+)
+
+// New returns an in-memory TaskManager that is shut down when the test ends.
+func New(t testing.TB) *tasks.MemoryTaskManager {
+	t.Helper()
+	tm := tasks.NewMemoryTaskManager(1)
+	t.Cleanup(tm.Shutdown)
+	return tm
+}
+
+// RequireEnqueued fails the test unless exactly n tasks of the given type were enqueued and returns them.
+func RequireEnqueued(t testing.TB, tm *tasks.MemoryTaskManager, taskType string, n int) []tasks.Task {
+	t.Helper()
+	enqueued := tm.Enqueued(taskType)
+	require.Len(t, enqueued, n, "tasks of type %s enqueued", taskType)
+	return enqueued
+}
+
+// RequirePayload decodes the JSON payload of a task.
+func RequirePayload[T any](t testing.TB, task tasks.Task) T {
+	t.Helper()
+	var payload T
+	require.NoError(t, json.Unmarshal(task.Payload, &payload))
+	return payload
+}
+
+// RequireDrain processes every pending task and fails the test if any of them was archived.
+func RequireDrain(t testing.TB, tm *tasks.MemoryTaskManager) {
+	t.Helper()
+	require.NoError(t, tm.Drain(context.Background()))
+	require.Zero(t, tm.Pending())
+}
@@ -0,0 +1,200 @@
+package userservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	"github.com/fadedreams/gofinanceflow/foundation/sdk"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// CreateAccount opens a new named account for the owner.
+// The owner's first account in a currency becomes the default account for that currency.
+func (us *UserService) CreateAccount(ctx context.Context, owner string, arg domain.CreateAccountParams) (*db.Account, error) {
+	if !sdk.IsSupportedCurrency(arg.Currency) {
+		return nil, fmt.Errorf("unsupported currency %q", arg.Currency)
+	}
+	if arg.ProductType == "" {
+		arg.ProductType = domain.AccountProductChecking
+	}
+	if !domain.IsValidAccountProductType(arg.ProductType) {
+		return nil, fmt.Errorf("unknown product type %q", arg.ProductType)
+	}
+
+	var account db.Account
+	err := us.ExecuteInTransaction(ctx, func(q *db.Queries) error {
+		_, err := q.GetDefaultAccount(ctx, db.GetDefaultAccountParams{Owner: owner, Currency: arg.Currency})
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("failed to get default account: %v", err)
+		}
+		isDefault := errors.Is(err, pgx.ErrNoRows)
+
+		account, err = q.CreateNamedAccount(ctx, db.CreateNamedAccountParams{
+			Owner:       owner,
+			Balance:     0,
+			Currency:    arg.Currency,
+			Nickname:    arg.Nickname,
+			ProductType: arg.ProductType,
+			IsDefault:   isDefault,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create account: %v", err)
+		}
+		return recordEvent(ctx, q, domain.AggregateAccount, account.ID, domain.EventAccountCreated, domain.AccountCreatedEvent{
+			AccountID:   account.ID,
+			Owner:       account.Owner,
+			Currency:    account.Currency,
+			ProductType: account.ProductType,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// ListAccounts returns the owner's accounts matching the filters.
+func (us *UserService) ListAccounts(ctx context.Context, owner string, arg domain.ListAccountsParams) ([]db.Account, error) {
+	params := db.ListAccountsParams{
+		Owner:  owner,
+		Limit:  arg.Limit,
+		Offset: arg.Offset,
+	}
+	if arg.Currency != "" {
+		params.Currency = pgtype.Text{String: arg.Currency, Valid: true}
+	}
+	if arg.ProductType != "" {
+		params.ProductType = pgtype.Text{String: arg.ProductType, Valid: true}
+	}
+	if arg.Status != "" {
+		params.Status = pgtype.Text{String: arg.Status, Valid: true}
+	}
+	return us.store.ListAccounts(ctx, params)
+}
+
+// SetDefaultAccount makes the account the owner's default account for its currency.
+func (us *UserService) SetDefaultAccount(ctx context.Context, owner string, accountID int64) (*db.Account, error) {
+	var account db.Account
+	err := us.ExecuteInTransaction(ctx, func(q *db.Queries) error {
+		current, err := q.GetAccountForUpdate(ctx, accountID)
+		if err != nil {
+			return fmt.Errorf("account %d not found", accountID)
+		}
+		if current.Owner != owner {
+			return fmt.Errorf("account does not belong to %s", owner)
+		}
+		if current.Status != domain.AccountStatusActive {
+			return fmt.Errorf("cannot make a %s account the default", current.Status)
+		}
+
+		// Clear the old default first so the unique default index holds
+		err = q.ClearDefaultAccount(ctx, db.ClearDefaultAccountParams{Owner: owner, Currency: current.Currency})
+		if err != nil {
+			return fmt.Errorf("failed to clear default account: %v", err)
+		}
+		account, err = q.SetDefaultAccount(ctx, current.ID)
+		if err != nil {
+			return fmt.Errorf("failed to set default account: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// ChangeAccountStatus applies a lifecycle action (freeze, unfreeze, close or reopen) to an account
+// on behalf of its owner or an admin, and records it in the account's audit log.
+func (us *UserService) ChangeAccountStatus(ctx context.Context, accountID int64, actor string, admin bool, action string, arg domain.ChangeAccountStatusParams) (db.Account, error) {
+	var account db.Account
+	err := us.ExecuteInTransaction(ctx, func(q *db.Queries) error {
+		current, err := q.GetAccountForUpdate(ctx, accountID)
+		if err != nil {
+			return fmt.Errorf("account %d not found", accountID)
+		}
+		if !admin && current.Owner != actor {
+			return fmt.Errorf("account does not belong to %s", actor)
+		}
+
+		status, err := domain.TransitionAccount(action, current.Status, admin)
+		if err != nil {
+			return err
+		}
+
+		// Only empty accounts may be closed, so no money is stranded
+		if status == domain.AccountStatusClosed {
+			if current.Balance != 0 {
+				return fmt.Errorf("account balance must be zero to close, got %d", current.Balance)
+			}
+			held, err := q.GetAccountHeldAmount(ctx, current.ID)
+			if err != nil {
+				return fmt.Errorf("failed to get held amount: %v", err)
+			}
+			if held != 0 {
+				return fmt.Errorf("account has active holds")
+			}
+		}
+
+		account, err = q.UpdateAccountStatus(ctx, db.UpdateAccountStatusParams{
+			ID:     current.ID,
+			Status: status,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update account status: %v", err)
+		}
+
+		_, err = q.CreateAccountAuditLog(ctx, db.CreateAccountAuditLogParams{
+			AccountID:  current.ID,
+			Action:     action,
+			FromStatus: current.Status,
+			ToStatus:   status,
+			Actor:      actor,
+			Reason:     arg.Reason,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record account audit log: %v", err)
+		}
+		return nil
+	})
+	return account, err
+}
+
+// ErrAccountNotFound is returned for accounts that do not exist or are hidden from the caller.
+var ErrAccountNotFound = errors.New("account not found")
+
+// ErrAccountNotOwned is returned when an account is used on behalf of a user who does not own it.
+var ErrAccountNotOwned = errors.New("account does not belong to the caller")
+
+// CheckAccountOwner returns ErrAccountNotOwned unless the account belongs to owner.
+func (us *UserService) CheckAccountOwner(ctx context.Context, accountID int64, owner string) error {
+	account, err := us.store.GetAccount(ctx, accountID)
+	if err != nil {
+		return fmt.Errorf("account %d not found", accountID)
+	}
+	if account.Owner != owner {
+		return fmt.Errorf("%w: account %d", ErrAccountNotOwned, accountID)
+	}
+	return nil
+}
+
+// ListAccountAuditLogs returns the lifecycle changes of an account, newest first.
+func (us *UserService) ListAccountAuditLogs(ctx context.Context, accountID int64, actor string, admin bool, arg domain.ListAccountAuditLogsParams) ([]db.AccountAuditLog, error) {
+	account, err := us.store.GetAccount(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("account %d not found", accountID)
+	}
+	if !admin && account.Owner != actor {
+		return nil, fmt.Errorf("account does not belong to %s", actor)
+	}
+
+	return us.store.ListAccountAuditLogs(ctx, db.ListAccountAuditLogsParams{
+		AccountID: accountID,
+		Limit:     arg.Limit,
+		Offset:    arg.Offset,
+	})
+}
@@ -0,0 +1,72 @@
+package userservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrInsufficientFunds is returned when a debit would take an account below what its policy allows.
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// GetAccountPolicy returns the balance policy of an account.
+// Accounts without a stored policy use the default policy, which allows no overdraft.
+func (us *UserService) GetAccountPolicy(ctx context.Context, accountID int64) (db.AccountPolicy, error) {
+	if _, err := us.store.GetAccount(ctx, accountID); err != nil {
+		return db.AccountPolicy{}, fmt.Errorf("account %d not found", accountID)
+	}
+	return accountPolicy(ctx, us.store, accountID)
+}
+
+// UpdateAccountPolicy replaces the balance policy of an account.
+func (us *UserService) UpdateAccountPolicy(ctx context.Context, accountID int64, updatedBy string, arg domain.UpdateAccountPolicyParams) (db.AccountPolicy, error) {
+	if arg.OverdraftLimit < 0 {
+		return db.AccountPolicy{}, fmt.Errorf("overdraft_limit must not be negative")
+	}
+
+	var policy db.AccountPolicy
+	err := us.ExecuteInTransaction(ctx, func(q *db.Queries) error {
+		// Lock the account so the policy cannot change under a running transfer
+		if _, err := q.GetAccountForUpdate(ctx, accountID); err != nil {
+			return fmt.Errorf("account %d not found", accountID)
+		}
+
+		var err error
+		policy, err = q.UpsertAccountPolicy(ctx, db.UpsertAccountPolicyParams{
+			AccountID:          accountID,
+			MinBalance:         arg.MinBalance,
+			OverdraftLimit:     arg.OverdraftLimit,
+			UnlimitedOverdraft: arg.UnlimitedOverdraft,
+			UpdatedBy:          updatedBy,
+		})
+		return err
+	})
+	return policy, err
+}
+
+// accountPolicy returns the stored balance policy of an account or the default policy.
+func accountPolicy(ctx context.Context, q *db.Queries, accountID int64) (db.AccountPolicy, error) {
+	policy, err := q.GetAccountPolicy(ctx, accountID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return db.AccountPolicy{AccountID: accountID}, nil
+	}
+	if err != nil {
+		return policy, fmt.Errorf("failed to get account policy: %v", err)
+	}
+	return policy, nil
+}
+
+// checkBalancePolicy returns ErrInsufficientFunds when debiting amount from the available balance breaks the policy.
+func checkBalancePolicy(policy db.AccountPolicy, available int64, amount int64) error {
+	if policy.UnlimitedOverdraft {
+		return nil
+	}
+	if available-amount < policy.MinBalance-policy.OverdraftLimit {
+		return ErrInsufficientFunds
+	}
+	return nil
+}
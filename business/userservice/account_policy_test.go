@@ -0,0 +1,37 @@
+package userservice
+
+import (
+	"testing"
+
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckBalancePolicy(t *testing.T) {
+	testCases := []struct {
+		name      string
+		policy    db.AccountPolicy
+		available int64
+		amount    int64
+		ok        bool
+	}{
+		{"default policy allows spending to zero", db.AccountPolicy{}, 100, 100, true},
+		{"default policy rejects going negative", db.AccountPolicy{}, 100, 101, false},
+		{"overdraft limit allows going negative", db.AccountPolicy{OverdraftLimit: 50}, 100, 150, true},
+		{"overdraft limit is a hard floor", db.AccountPolicy{OverdraftLimit: 50}, 100, 151, false},
+		{"minimum balance keeps a reserve", db.AccountPolicy{MinBalance: 20}, 100, 81, false},
+		{"minimum balance and overdraft combine", db.AccountPolicy{MinBalance: 20, OverdraftLimit: 30}, 100, 110, true},
+		{"system accounts may go negative", db.AccountPolicy{UnlimitedOverdraft: true}, 0, 1000000, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkBalancePolicy(tc.policy, tc.available, tc.amount)
+			if tc.ok {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrInsufficientFunds)
+			}
+		})
+	}
+}
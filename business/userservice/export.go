@@ -0,0 +1,375 @@
+package userservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	"github.com/fadedreams/gofinanceflow/business/export"
+	"github.com/fadedreams/gofinanceflow/business/tasks"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// maxSyncExportEntries is the number of account transactions above which an export is generated in the background.
+const maxSyncExportEntries = 10000
+
+// maxExportPeriod limits the date range of a single export.
+const maxExportPeriod = 5 * 366 * 24 * time.Hour
+
+// exportRetention is how long the file of a completed export is kept before cleanup.
+const exportRetention = 7 * 24 * time.Hour
+
+// exportCleanupBatchSize is the number of export files deleted per transaction.
+const exportCleanupBatchSize = 100
+
+// exportClaimTimeout is how long a claimed export is left to its worker before another task may claim it again.
+// It exceeds the timeout of the export task, so a claim only goes stale once its worker died.
+const exportClaimTimeout = time.Hour
+
+// errExportUnrecoverable marks export errors that no retry can fix.
+var errExportUnrecoverable = errors.New("transaction export cannot be generated")
+
+// IsLargeTransactionExport reports whether an export has too many account transactions to be streamed
+// in the request and should be started with StartTransactionExport instead.
+func (us *UserService) IsLargeTransactionExport(ctx context.Context, actor string, admin bool, arg domain.ExportTransactionsParams) (bool, error) {
+	if _, err := us.exportAccount(ctx, actor, admin, arg); err != nil {
+		return false, err
+	}
+
+	count, err := us.store.CountAccountTransactionsInRange(ctx, db.CountAccountTransactionsInRangeParams{
+		AccountID: arg.AccountID,
+		StartAt:   arg.From,
+		EndAt:     arg.To,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to count account transactions: %v", err)
+	}
+	return count > maxSyncExportEntries, nil
+}
+
+// ExportTransactions streams the account transactions of an export to w.
+func (us *UserService) ExportTransactions(ctx context.Context, actor string, admin bool, arg domain.ExportTransactionsParams, w io.Writer) error {
+	account, err := us.exportAccount(ctx, actor, admin, arg)
+	if err != nil {
+		return err
+	}
+	return us.writeTransactionExport(ctx, account, arg, w)
+}
+
+// StartTransactionExport records an export and generates its file in the background.
+func (us *UserService) StartTransactionExport(ctx context.Context, actor string, admin bool, arg domain.ExportTransactionsParams) (db.TransactionExport, error) {
+	account, err := us.exportAccount(ctx, actor, admin, arg)
+	if err != nil {
+		return db.TransactionExport{}, err
+	}
+
+	exp, err := us.store.CreateTransactionExport(ctx, db.CreateTransactionExportParams{
+		Owner:     account.Owner,
+		AccountID: account.ID,
+		Format:    arg.Format,
+		StartAt:   arg.From,
+		EndAt:     arg.To,
+	})
+	if err != nil {
+		return db.TransactionExport{}, fmt.Errorf("failed to create transaction export: %v", err)
+	}
+
+	if err := us.taskManager.EnqueueTransactionExportTask(exp.ID); err != nil {
+		return db.TransactionExport{}, fmt.Errorf("failed to enqueue transaction export: %v", err)
+	}
+	return exp, nil
+}
+
+// GetTransactionExport returns an export on behalf of its owner or an admin.
+func (us *UserService) GetTransactionExport(ctx context.Context, id int64, actor string, admin bool) (db.TransactionExport, error) {
+	exp, err := us.store.GetTransactionExport(ctx, id)
+	if err != nil {
+		return db.TransactionExport{}, fmt.Errorf("transaction export %d not found", id)
+	}
+	if !admin && exp.Owner != actor {
+		return db.TransactionExport{}, fmt.Errorf("transaction export %d not found", id)
+	}
+	return exp, nil
+}
+
+// DownloadTransactionExport copies the file of a completed export to w.
+func (us *UserService) DownloadTransactionExport(ctx context.Context, exp db.TransactionExport, w io.Writer) error {
+	if exp.Status != domain.TransactionExportStatusCompleted {
+		return fmt.Errorf("transaction export %d is %s", exp.ID, exp.Status)
+	}
+	if !exp.FileOid.Valid {
+		return fmt.Errorf("the file of transaction export %d has expired", exp.ID)
+	}
+
+	// Large objects can only be read inside a transaction
+	tx, err := us.connPool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	objects := tx.LargeObjects()
+	file, err := objects.Open(ctx, uint32(exp.FileOid.Int64), pgx.LargeObjectModeRead)
+	if err != nil {
+		return fmt.Errorf("failed to open export file: %v", err)
+	}
+	if _, err := io.Copy(w, file); err != nil {
+		return fmt.Errorf("failed to read export file: %v", err)
+	}
+	return file.Close()
+}
+
+// HandleTransactionExportTask handles the task that generates the file of a transaction export.
+func (us *UserService) HandleTransactionExportTask(ctx context.Context, p tasks.TransactionExportPayload) error {
+	return us.GenerateTransactionExport(ctx, p.ExportID)
+}
+
+// GenerateTransactionExport writes the file of a pending export to a large object.
+// Finished exports are skipped, so the task may safely be delivered more than once. An export another worker
+// is still generating is retried later, and taken over once that worker's claim went stale.
+// Errors that no retry can fix fail the export, any other error returns it to pending for the retried task.
+func (us *UserService) GenerateTransactionExport(ctx context.Context, id int64) error {
+	exp, err := us.store.ClaimTransactionExport(ctx, db.ClaimTransactionExportParams{
+		ID:          id,
+		StaleBefore: time.Now().Add(-exportClaimTimeout),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		current, err := us.store.GetTransactionExport(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get transaction export: %v", err)
+		}
+		if current.Status == domain.TransactionExportStatusProcessing {
+			return fmt.Errorf("transaction export %d is being generated since %s", id, current.ClaimedAt.Time.Format(time.RFC3339))
+		}
+		log.Printf("Skipping transaction export %d: %s", id, current.Status)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to claim transaction export: %v", err)
+	}
+
+	if err := us.writeTransactionExportFile(ctx, exp); err != nil {
+		if !errors.Is(err, errExportUnrecoverable) {
+			_, releaseErr := us.store.ReleaseTransactionExport(ctx, db.ReleaseTransactionExportParams{
+				ID:        exp.ID,
+				ClaimedAt: exp.ClaimedAt,
+			})
+			if releaseErr != nil && !errors.Is(releaseErr, pgx.ErrNoRows) {
+				// The claim goes stale instead and is taken over by a later retry
+				log.Printf("Failed to release transaction export %d: %v", exp.ID, releaseErr)
+			}
+			return fmt.Errorf("transaction export %d failed: %v", exp.ID, err)
+		}
+
+		if _, failErr := us.store.FailTransactionExport(ctx, db.FailTransactionExportParams{
+			ID:            exp.ID,
+			FailureReason: pgtype.Text{String: err.Error(), Valid: true},
+			ClaimedAt:     exp.ClaimedAt,
+		}); failErr != nil {
+			return fmt.Errorf("failed to mark transaction export %d as failed: %v (export error: %v)", exp.ID, failErr, err)
+		}
+		log.Printf("Transaction export %d failed: %v", exp.ID, err)
+		return fmt.Errorf("transaction export %d failed: %v: %w", exp.ID, err, asynq.SkipRetry)
+	}
+	return nil
+}
+
+// writeTransactionExportFile stores the file of an export in a large object and completes the export
+// in the same transaction, so a failed export never leaves an orphaned file behind.
+func (us *UserService) writeTransactionExportFile(ctx context.Context, exp db.TransactionExport) error {
+	if !export.IsValidFormat(exp.Format) {
+		return fmt.Errorf("%w: unknown export format %q", errExportUnrecoverable, exp.Format)
+	}
+	account, err := us.store.GetAccount(ctx, exp.AccountID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: account %d not found", errExportUnrecoverable, exp.AccountID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get account: %v", err)
+	}
+
+	tx, err := us.connPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	objects := tx.LargeObjects()
+	oid, err := objects.Create(ctx, 0)
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %v", err)
+	}
+	file, err := objects.Open(ctx, oid, pgx.LargeObjectModeWrite)
+	if err != nil {
+		return fmt.Errorf("failed to open export file: %v", err)
+	}
+
+	// Rows are streamed over a pool connection because the transaction's connection is busy writing the file
+	counter := &countingWriter{w: file}
+	err = us.writeTransactionExport(ctx, account, domain.ExportTransactionsParams{
+		AccountID: exp.AccountID,
+		Format:    exp.Format,
+		From:      exp.StartAt,
+		To:        exp.EndAt,
+	}, counter)
+	if err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close export file: %v", err)
+	}
+
+	if _, err := db.New(tx).CompleteTransactionExport(ctx, db.CompleteTransactionExportParams{
+		ID:        exp.ID,
+		FileOid:   pgtype.Int8{Int64: int64(oid), Valid: true},
+		FileSize:  pgtype.Int8{Int64: counter.n, Valid: true},
+		ClaimedAt: exp.ClaimedAt,
+	}); err != nil {
+		return fmt.Errorf("failed to complete transaction export: %v", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// HandleTransactionExportCleanupTask is the task handler for tasks.TypeTransactionExportCleanup.
+func (us *UserService) HandleTransactionExportCleanupTask(ctx context.Context, payload []byte) error {
+	deleted, err := us.DeleteExpiredTransactionExportFiles(ctx, time.Now().Add(-exportRetention))
+	if err != nil {
+		return err
+	}
+	log.Printf("Deleted %d expired transaction export files", deleted)
+	return nil
+}
+
+// DeleteExpiredTransactionExportFiles unlinks the large objects of exports completed before completedBefore
+// and clears their file_oid. The exports themselves are kept, but can no longer be downloaded.
+func (us *UserService) DeleteExpiredTransactionExportFiles(ctx context.Context, completedBefore time.Time) (int, error) {
+	var deleted int
+	for {
+		n, err := us.deleteTransactionExportFiles(ctx, completedBefore)
+		if err != nil {
+			return deleted, err
+		}
+		deleted += n
+		if n < exportCleanupBatchSize {
+			return deleted, nil
+		}
+	}
+}
+
+// deleteTransactionExportFiles deletes the files of up to exportCleanupBatchSize expired exports in one transaction,
+// so a large object is only unlinked together with clearing the reference to it.
+func (us *UserService) deleteTransactionExportFiles(ctx context.Context, completedBefore time.Time) (int, error) {
+	tx, err := us.connPool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	q := db.New(tx)
+	exports, err := q.ListExpiredTransactionExports(ctx, db.ListExpiredTransactionExportsParams{
+		CompletedBefore: completedBefore,
+		Limit:           exportCleanupBatchSize,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list expired transaction exports: %v", err)
+	}
+
+	objects := tx.LargeObjects()
+	for _, exp := range exports {
+		if err := objects.Unlink(ctx, uint32(exp.FileOid.Int64)); err != nil {
+			return 0, fmt.Errorf("failed to delete file of transaction export %d: %v", exp.ID, err)
+		}
+		if err := q.ClearTransactionExportFile(ctx, exp.ID); err != nil {
+			return 0, fmt.Errorf("failed to clear file of transaction export %d: %v", exp.ID, err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(exports), nil
+}
+
+// writeTransactionExport writes the header and every account transaction of an export to w.
+func (us *UserService) writeTransactionExport(ctx context.Context, account db.Account, arg domain.ExportTransactionsParams, w io.Writer) error {
+	opening, err := us.store.GetAccountBalanceAt(ctx, db.GetAccountBalanceAtParams{
+		AccountID: account.ID,
+		At:        arg.From,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get opening balance: %v", err)
+	}
+	closing, err := us.store.GetAccountBalanceAt(ctx, db.GetAccountBalanceAtParams{
+		AccountID: account.ID,
+		At:        arg.To,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get closing balance: %v", err)
+	}
+
+	writer, err := export.NewWriter(arg.Format, w)
+	if err != nil {
+		return err
+	}
+	err = writer.WriteHeader(export.Header{
+		Account:        account,
+		From:           arg.From,
+		To:             arg.To,
+		OpeningBalance: opening,
+		ClosingBalance: closing,
+		GeneratedAt:    time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = us.store.StreamAccountTransactionsInRange(ctx, db.ListAccountTransactionsInRangeParams{
+		AccountID: account.ID,
+		StartAt:   arg.From,
+		EndAt:     arg.To,
+	}, writer.WriteEntry)
+	if err != nil {
+		return fmt.Errorf("failed to export account transactions: %v", err)
+	}
+	return writer.Close()
+}
+
+// exportAccount validates an export and returns its account if the actor may export it.
+func (us *UserService) exportAccount(ctx context.Context, actor string, admin bool, arg domain.ExportTransactionsParams) (db.Account, error) {
+	if !export.IsValidFormat(arg.Format) {
+		return db.Account{}, fmt.Errorf("unknown export format %q", arg.Format)
+	}
+	if !arg.From.Before(arg.To) {
+		return db.Account{}, fmt.Errorf("from must be before to")
+	}
+	if arg.To.Sub(arg.From) > maxExportPeriod {
+		return db.Account{}, fmt.Errorf("export period must not exceed five years")
+	}
+
+	account, err := us.store.GetAccount(ctx, arg.AccountID)
+	if err != nil {
+		return db.Account{}, fmt.Errorf("account %d not found", arg.AccountID)
+	}
+	if !admin && account.Owner != actor {
+		return db.Account{}, fmt.Errorf("account %d not found", arg.AccountID)
+	}
+	return account, nil
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
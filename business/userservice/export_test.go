@@ -0,0 +1,112 @@
+package userservice
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	"github.com/fadedreams/gofinanceflow/business/export"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestTransactionExport(t *testing.T, format string) db.TransactionExport {
+	account := createTestAccount(t, 1000)
+	exp, err := testQueries.CreateTransactionExport(context.Background(), db.CreateTransactionExportParams{
+		Owner:     account.Owner,
+		AccountID: account.ID,
+		Format:    format,
+		StartAt:   time.Now().Add(-24 * time.Hour),
+		EndAt:     time.Now(),
+	})
+	require.NoError(t, err)
+	return exp
+}
+
+func TestGenerateTransactionExportTakesOverStaleClaim(t *testing.T) {
+	ctx := context.Background()
+	exp := createTestTransactionExport(t, export.FormatCSV)
+
+	// A worker claimed the export and died
+	_, err := testQueries.ClaimTransactionExport(ctx, db.ClaimTransactionExportParams{ID: exp.ID, StaleBefore: time.Now()})
+	require.NoError(t, err)
+
+	// While the claim is fresh the redelivered task is retried instead of dropped
+	require.Error(t, testService.GenerateTransactionExport(ctx, exp.ID))
+
+	_, err = testService.connPool.Exec(ctx, "UPDATE transaction_exports SET claimed_at = now() - interval '2 hours' WHERE id = $1", exp.ID)
+	require.NoError(t, err)
+	require.NoError(t, testService.GenerateTransactionExport(ctx, exp.ID))
+	completed, err := testQueries.GetTransactionExport(ctx, exp.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.TransactionExportStatusCompleted, completed.Status)
+	require.True(t, completed.FileOid.Valid)
+
+	// A finished export is skipped
+	require.NoError(t, testService.GenerateTransactionExport(ctx, exp.ID))
+}
+
+func TestGenerateTransactionExportFailsUnrecoverableExport(t *testing.T) {
+	ctx := context.Background()
+	exp := createTestTransactionExport(t, "xlsx")
+
+	err := testService.GenerateTransactionExport(ctx, exp.ID)
+	require.ErrorIs(t, err, asynq.SkipRetry)
+	failed, err := testQueries.GetTransactionExport(ctx, exp.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.TransactionExportStatusFailed, failed.Status)
+	require.Contains(t, failed.FailureReason.String, "xlsx")
+}
+
+func TestTransactionExportDatabaseErrorsAreRetried(t *testing.T) {
+	exp := createTestTransactionExport(t, export.FormatCSV)
+	claimed, err := testQueries.ClaimTransactionExport(context.Background(), db.ClaimTransactionExportParams{ID: exp.ID, StaleBefore: time.Now()})
+	require.NoError(t, err)
+
+	// The file cannot be written, which must not fail the export for good
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = testService.writeTransactionExportFile(cancelled, claimed)
+	require.Error(t, err)
+	require.NotErrorIs(t, err, errExportUnrecoverable)
+
+	// Releasing the claim lets the retried task generate the export right away
+	_, err = testQueries.ReleaseTransactionExport(context.Background(), db.ReleaseTransactionExportParams{ID: claimed.ID, ClaimedAt: claimed.ClaimedAt})
+	require.NoError(t, err)
+	require.NoError(t, testService.GenerateTransactionExport(context.Background(), exp.ID))
+}
+
+func TestDeleteExpiredTransactionExportFiles(t *testing.T) {
+	ctx := context.Background()
+	exp := createTestTransactionExport(t, export.FormatCSV)
+	require.NoError(t, testService.GenerateTransactionExport(ctx, exp.ID))
+
+	// A recent export keeps its file
+	_, err := testService.DeleteExpiredTransactionExportFiles(ctx, time.Now().Add(-exportRetention))
+	require.NoError(t, err)
+	completed, err := testQueries.GetTransactionExport(ctx, exp.ID)
+	require.NoError(t, err)
+	require.True(t, completed.FileOid.Valid)
+
+	_, err = testService.connPool.Exec(ctx, "UPDATE transaction_exports SET completed_at = now() - interval '30 days' WHERE id = $1", exp.ID)
+	require.NoError(t, err)
+	deleted, err := testService.DeleteExpiredTransactionExportFiles(ctx, time.Now().Add(-exportRetention))
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, deleted, 1)
+
+	expired, err := testQueries.GetTransactionExport(ctx, exp.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.TransactionExportStatusCompleted, expired.Status)
+	require.False(t, expired.FileOid.Valid)
+
+	var objects int
+	err = testService.connPool.QueryRow(ctx, "SELECT count(*) FROM pg_largeobject_metadata WHERE oid = $1", completed.FileOid.Int64).Scan(&objects)
+	require.NoError(t, err)
+	require.Zero(t, objects)
+
+	var buf bytes.Buffer
+	require.ErrorContains(t, testService.DownloadTransactionExport(ctx, expired, &buf), "expired")
+}
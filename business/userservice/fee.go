@@ -0,0 +1,205 @@
+package userservice
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	"github.com/fadedreams/gofinanceflow/foundation/sdk"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// CreateFeeSchedule adds a fee schedule whose fees are posted to the given fee account.
+func (us *UserService) CreateFeeSchedule(ctx context.Context, arg domain.CreateFeeScheduleParams) (db.FeeSchedule, error) {
+	if arg.Name == "" {
+		return db.FeeSchedule{}, fmt.Errorf("name is required")
+	}
+	if !sdk.IsSupportedCurrency(arg.Currency) {
+		return db.FeeSchedule{}, fmt.Errorf("unsupported currency %q", arg.Currency)
+	}
+	if arg.ProductType != "" && !domain.IsValidAccountProductType(arg.ProductType) {
+		return db.FeeSchedule{}, fmt.Errorf("unknown product type %q", arg.ProductType)
+	}
+	if arg.FlatFee < 0 || arg.PercentageBps < 0 || arg.MinFee < 0 {
+		return db.FeeSchedule{}, fmt.Errorf("fees must not be negative")
+	}
+	if arg.MaxFee != nil && *arg.MaxFee < arg.MinFee {
+		return db.FeeSchedule{}, fmt.Errorf("max_fee must not be below min_fee")
+	}
+
+	feeAccount, err := us.store.GetAccount(ctx, arg.FeeAccountID)
+	if err != nil {
+		return db.FeeSchedule{}, fmt.Errorf("fee account %d not found", arg.FeeAccountID)
+	}
+	if feeAccount.Currency != arg.Currency {
+		return db.FeeSchedule{}, fmt.Errorf("fee account currency %s does not match %s", feeAccount.Currency, arg.Currency)
+	}
+
+	params := db.CreateFeeScheduleParams{
+		Name:          arg.Name,
+		Currency:      arg.Currency,
+		FlatFee:       arg.FlatFee,
+		PercentageBps: arg.PercentageBps,
+		MinFee:        arg.MinFee,
+		MaxFee:        optionalInt8(arg.MaxFee),
+		FeeAccountID:  arg.FeeAccountID,
+	}
+	if arg.ProductType != "" {
+		params.ProductType = pgtype.Text{String: arg.ProductType, Valid: true}
+	}
+	return us.store.CreateFeeSchedule(ctx, params)
+}
+
+// ListFeeSchedules returns all fee schedules, including inactive ones.
+func (us *UserService) ListFeeSchedules(ctx context.Context, arg domain.ListFeeSchedulesParams) ([]db.FeeSchedule, error) {
+	return us.store.ListFeeSchedules(ctx, db.ListFeeSchedulesParams{
+		Limit:  arg.Limit,
+		Offset: arg.Offset,
+	})
+}
+
+// DeactivateFeeSchedule stops a fee schedule from applying to new transfers.
+func (us *UserService) DeactivateFeeSchedule(ctx context.Context, id int64) (db.FeeSchedule, error) {
+	return us.store.DeactivateFeeSchedule(ctx, id)
+}
+
+// QuoteTransfer returns the fees a transfer would be charged without moving any money.
+func (us *UserService) QuoteTransfer(ctx context.Context, arg domain.QuoteTransferParams) (domain.TransferQuote, error) {
+	if arg.Amount <= 0 {
+		return domain.TransferQuote{}, fmt.Errorf("amount must be positive")
+	}
+
+	account, err := us.store.GetAccount(ctx, arg.FromAccountID)
+	if err != nil {
+		return domain.TransferQuote{}, fmt.Errorf("account %d not found", arg.FromAccountID)
+	}
+
+	fees, totalFee, err := transferFees(ctx, us.store, account, arg.Amount)
+	if err != nil {
+		return domain.TransferQuote{}, err
+	}
+	return domain.TransferQuote{
+		Amount:     arg.Amount,
+		Currency:   account.Currency,
+		Fees:       fees,
+		TotalFee:   totalFee,
+		TotalDebit: arg.Amount + totalFee,
+	}, nil
+}
+
+// transferFees evaluates the fee schedules that apply to a transfer of amount from the account.
+func transferFees(ctx context.Context, q *db.Queries, fromAccount db.Account, amount int64) ([]domain.FeeLine, int64, error) {
+	schedules, err := q.ListApplicableFeeSchedules(ctx, db.ListApplicableFeeSchedulesParams{
+		Currency:    fromAccount.Currency,
+		ProductType: pgtype.Text{String: fromAccount.ProductType, Valid: true},
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get fee schedules: %v", err)
+	}
+
+	fees := []domain.FeeLine{}
+	var total int64
+	for _, schedule := range schedules {
+		// Fee accounts are not charged for moving their own money
+		if schedule.FeeAccountID == fromAccount.ID {
+			continue
+		}
+		fee := computeFee(schedule, amount)
+		if fee == 0 {
+			continue
+		}
+		fees = append(fees, domain.FeeLine{
+			FeeScheduleID: schedule.ID,
+			Name:          schedule.Name,
+			FeeAccountID:  schedule.FeeAccountID,
+			Amount:        fee,
+		})
+		total += fee
+	}
+	return fees, total, nil
+}
+
+// postTransferFees debits the fees from the 'from' account and credits each fee account.
+// The fee accounts must already be locked by the caller.
+func postTransferFees(ctx context.Context, q *db.Queries, transfer db.Transfer, fees []domain.FeeLine) error {
+	for _, fee := range fees {
+		if _, err := q.CreateTransferFee(ctx, db.CreateTransferFeeParams{
+			TransferID:    transfer.ID,
+			FeeScheduleID: fee.FeeScheduleID,
+			FeeAccountID:  fee.FeeAccountID,
+			Amount:        fee.Amount,
+		}); err != nil {
+			return fmt.Errorf("failed to record transfer fee: %v", err)
+		}
+
+		if _, err := q.CreateAccountTransactions(ctx, db.CreateAccountTransactionsParams{
+			AccountID: transfer.FromAccountID,
+			Amount:    -fee.Amount,
+		}); err != nil {
+			return fmt.Errorf("failed to create fee account transaction: %v", err)
+		}
+		if _, err := q.CreateAccountTransactions(ctx, db.CreateAccountTransactionsParams{
+			AccountID: fee.FeeAccountID,
+			Amount:    fee.Amount,
+		}); err != nil {
+			return fmt.Errorf("failed to create fee account transaction: %v", err)
+		}
+
+		if _, err := q.AddAccountBalance(ctx, db.AddAccountBalanceParams{ID: transfer.FromAccountID, Amount: -fee.Amount}); err != nil {
+			return fmt.Errorf("failed to charge fee: %v", err)
+		}
+		if _, err := q.AddAccountBalance(ctx, db.AddAccountBalanceParams{ID: fee.FeeAccountID, Amount: fee.Amount}); err != nil {
+			return fmt.Errorf("failed to credit fee account: %v", err)
+		}
+	}
+	return nil
+}
+
+// reverseTransferFees refunds the fees of a transfer from each fee account to the 'from' account.
+// The fee accounts must already be locked by the caller.
+func reverseTransferFees(ctx context.Context, q *db.Queries, transfer db.Transfer, fees []db.TransferFee) error {
+	for _, fee := range fees {
+		feeAccount, err := q.GetAccount(ctx, fee.FeeAccountID)
+		if err != nil {
+			return fmt.Errorf("failed to get fee account: %v", err)
+		}
+		if feeAccount.Status == domain.AccountStatusClosed {
+			return fmt.Errorf("cannot reverse a transfer involving a closed account")
+		}
+
+		if _, err := q.CreateAccountTransactions(ctx, db.CreateAccountTransactionsParams{
+			AccountID: fee.FeeAccountID,
+			Amount:    -fee.Amount,
+		}); err != nil {
+			return fmt.Errorf("failed to create fee refund account transaction: %v", err)
+		}
+		if _, err := q.CreateAccountTransactions(ctx, db.CreateAccountTransactionsParams{
+			AccountID: transfer.FromAccountID,
+			Amount:    fee.Amount,
+		}); err != nil {
+			return fmt.Errorf("failed to create fee refund account transaction: %v", err)
+		}
+
+		if _, err := q.AddAccountBalance(ctx, db.AddAccountBalanceParams{ID: fee.FeeAccountID, Amount: -fee.Amount}); err != nil {
+			return fmt.Errorf("failed to debit fee account: %v", err)
+		}
+		if _, err := q.AddAccountBalance(ctx, db.AddAccountBalanceParams{ID: transfer.FromAccountID, Amount: fee.Amount}); err != nil {
+			return fmt.Errorf("failed to refund fee: %v", err)
+		}
+	}
+	return nil
+}
+
+// computeFee returns the fee a schedule charges on amount: the flat fee plus the
+// percentage, rounded half up and clamped to the schedule's minimum and maximum.
+func computeFee(schedule db.FeeSchedule, amount int64) int64 {
+	percentage := (amount*int64(schedule.PercentageBps) + 5000) / 10000
+	if percentage < schedule.MinFee {
+		percentage = schedule.MinFee
+	}
+	if schedule.MaxFee.Valid && percentage > schedule.MaxFee.Int64 {
+		percentage = schedule.MaxFee.Int64
+	}
+	return schedule.FlatFee + percentage
+}
@@ -0,0 +1,84 @@
+package userservice
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	"github.com/fadedreams/gofinanceflow/foundation/sdk"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeFee(t *testing.T) {
+	testCases := []struct {
+		name     string
+		schedule db.FeeSchedule
+		amount   int64
+		fee      int64
+	}{
+		{"flat", db.FeeSchedule{FlatFee: 25}, 10000, 25},
+		{"percentage", db.FeeSchedule{PercentageBps: 150}, 10000, 150},
+		{"percentage rounds half up", db.FeeSchedule{PercentageBps: 15}, 1000, 2},
+		{"percentage below minimum", db.FeeSchedule{PercentageBps: 100, MinFee: 50}, 1000, 50},
+		{"percentage above maximum", db.FeeSchedule{PercentageBps: 100, MaxFee: pgtype.Int8{Int64: 500, Valid: true}}, 1000000, 500},
+		{"flat plus clamped percentage", db.FeeSchedule{FlatFee: 30, PercentageBps: 200, MinFee: 100}, 1000, 130},
+		{"free", db.FeeSchedule{}, 1000, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.fee, computeFee(tc.schedule, tc.amount))
+		})
+	}
+}
+
+func TestReverseTransferRefundsFees(t *testing.T) {
+	ctx := context.Background()
+	payee := createTestAccount(t, 0)
+	feeAccount := createTestAccount(t, 0)
+	payer, err := testQueries.CreateNamedAccount(ctx, db.CreateNamedAccountParams{
+		Owner:       payee.Owner,
+		Balance:     10000,
+		Currency:    payee.Currency,
+		Nickname:    sdk.RandomString(8),
+		ProductType: domain.AccountProductEnvelope,
+	})
+	require.NoError(t, err)
+
+	schedule, err := testService.CreateFeeSchedule(ctx, domain.CreateFeeScheduleParams{
+		Name:         "envelope flat fee",
+		Currency:     payer.Currency,
+		ProductType:  domain.AccountProductEnvelope,
+		FlatFee:      50,
+		FeeAccountID: feeAccount.ID,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := testService.DeactivateFeeSchedule(ctx, schedule.ID)
+		require.NoError(t, err)
+	})
+
+	transfer, err := testService.HandleFundsTransfer(ctx, domain.HandleFundsTransferParams{
+		FromAccountID: payer.ID,
+		ToAccountID:   payee.ID,
+		Amount:        1000,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(50), transfer.TotalFee)
+	require.Equal(t, int64(8950), transfer.FromAccount.Balance)
+
+	reversed, err := testService.ReverseTransfer(ctx, transfer.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.TransferStatusReversed, reversed.Transfer.Status)
+	require.Equal(t, int64(50), reversed.TotalFee)
+	require.Equal(t, int64(10000), reversed.FromAccount.Balance)
+
+	payeeAccount, err := testQueries.GetAccount(ctx, payee.ID)
+	require.NoError(t, err)
+	require.Zero(t, payeeAccount.Balance)
+	fees, err := testQueries.GetAccount(ctx, feeAccount.ID)
+	require.NoError(t, err)
+	require.Zero(t, fees.Balance)
+}
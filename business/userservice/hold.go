@@ -0,0 +1,199 @@
+package userservice
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+)
+
+// CreateHold reserves funds on an account until they are captured, released or the hold expires.
+func (us *UserService) CreateHold(ctx context.Context, owner string, arg domain.CreateHoldParams) (*db.Hold, error) {
+	if arg.Amount <= 0 {
+		return nil, fmt.Errorf("amount must be positive")
+	}
+	if arg.AccountID == arg.ToAccountID {
+		return nil, fmt.Errorf("from and to accounts must be different")
+	}
+	if !arg.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("expires_at must be in the future")
+	}
+
+	var hold db.Hold
+	err := us.ExecuteInTransaction(ctx, func(q *db.Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return fmt.Errorf("failed to get account: %v", err)
+		}
+		if account.Owner != owner {
+			return fmt.Errorf("account does not belong to %s", owner)
+		}
+		if _, err := q.GetAccount(ctx, arg.ToAccountID); err != nil {
+			return fmt.Errorf("failed to get 'to' account: %v", err)
+		}
+
+		if account.Status != domain.AccountStatusActive {
+			return fmt.Errorf("account is %s", account.Status)
+		}
+
+		policy, err := accountPolicy(ctx, q, account.ID)
+		if err != nil {
+			return err
+		}
+		available, err := availableBalance(ctx, q, account)
+		if err != nil {
+			return err
+		}
+		if err := checkBalancePolicy(policy, available, arg.Amount); err != nil {
+			return fmt.Errorf("%w in account", err)
+		}
+
+		hold, err = q.CreateHold(ctx, db.CreateHoldParams{
+			AccountID:   arg.AccountID,
+			ToAccountID: arg.ToAccountID,
+			Amount:      arg.Amount,
+			ExpiresAt:   arg.ExpiresAt,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// GetHold returns a hold visible to the owner of either of its accounts.
+func (us *UserService) GetHold(ctx context.Context, owner string, id int64) (*db.Hold, error) {
+	hold, err := us.store.GetHold(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("hold %d not found", id)
+	}
+	if err := us.checkHoldAccess(ctx, us.store, owner, hold); err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// CaptureHold moves captured funds of an active hold into a transfer to the hold's 'to' account.
+// A hold may be captured in several parts and becomes captured once nothing remains.
+func (us *UserService) CaptureHold(ctx context.Context, owner string, id int64, arg domain.CaptureHoldParams) (domain.CaptureHoldResult, error) {
+	var result domain.CaptureHoldResult
+
+	err := us.ExecuteInTransaction(ctx, func(q *db.Queries) error {
+		hold, err := q.GetHoldForUpdate(ctx, id)
+		if err != nil {
+			return fmt.Errorf("hold %d not found", id)
+		}
+		if err := us.checkHoldAccess(ctx, q, owner, hold); err != nil {
+			return err
+		}
+		if hold.Status != domain.HoldStatusActive || !hold.ExpiresAt.After(time.Now()) {
+			return fmt.Errorf("cannot capture a %s hold", holdState(hold))
+		}
+
+		remaining := hold.Amount - hold.CapturedAmount
+		amount := arg.Amount
+		if amount == 0 {
+			amount = remaining
+		}
+		if amount < 0 || amount > remaining {
+			return fmt.Errorf("capture amount must be between 1 and %d", remaining)
+		}
+
+		// Reduce the hold first so the captured funds count as available for the transfer
+		result.Hold, err = q.AddHoldCapturedAmount(ctx, db.AddHoldCapturedAmountParams{
+			ID:     hold.ID,
+			Amount: amount,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to capture hold: %v", err)
+		}
+
+		result.Transfer, err = us.transferFunds(ctx, q, domain.HandleFundsTransferParams{
+			FromAccountID: hold.AccountID,
+			ToAccountID:   hold.ToAccountID,
+			Amount:        amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateHoldCapture(ctx, db.CreateHoldCaptureParams{
+			HoldID:     hold.ID,
+			TransferID: result.Transfer.Transfer.ID,
+			Amount:     amount,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record capture: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	observeTransfer(result.Transfer)
+	return result, nil
+}
+
+// ReleaseHold gives the remaining reserved funds of an active hold back to the account.
+func (us *UserService) ReleaseHold(ctx context.Context, owner string, id int64) (*db.Hold, error) {
+	var hold db.Hold
+
+	err := us.ExecuteInTransaction(ctx, func(q *db.Queries) error {
+		current, err := q.GetHoldForUpdate(ctx, id)
+		if err != nil {
+			return fmt.Errorf("hold %d not found", id)
+		}
+		if err := us.checkHoldAccess(ctx, q, owner, current); err != nil {
+			return err
+		}
+		if current.Status != domain.HoldStatusActive {
+			return fmt.Errorf("cannot release a %s hold", current.Status)
+		}
+
+		hold, err = q.UpdateHoldStatus(ctx, db.UpdateHoldStatusParams{
+			ID:     id,
+			Status: domain.HoldStatusReleased,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// HandleExpireHoldsTask is the task handler for tasks.TypeHoldExpiry.
+func (us *UserService) HandleExpireHoldsTask(ctx context.Context, payload []byte) error {
+	expired, err := us.store.ExpireHolds(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to expire holds: %v", err)
+	}
+	if expired > 0 {
+		log.Printf("Expired %d holds", expired)
+	}
+	return nil
+}
+
+// checkHoldAccess allows the owners of both accounts of a hold to act on it.
+func (us *UserService) checkHoldAccess(ctx context.Context, q *db.Queries, owner string, hold db.Hold) error {
+	for _, accountID := range []int64{hold.AccountID, hold.ToAccountID} {
+		account, err := q.GetAccount(ctx, accountID)
+		if err == nil && account.Owner == owner {
+			return nil
+		}
+	}
+	return fmt.Errorf("hold %d not found", hold.ID)
+}
+
+// holdState returns the status of a hold, treating active holds past their expiry as expired.
+func holdState(hold db.Hold) string {
+	if hold.Status == domain.HoldStatusActive && !hold.ExpiresAt.After(time.Now()) {
+		return domain.HoldStatusExpired
+	}
+	return hold.Status
+}
@@ -0,0 +1,116 @@
+package userservice
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	"github.com/fadedreams/gofinanceflow/foundation/sdk"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestAccount(t *testing.T, balance int64) db.Account {
+	hashedPassword, err := sdk.HashPassword(sdk.RandomString(6))
+	require.NoError(t, err)
+
+	user, err := testQueries.CreateUser(context.Background(), db.CreateUserParams{
+		Username:       sdk.RandomOwner(),
+		HashedPassword: hashedPassword,
+		FullName:       sdk.RandomOwner(),
+		Email:          sdk.RandomEmail(),
+	})
+	require.NoError(t, err)
+
+	account, err := testQueries.CreateAccount(context.Background(), db.CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  balance,
+		Currency: sdk.USD,
+	})
+	require.NoError(t, err)
+	return account
+}
+
+func TestHoldReducesAvailableBalance(t *testing.T) {
+	ctx := context.Background()
+	payer := createTestAccount(t, 1000)
+	payee := createTestAccount(t, 0)
+
+	_, err := testService.CreateHold(ctx, payer.Owner, domain.CreateHoldParams{
+		AccountID:   payer.ID,
+		ToAccountID: payee.ID,
+		Amount:      600,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	_, err = testService.HandleFundsTransfer(ctx, domain.HandleFundsTransferParams{
+		FromAccountID: payer.ID,
+		ToAccountID:   payee.ID,
+		Amount:        500,
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "insufficient funds")
+
+	_, err = testService.HandleFundsTransfer(ctx, domain.HandleFundsTransferParams{
+		FromAccountID: payer.ID,
+		ToAccountID:   payee.ID,
+		Amount:        400,
+	})
+	require.NoError(t, err)
+}
+
+func TestConcurrentHoldCaptures(t *testing.T) {
+	ctx := context.Background()
+	payer := createTestAccount(t, 1000)
+	payee := createTestAccount(t, 0)
+
+	hold, err := testService.CreateHold(ctx, payer.Owner, domain.CreateHoldParams{
+		AccountID:   payer.ID,
+		ToAccountID: payee.ID,
+		Amount:      600,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	n := 10
+	errs := make(chan error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := testService.CaptureHold(ctx, payee.Owner, hold.ID, domain.CaptureHoldParams{Amount: 100})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		if err == nil {
+			succeeded++
+		}
+	}
+	require.Equal(t, 6, succeeded)
+
+	captured, err := testQueries.GetHold(ctx, hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.HoldStatusCaptured, captured.Status)
+	require.Equal(t, int64(600), captured.CapturedAmount)
+
+	updatedPayer, err := testQueries.GetAccount(ctx, payer.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(400), updatedPayer.Balance)
+
+	updatedPayee, err := testQueries.GetAccount(ctx, payee.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(600), updatedPayee.Balance)
+
+	captures, err := testQueries.ListHoldCaptures(ctx, hold.ID)
+	require.NoError(t, err)
+	require.Len(t, captures, 6)
+}
@@ -0,0 +1,264 @@
+package userservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// interestBatchSize limits how many accounts a single accrual or posting query returns.
+const interestBatchSize = 500
+
+// interestMaxBackfillDays limits how many days a single accrual run catches up on after missed runs.
+const interestMaxBackfillDays = 31
+
+// ListInterestRates returns the annual interest rates of all account product types.
+func (us *UserService) ListInterestRates(ctx context.Context) ([]db.InterestRate, error) {
+	return us.store.ListInterestRates(ctx)
+}
+
+// UpdateInterestRate sets the annual interest rate of an account product type.
+// The new rate applies from the next accrual on.
+func (us *UserService) UpdateInterestRate(ctx context.Context, productType, updatedBy string, arg domain.UpdateInterestRateParams) (db.InterestRate, error) {
+	if !domain.IsValidAccountProductType(productType) {
+		return db.InterestRate{}, fmt.Errorf("unknown product type %q", productType)
+	}
+	if arg.AnnualRateBps < 0 {
+		return db.InterestRate{}, fmt.Errorf("annual_rate_bps must not be negative")
+	}
+	return us.store.UpsertInterestRate(ctx, db.UpsertInterestRateParams{
+		ProductType:   productType,
+		AnnualRateBps: arg.AnnualRateBps,
+		UpdatedBy:     pgtype.Text{String: updatedBy, Valid: true},
+	})
+}
+
+// HandleInterestAccrualTask accrues the interest of the previous day
+// and of every earlier day that was missed since the last run.
+func (us *UserService) HandleInterestAccrualTask(ctx context.Context, payload []byte) error {
+	var latest *time.Time
+	run, err := us.store.GetLatestInterestAccrualRun(ctx)
+	switch {
+	case err == nil:
+		latest = &run.Time
+	case !errors.Is(err, pgx.ErrNoRows):
+		return fmt.Errorf("failed to get latest interest accrual: %v", err)
+	}
+
+	for _, day := range interestAccrualDays(latest, time.Now()) {
+		if err := us.AccrueInterest(ctx, day); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// interestAccrualDays returns the days from the one after latest up to the day before now,
+// at most interestMaxBackfillDays of them. Without a latest accrual only the day before now is returned.
+func interestAccrualDays(latest *time.Time, now time.Time) []time.Time {
+	last := truncateToDay(now).AddDate(0, 0, -1)
+	first := last
+	if latest != nil {
+		first = truncateToDay(*latest).AddDate(0, 0, 1)
+	}
+	if oldest := last.AddDate(0, 0, 1-interestMaxBackfillDays); first.Before(oldest) {
+		log.Printf("Skipping interest accrual from %s to %s: more than %d days missed",
+			first.Format(time.DateOnly), oldest.AddDate(0, 0, -1).Format(time.DateOnly), interestMaxBackfillDays)
+		first = oldest
+	}
+
+	var days []time.Time
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	return days
+}
+
+// AccrueInterest records one day of interest for every interest-bearing account,
+// on the balance the account had at the end of that day.
+// Accruals are unique per account and day, so running it again for the same day changes nothing.
+func (us *UserService) AccrueInterest(ctx context.Context, day time.Time) error {
+	date := pgtype.Date{Time: truncateToDay(day), Valid: true}
+	endOfDay := date.Time.AddDate(0, 0, 1)
+
+	var afterID int64
+	var accrued int64
+	for {
+		accounts, err := us.store.ListInterestBearingAccounts(ctx, db.ListInterestBearingAccountsParams{
+			CreatedBefore: endOfDay,
+			AfterID:       afterID,
+			Limit:         interestBatchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list interest-bearing accounts: %v", err)
+		}
+
+		for _, account := range accounts {
+			afterID = account.ID
+			balance, err := us.store.GetAccountBalanceAt(ctx, db.GetAccountBalanceAtParams{
+				AccountID: account.ID,
+				At:        endOfDay,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to get balance of account %d: %v", account.ID, err)
+			}
+			amount := dailyInterest(balance, account.AnnualRateBps, date.Time)
+			if amount == 0 {
+				continue
+			}
+			created, err := us.store.CreateInterestAccrual(ctx, db.CreateInterestAccrualParams{
+				AccountID:     account.ID,
+				AccrualDate:   date,
+				Balance:       balance,
+				AnnualRateBps: account.AnnualRateBps,
+				Amount:        amount,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to accrue interest for account %d: %v", account.ID, err)
+			}
+			accrued += created
+		}
+
+		if len(accounts) < interestBatchSize {
+			break
+		}
+	}
+
+	err := us.store.CreateInterestAccrualRun(ctx, db.CreateInterestAccrualRunParams{
+		AccrualDate: date,
+		Accounts:    accrued,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record interest accrual run: %v", err)
+	}
+
+	log.Printf("Accrued interest for %d accounts on %s", accrued, date.Time.Format(time.DateOnly))
+	return nil
+}
+
+// HandleInterestPostingTask posts the interest accrued before the current month.
+func (us *UserService) HandleInterestPostingTask(ctx context.Context, payload []byte) error {
+	now := time.Now().UTC()
+	return us.PostInterest(ctx, time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC))
+}
+
+// PostInterest credits every account with its unposted interest accrued before periodEnd.
+// Only unposted accruals are credited, so running it again never posts interest twice.
+func (us *UserService) PostInterest(ctx context.Context, periodEnd time.Time) error {
+	date := pgtype.Date{Time: truncateToDay(periodEnd), Valid: true}
+
+	var afterID int64
+	for {
+		accountIDs, err := us.store.ListAccountsWithUnpostedInterest(ctx, db.ListAccountsWithUnpostedInterestParams{
+			PeriodEnd: date,
+			AfterID:   afterID,
+			Limit:     interestBatchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list accounts with unposted interest: %v", err)
+		}
+
+		for _, accountID := range accountIDs {
+			afterID = accountID
+			if err := us.postAccountInterest(ctx, accountID, date); err != nil {
+				log.Printf("Failed to post interest for account %d: %v", accountID, err)
+			}
+		}
+
+		if len(accountIDs) < interestBatchSize {
+			return nil
+		}
+	}
+}
+
+// postAccountInterest credits one account with its unposted interest in a single transaction.
+func (us *UserService) postAccountInterest(ctx context.Context, accountID int64, periodEnd pgtype.Date) error {
+	return us.ExecuteInTransaction(ctx, func(q *db.Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, accountID)
+		if err != nil {
+			return fmt.Errorf("failed to get account: %v", err)
+		}
+		// Closed accounts must keep a zero balance, their interest stays unposted
+		if account.Status == domain.AccountStatusClosed {
+			return nil
+		}
+
+		amounts, err := q.MarkInterestAccrualsPosted(ctx, db.MarkInterestAccrualsPostedParams{
+			AccountID: accountID,
+			PeriodEnd: periodEnd,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to mark accruals posted: %v", err)
+		}
+		var total int64
+		for _, amount := range amounts {
+			total += amount
+		}
+		if total == 0 {
+			return nil
+		}
+
+		entry, err := q.CreateAccountTransactions(ctx, db.CreateAccountTransactionsParams{
+			AccountID: accountID,
+			Amount:    total,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create interest account transaction: %v", err)
+		}
+		if _, err := q.AddAccountBalance(ctx, db.AddAccountBalanceParams{ID: accountID, Amount: total}); err != nil {
+			return fmt.Errorf("failed to credit interest: %v", err)
+		}
+
+		_, err = q.CreateInterestPosting(ctx, db.CreateInterestPostingParams{
+			AccountID:            accountID,
+			PeriodEnd:            periodEnd,
+			Amount:               total,
+			AccountTransactionID: entry.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record interest posting: %v", err)
+		}
+		return nil
+	})
+}
+
+// dailyInterest returns one day of interest on balance at the annual rate, using the actual
+// number of days in the year and rounding half to even.
+func dailyInterest(balance int64, annualRateBps int32, day time.Time) int64 {
+	if balance <= 0 || annualRateBps <= 0 {
+		return 0
+	}
+	daysInYear := int64(time.Date(day.Year(), time.December, 31, 0, 0, 0, 0, time.UTC).YearDay())
+
+	numerator := new(big.Int).Mul(big.NewInt(balance), big.NewInt(int64(annualRateBps)))
+	denominator := big.NewInt(10000 * daysInYear)
+	return roundHalfEven(numerator, denominator).Int64()
+}
+
+// roundHalfEven divides two non-negative integers and rounds the quotient half to even.
+func roundHalfEven(numerator, denominator *big.Int) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+	switch new(big.Int).Lsh(remainder, 1).Cmp(denominator) {
+	case 1:
+		quotient.Add(quotient, big.NewInt(1))
+	case 0:
+		if quotient.Bit(0) == 1 {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return quotient
+}
+
+// truncateToDay returns midnight UTC of the day t falls on in UTC.
+func truncateToDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
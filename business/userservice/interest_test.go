@@ -0,0 +1,123 @@
+package userservice
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	"github.com/fadedreams/gofinanceflow/foundation/sdk"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundHalfEven(t *testing.T) {
+	testCases := []struct {
+		numerator   int64
+		denominator int64
+		result      int64
+	}{
+		{5, 2, 2},
+		{7, 2, 4},
+		{3, 2, 2},
+		{1, 2, 0},
+		{10, 4, 2},
+		{11, 4, 3},
+		{9, 4, 2},
+		{0, 7, 0},
+	}
+
+	for _, tc := range testCases {
+		result := roundHalfEven(big.NewInt(tc.numerator), big.NewInt(tc.denominator))
+		require.Equal(t, tc.result, result.Int64(), "%d / %d", tc.numerator, tc.denominator)
+	}
+}
+
+func TestDailyInterest(t *testing.T) {
+	common := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	leap := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	// 1,000,000.00 at 3.65% is 100.00 a day in a 365-day year
+	require.Equal(t, int64(10000), dailyInterest(100000000, 365, common))
+	// the same balance earns less per day in a leap year
+	require.Equal(t, int64(9973), dailyInterest(100000000, 365, leap))
+	// 0.5 minor units rounds to the even 0, 1.5 rounds to 2
+	require.Equal(t, int64(0), dailyInterest(500, 3650, common))
+	require.Equal(t, int64(2), dailyInterest(1500, 3650, common))
+	require.Equal(t, int64(0), dailyInterest(-1000, 200, common))
+	require.Equal(t, int64(0), dailyInterest(1000, 0, common))
+}
+
+func TestInterestAccrualDays(t *testing.T) {
+	now := time.Date(2025, time.March, 10, 1, 30, 0, 0, time.UTC)
+	yesterday := time.Date(2025, time.March, 9, 0, 0, 0, 0, time.UTC)
+
+	// Without earlier runs only yesterday is accrued
+	require.Equal(t, []time.Time{yesterday}, interestAccrualDays(nil, now))
+
+	// Missed days are backfilled
+	latest := time.Date(2025, time.March, 6, 0, 0, 0, 0, time.UTC)
+	require.Equal(t, []time.Time{
+		time.Date(2025, time.March, 7, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, time.March, 8, 0, 0, 0, 0, time.UTC),
+		yesterday,
+	}, interestAccrualDays(&latest, now))
+
+	// Nothing is left once yesterday has been accrued
+	require.Empty(t, interestAccrualDays(&yesterday, now))
+
+	// Long outages are backfilled for at most interestMaxBackfillDays
+	latest = time.Date(2024, time.March, 6, 0, 0, 0, 0, time.UTC)
+	days := interestAccrualDays(&latest, now)
+	require.Len(t, days, interestMaxBackfillDays)
+	require.Equal(t, yesterday, days[len(days)-1])
+}
+
+func TestAccrueInterestOnEndOfDayBalance(t *testing.T) {
+	ctx := context.Background()
+	rate, err := testQueries.GetInterestRate(ctx, domain.AccountProductSavings)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := testQueries.UpsertInterestRate(ctx, db.UpsertInterestRateParams{
+			ProductType:   rate.ProductType,
+			AnnualRateBps: rate.AnnualRateBps,
+			UpdatedBy:     rate.UpdatedBy,
+		})
+		require.NoError(t, err)
+	})
+	_, err = testService.UpdateInterestRate(ctx, domain.AccountProductSavings, "admin", domain.UpdateInterestRateParams{AnnualRateBps: 365})
+	require.NoError(t, err)
+
+	owner := createTestAccount(t, 0)
+	savings, err := testQueries.CreateNamedAccount(ctx, db.CreateNamedAccountParams{
+		Owner:       owner.Owner,
+		Balance:     100000000,
+		Currency:    owner.Currency,
+		Nickname:    sdk.RandomString(8),
+		ProductType: domain.AccountProductSavings,
+	})
+	require.NoError(t, err)
+	_, err = testService.connPool.Exec(ctx, "UPDATE accounts SET created_at = now() - interval '2 days' WHERE id = $1", savings.ID)
+	require.NoError(t, err)
+
+	// Money moved out today does not change the balance yesterday's interest is accrued on
+	_, err = testService.HandleFundsTransfer(ctx, domain.HandleFundsTransferParams{
+		FromAccountID: savings.ID,
+		ToAccountID:   owner.ID,
+		Amount:        50000000,
+	})
+	require.NoError(t, err)
+
+	yesterday := truncateToDay(time.Now()).AddDate(0, 0, -1)
+	require.NoError(t, testService.AccrueInterest(ctx, yesterday))
+	accruals, err := testQueries.ListInterestAccruals(ctx, db.ListInterestAccrualsParams{AccountID: savings.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, accruals, 1)
+	require.Equal(t, int64(100000000), accruals[0].Balance)
+	require.Equal(t, dailyInterest(100000000, 365, yesterday), accruals[0].Amount)
+
+	latest, err := testQueries.GetLatestInterestAccrualRun(ctx)
+	require.NoError(t, err)
+	require.False(t, latest.Time.Before(yesterday))
+}
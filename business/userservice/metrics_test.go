@@ -0,0 +1,38 @@
+package userservice
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	"github.com/fadedreams/gofinanceflow/foundation/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferMetrics(t *testing.T) {
+	ctx := context.Background()
+	payer := createTestAccount(t, 1000)
+	payee := createTestAccount(t, 0)
+
+	completed := testutil.ToFloat64(metrics.TransfersCompleted.WithLabelValues(payer.Currency))
+	amount := testutil.ToFloat64(metrics.TransferAmount.WithLabelValues(payer.Currency))
+	failed := testutil.ToFloat64(metrics.TransfersFailed)
+
+	_, err := testService.HandleFundsTransfer(ctx, domain.HandleFundsTransferParams{
+		FromAccountID: payer.ID,
+		ToAccountID:   payee.ID,
+		Amount:        250,
+	})
+	require.NoError(t, err)
+	_, err = testService.HandleFundsTransfer(ctx, domain.HandleFundsTransferParams{
+		FromAccountID: payer.ID,
+		ToAccountID:   payee.ID,
+		Amount:        5000,
+	})
+	require.Error(t, err)
+
+	require.Equal(t, completed+1, testutil.ToFloat64(metrics.TransfersCompleted.WithLabelValues(payer.Currency)))
+	require.Equal(t, amount+250, testutil.ToFloat64(metrics.TransferAmount.WithLabelValues(payer.Currency)))
+	require.Equal(t, failed+1, testutil.ToFloat64(metrics.TransfersFailed))
+}
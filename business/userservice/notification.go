@@ -0,0 +1,52 @@
+package userservice
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	"github.com/fadedreams/gofinanceflow/business/email"
+	"github.com/fadedreams/gofinanceflow/foundation/sdk"
+)
+
+// HandleUserCreatedEvent welcomes a new user by email.
+func (us *UserService) HandleUserCreatedEvent(ctx context.Context, e domain.UserCreatedEvent) error {
+	if err := us.taskManager.EnqueueEmailDeliveryTask(e.Username, email.TemplateWelcome, nil); err != nil {
+		return fmt.Errorf("failed to enqueue welcome email: %v", err)
+	}
+	return nil
+}
+
+// HandleTransferCompletedEvent emails the sender a receipt of a completed transfer and alerts them
+// if it took the balance of the 'from' account below domain.LowBalanceAlertThreshold.
+func (us *UserService) HandleTransferCompletedEvent(ctx context.Context, e domain.TransferCompletedEvent) error {
+	err := us.taskManager.EnqueueEmailDeliveryTask(e.FromOwner, email.TemplateTransferReceipt, map[string]string{
+		"transfer_id":     strconv.FormatInt(e.TransferID, 10),
+		"from_account_id": strconv.FormatInt(e.FromAccountID, 10),
+		"to_account_id":   strconv.FormatInt(e.ToAccountID, 10),
+		"amount":          sdk.FormatAmount(e.Amount, e.Currency),
+		"fee":             sdk.FormatAmount(e.Fee, e.Currency),
+		"balance":         sdk.FormatAmount(e.FromBalance, e.Currency),
+		"currency":        e.Currency,
+		"description":     e.Description,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enqueue transfer receipt: %v", err)
+	}
+
+	previous := e.FromBalance + e.Amount + e.Fee
+	if previous < domain.LowBalanceAlertThreshold || e.FromBalance >= domain.LowBalanceAlertThreshold {
+		return nil
+	}
+	err = us.taskManager.EnqueueEmailDeliveryTask(e.FromOwner, email.TemplateLowBalance, map[string]string{
+		"account_id": strconv.FormatInt(e.FromAccountID, 10),
+		"balance":    sdk.FormatAmount(e.FromBalance, e.Currency),
+		"threshold":  sdk.FormatAmount(domain.LowBalanceAlertThreshold, e.Currency),
+		"currency":   e.Currency,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enqueue low balance alert: %v", err)
+	}
+	return nil
+}
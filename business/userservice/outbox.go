@@ -0,0 +1,115 @@
+package userservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/fadedreams/gofinanceflow/business/tasks"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// outboxBatchSize is the number of events published per relay transaction.
+const outboxBatchSize = 100
+
+// outboxRetention is how long published events are kept before cleanup.
+const outboxRetention = 7 * 24 * time.Hour
+
+// recordEvent writes a domain event to the outbox using the given transaction-bound queries,
+// so it is published if and only if the transaction commits.
+func recordEvent(ctx context.Context, q *db.Queries, aggregateType string, aggregateID interface{}, eventType string, event interface{}) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %v", eventType, err)
+	}
+	_, err = q.CreateOutboxEvent(ctx, db.CreateOutboxEventParams{
+		AggregateType: aggregateType,
+		AggregateID:   fmt.Sprint(aggregateID),
+		EventType:     eventType,
+		Payload:       payload,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record %s event: %v", eventType, err)
+	}
+	return nil
+}
+
+// RelayOutbox publishes the oldest unpublished event of up to outboxBatchSize aggregates to the task manager
+// and returns how many were published. The task of an event takes the unique key of its aggregate, so the next
+// event of an aggregate is only published once the task of the previous one was handled: events of one aggregate
+// are handled in order, while an archived task holds its aggregate back until it is run or deleted.
+// An event is marked published in the same transaction that locked it, so a crash before commit publishes it
+// again once its first task was handled: delivery is at least once.
+func (us *UserService) RelayOutbox(ctx context.Context) (int, error) {
+	published := 0
+	err := us.ExecuteInTransaction(ctx, func(q *db.Queries) error {
+		events, err := q.ClaimOutboxEvents(ctx, outboxBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to claim outbox events: %v", err)
+		}
+
+		for _, event := range events {
+			key := fmt.Sprintf("outbox:%s:%s", event.AggregateType, event.AggregateID)
+			err := us.taskManager.Enqueue(event.EventType, event.Payload, tasks.UniqueKey(key))
+			if err != nil {
+				// The event stays at the head of its aggregate and is retried by the next relay run
+				if errors.Is(err, tasks.ErrDuplicateTask) {
+					err = fmt.Errorf("waiting for the previous event of %s %s", event.AggregateType, event.AggregateID)
+				} else {
+					log.Printf("Failed to publish outbox event %d: %v", event.ID, err)
+				}
+				if err := q.FailOutboxEvent(ctx, db.FailOutboxEventParams{
+					ID:        event.ID,
+					LastError: pgtype.Text{String: err.Error(), Valid: true},
+				}); err != nil {
+					return fmt.Errorf("failed to record outbox event failure: %v", err)
+				}
+				continue
+			}
+			if err := q.MarkOutboxEventPublished(ctx, event.ID); err != nil {
+				return fmt.Errorf("failed to mark outbox event %d as published: %v", event.ID, err)
+			}
+			published++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return published, nil
+}
+
+// RunOutboxRelay publishes outbox events until ctx is cancelled, polling every interval once the outbox is drained.
+func (us *UserService) RunOutboxRelay(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		n, err := us.RelayOutbox(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Outbox relay failed: %v", err)
+		}
+		if n > 0 && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// HandleOutboxCleanupTask is the task handler for tasks.TypeOutboxCleanup.
+func (us *UserService) HandleOutboxCleanupTask(ctx context.Context, payload []byte) error {
+	deleted, err := us.store.DeletePublishedOutboxEvents(ctx, time.Now().Add(-outboxRetention))
+	if err != nil {
+		return fmt.Errorf("failed to delete published outbox events: %v", err)
+	}
+	log.Printf("Deleted %d published outbox events", deleted)
+	return nil
+}
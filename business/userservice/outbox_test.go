@@ -0,0 +1,100 @@
+package userservice
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	"github.com/fadedreams/gofinanceflow/business/email"
+	"github.com/fadedreams/gofinanceflow/business/tasks"
+	"github.com/fadedreams/gofinanceflow/business/tasks/taskstest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayOutboxPublishesCommittedEvents(t *testing.T) {
+	ctx := context.Background()
+	tm := taskstest.New(t)
+	service := NewUserService(testService.connPool, testQueries, tm)
+	payer := createTestAccount(t, 1000)
+	payee := createTestAccount(t, 0)
+
+	for i := 0; i < 2; i++ {
+		_, err := service.HandleFundsTransfer(ctx, domain.HandleFundsTransferParams{
+			FromAccountID: payer.ID,
+			ToAccountID:   payee.ID,
+			Amount:        100,
+		})
+		require.NoError(t, err)
+	}
+	// A rolled back transfer records no event
+	_, err := service.HandleFundsTransfer(ctx, domain.HandleFundsTransferParams{
+		FromAccountID: payer.ID,
+		ToAccountID:   payee.ID,
+		Amount:        5000,
+	})
+	require.Error(t, err)
+
+	// The second event of the account waits until the task of the first one was handled
+	tm.On(domain.EventTransferCompleted, func(ctx context.Context, payload []byte) error { return nil })
+	relay := func() []int64 {
+		for {
+			n, err := service.RelayOutbox(ctx)
+			require.NoError(t, err)
+			if n == 0 {
+				break
+			}
+		}
+		var balances []int64
+		for _, task := range tm.Enqueued(domain.EventTransferCompleted) {
+			event := taskstest.RequirePayload[domain.TransferCompletedEvent](t, task)
+			if event.FromAccountID == payer.ID {
+				balances = append(balances, event.FromBalance)
+			}
+		}
+		return balances
+	}
+	require.Equal(t, []int64{900}, relay())
+	require.Equal(t, []int64{900}, relay())
+	taskstest.RequireDrain(t, tm)
+	require.Equal(t, []int64{900, 800}, relay())
+
+	// Published events are not published again
+	taskstest.RequireDrain(t, tm)
+	require.Equal(t, []int64{900, 800}, relay())
+}
+
+func TestHandleTransferCompletedEvent(t *testing.T) {
+	ctx := context.Background()
+	tm := taskstest.New(t)
+	service := NewUserService(nil, nil, tm)
+
+	event := domain.TransferCompletedEvent{
+		TransferID:    1,
+		FromAccountID: 2,
+		ToAccountID:   3,
+		FromOwner:     "alice",
+		Amount:        5000,
+		Fee:           100,
+		Currency:      "USD",
+		FromBalance:   domain.LowBalanceAlertThreshold + 1,
+	}
+	require.NoError(t, service.HandleTransferCompletedEvent(ctx, event))
+	enqueued := taskstest.RequireEnqueued(t, tm, tasks.TypeEmailDelivery, 1)
+	receipt := taskstest.RequirePayload[tasks.EmailDeliveryPayload](t, enqueued[0])
+	require.Equal(t, "alice", receipt.Username)
+	require.Equal(t, email.TemplateTransferReceipt, receipt.TemplateID)
+	require.Equal(t, "50.00", receipt.Data["amount"])
+
+	// Crossing the threshold also alerts the owner
+	event.FromBalance = domain.LowBalanceAlertThreshold - 1
+	require.NoError(t, service.HandleTransferCompletedEvent(ctx, event))
+	enqueued = taskstest.RequireEnqueued(t, tm, tasks.TypeEmailDelivery, 3)
+	alert := taskstest.RequirePayload[tasks.EmailDeliveryPayload](t, enqueued[2])
+	require.Equal(t, email.TemplateLowBalance, alert.TemplateID)
+
+	// Staying below the threshold does not alert again
+	event.FromBalance = 10
+	event.Amount = 10
+	require.NoError(t, service.HandleTransferCompletedEvent(ctx, event))
+	taskstest.RequireEnqueued(t, tm, tasks.TypeEmailDelivery, 4)
+}
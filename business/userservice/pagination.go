@@ -0,0 +1,302 @@
+package userservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	"github.com/fadedreams/gofinanceflow/foundation/sdk"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// maxPageSize limits the number of rows of one page.
+const maxPageSize = 100
+
+// ErrInvalidPage is returned for page sizes out of range and for page tokens that cannot be used.
+var ErrInvalidPage = errors.New("invalid page")
+
+// ListTransfersPage lists the transfers of an account in creation order, one page at a time,
+// on behalf of its owner or an admin.
+func (us *UserService) ListTransfersPage(ctx context.Context, actor string, admin bool, arg domain.ListTransfersParams) (domain.TransferPage, error) {
+	account, err := us.store.GetAccount(ctx, arg.AccountID)
+	if err != nil || (!admin && account.Owner != actor) {
+		return domain.TransferPage{}, fmt.Errorf("account %d not found", arg.AccountID)
+	}
+
+	scope := fmt.Sprintf("transfers:%d", arg.AccountID)
+	after, err := pageKey[sdk.Cursor](scope, arg.PageToken, arg.Limit)
+	if err != nil {
+		return domain.TransferPage{}, err
+	}
+
+	params := db.ListTransfersAfterParams{
+		AccountID:      arg.AccountID,
+		AfterCreatedAt: after.Time,
+		AfterID:        after.ID,
+		Limit:          arg.Limit + 1,
+	}
+	if arg.Status != "" {
+		params.Status = pgtype.Text{String: arg.Status, Valid: true}
+	}
+	transfers, err := us.store.ListTransfersAfter(ctx, params)
+	if err != nil {
+		return domain.TransferPage{}, err
+	}
+
+	var page domain.TransferPage
+	page.Transfers, page.NextPageToken = paginate(scope, transfers, arg.Limit, func(t db.Transfer) sdk.Cursor {
+		return sdk.Cursor{Time: t.CreatedAt, ID: t.ID}
+	})
+	return page, nil
+}
+
+// ListAccountsPage lists the accounts of an owner in creation order, one page at a time.
+func (us *UserService) ListAccountsPage(ctx context.Context, owner string, arg domain.ListAccountsParams) (domain.AccountPage, error) {
+	scope := "accounts:" + owner
+	after, err := pageKey[sdk.Cursor](scope, arg.PageToken, arg.Limit)
+	if err != nil {
+		return domain.AccountPage{}, err
+	}
+
+	params := db.ListAccountsAfterParams{
+		Owner:          owner,
+		AfterCreatedAt: after.Time,
+		AfterID:        after.ID,
+		Limit:          arg.Limit + 1,
+	}
+	if arg.Currency != "" {
+		params.Currency = pgtype.Text{String: arg.Currency, Valid: true}
+	}
+	if arg.ProductType != "" {
+		params.ProductType = pgtype.Text{String: arg.ProductType, Valid: true}
+	}
+	if arg.Status != "" {
+		params.Status = pgtype.Text{String: arg.Status, Valid: true}
+	}
+	accounts, err := us.store.ListAccountsAfter(ctx, params)
+	if err != nil {
+		return domain.AccountPage{}, err
+	}
+
+	var page domain.AccountPage
+	page.Accounts, page.NextPageToken = paginate(scope, accounts, arg.Limit, func(a db.Account) sdk.Cursor {
+		return sdk.Cursor{Time: a.CreatedAt, ID: a.ID}
+	})
+	return page, nil
+}
+
+// ListAccountTransactionsPage lists the transactions of an account in creation order, one page at a time,
+// on behalf of its owner or an admin.
+func (us *UserService) ListAccountTransactionsPage(ctx context.Context, accountID int64, actor string, admin bool, arg domain.ListAccountTransactionsParams) (domain.AccountTransactionPage, error) {
+	account, err := us.store.GetAccount(ctx, accountID)
+	if err != nil || (!admin && account.Owner != actor) {
+		return domain.AccountTransactionPage{}, fmt.Errorf("account %d not found", accountID)
+	}
+
+	scope := fmt.Sprintf("account_transactions:%d", accountID)
+	after, err := pageKey[sdk.Cursor](scope, arg.PageToken, arg.Limit)
+	if err != nil {
+		return domain.AccountTransactionPage{}, err
+	}
+
+	entries, err := us.store.ListAccountTransactionsAfter(ctx, db.ListAccountTransactionsAfterParams{
+		AccountID:      accountID,
+		AfterCreatedAt: after.Time,
+		AfterID:        after.ID,
+		Limit:          arg.Limit + 1,
+	})
+	if err != nil {
+		return domain.AccountTransactionPage{}, err
+	}
+
+	var page domain.AccountTransactionPage
+	page.Transactions, page.NextPageToken = paginate(scope, entries, arg.Limit, func(e db.AccountTransaction) sdk.Cursor {
+		return sdk.Cursor{Time: e.CreatedAt, ID: e.ID}
+	})
+	return page, nil
+}
+
+// ListScheduledTransfersPage lists the scheduled transfers of an owner in execution order, one page at a time.
+func (us *UserService) ListScheduledTransfersPage(ctx context.Context, owner string, arg domain.ListScheduledTransfersParams) (domain.ScheduledTransferPage, error) {
+	scope := "scheduled_transfers:" + owner
+	after, err := pageKey[sdk.Cursor](scope, arg.PageToken, arg.Limit)
+	if err != nil {
+		return domain.ScheduledTransferPage{}, err
+	}
+
+	scheduled, err := us.store.ListScheduledTransfersAfter(ctx, db.ListScheduledTransfersAfterParams{
+		Owner:          owner,
+		AfterExecuteAt: after.Time,
+		AfterID:        after.ID,
+		Limit:          arg.Limit + 1,
+	})
+	if err != nil {
+		return domain.ScheduledTransferPage{}, err
+	}
+
+	var page domain.ScheduledTransferPage
+	page.ScheduledTransfers, page.NextPageToken = paginate(scope, scheduled, arg.Limit, func(st db.ScheduledTransfer) sdk.Cursor {
+		return sdk.Cursor{Time: st.ExecuteAt, ID: st.ID}
+	})
+	return page, nil
+}
+
+// ListRecurringTransfersPage lists the recurring transfers of an owner in creation order, one page at a time.
+func (us *UserService) ListRecurringTransfersPage(ctx context.Context, owner string, arg domain.ListRecurringTransfersParams) (domain.RecurringTransferPage, error) {
+	scope := "recurring_transfers:" + owner
+	after, err := pageKey[int64](scope, arg.PageToken, arg.Limit)
+	if err != nil {
+		return domain.RecurringTransferPage{}, err
+	}
+
+	recurring, err := us.store.ListRecurringTransfersAfter(ctx, db.ListRecurringTransfersAfterParams{
+		Owner:   owner,
+		AfterID: after,
+		Limit:   arg.Limit + 1,
+	})
+	if err != nil {
+		return domain.RecurringTransferPage{}, err
+	}
+
+	var page domain.RecurringTransferPage
+	page.RecurringTransfers, page.NextPageToken = paginate(scope, recurring, arg.Limit, func(rt db.RecurringTransfer) int64 {
+		return rt.ID
+	})
+	return page, nil
+}
+
+// ListTransferBatchesPage lists the transfer batches of an owner, newest first, one page at a time.
+func (us *UserService) ListTransferBatchesPage(ctx context.Context, owner string, arg domain.ListTransferBatchesParams) (domain.TransferBatchPage, error) {
+	scope := "transfer_batches:" + owner
+	after, err := pageKey[int64](scope, arg.PageToken, arg.Limit)
+	if err != nil {
+		return domain.TransferBatchPage{}, err
+	}
+
+	batches, err := us.store.ListTransferBatchesAfter(ctx, db.ListTransferBatchesAfterParams{
+		Owner:   owner,
+		AfterID: after,
+		Limit:   arg.Limit + 1,
+	})
+	if err != nil {
+		return domain.TransferBatchPage{}, err
+	}
+
+	var page domain.TransferBatchPage
+	page.TransferBatches, page.NextPageToken = paginate(scope, batches, arg.Limit, func(b db.TransferBatch) int64 {
+		return b.ID
+	})
+	return page, nil
+}
+
+// ListAccountAuditLogsPage lists the audit logs of an account, newest first, one page at a time,
+// on behalf of its owner or an admin.
+func (us *UserService) ListAccountAuditLogsPage(ctx context.Context, accountID int64, actor string, admin bool, arg domain.ListAccountAuditLogsParams) (domain.AccountAuditLogPage, error) {
+	account, err := us.store.GetAccount(ctx, accountID)
+	if err != nil || (!admin && account.Owner != actor) {
+		return domain.AccountAuditLogPage{}, fmt.Errorf("account %d not found", accountID)
+	}
+
+	scope := fmt.Sprintf("account_audit_logs:%d", accountID)
+	after, err := pageKey[int64](scope, arg.PageToken, arg.Limit)
+	if err != nil {
+		return domain.AccountAuditLogPage{}, err
+	}
+
+	logs, err := us.store.ListAccountAuditLogsAfter(ctx, db.ListAccountAuditLogsAfterParams{
+		AccountID: accountID,
+		AfterID:   after,
+		Limit:     arg.Limit + 1,
+	})
+	if err != nil {
+		return domain.AccountAuditLogPage{}, err
+	}
+
+	var page domain.AccountAuditLogPage
+	page.AuditLogs, page.NextPageToken = paginate(scope, logs, arg.Limit, func(l db.AccountAuditLog) int64 {
+		return l.ID
+	})
+	return page, nil
+}
+
+// ListFeeSchedulesPage lists the fee schedules in creation order, one page at a time.
+func (us *UserService) ListFeeSchedulesPage(ctx context.Context, arg domain.ListFeeSchedulesParams) (domain.FeeSchedulePage, error) {
+	const scope = "fee_schedules"
+	after, err := pageKey[int64](scope, arg.PageToken, arg.Limit)
+	if err != nil {
+		return domain.FeeSchedulePage{}, err
+	}
+
+	schedules, err := us.store.ListFeeSchedulesAfter(ctx, db.ListFeeSchedulesAfterParams{
+		AfterID: after,
+		Limit:   arg.Limit + 1,
+	})
+	if err != nil {
+		return domain.FeeSchedulePage{}, err
+	}
+
+	var page domain.FeeSchedulePage
+	page.FeeSchedules, page.NextPageToken = paginate(scope, schedules, arg.Limit, func(fs db.FeeSchedule) int64 {
+		return fs.ID
+	})
+	return page, nil
+}
+
+// ListWebhookDeliveriesPage lists the deliveries of a webhook subscription, newest first, one page at a time,
+// on behalf of its owner or an admin.
+func (us *UserService) ListWebhookDeliveriesPage(ctx context.Context, id int64, actor string, admin bool, arg domain.ListWebhookDeliveriesParams) (domain.WebhookDeliveryPage, error) {
+	if _, err := us.webhookSubscription(ctx, id, actor, admin); err != nil {
+		return domain.WebhookDeliveryPage{}, err
+	}
+
+	scope := fmt.Sprintf("webhook_deliveries:%d", id)
+	after, err := pageKey[int64](scope, arg.PageToken, arg.Limit)
+	if err != nil {
+		return domain.WebhookDeliveryPage{}, err
+	}
+
+	deliveries, err := us.store.ListWebhookDeliveriesAfter(ctx, db.ListWebhookDeliveriesAfterParams{
+		SubscriptionID: id,
+		AfterID:        after,
+		Limit:          arg.Limit + 1,
+	})
+	if err != nil {
+		return domain.WebhookDeliveryPage{}, fmt.Errorf("failed to list webhook deliveries: %v", err)
+	}
+
+	var page domain.WebhookDeliveryPage
+	page.Deliveries, page.NextPageToken = paginate(scope, deliveries, arg.Limit, func(d db.WebhookDelivery) int64 {
+		return d.ID
+	})
+	return page, nil
+}
+
+// pageKey validates the page size and returns the sort key a page starts after.
+// An empty token starts at the first row and returns the zero key.
+func pageKey[K any](scope string, token string, limit int32) (K, error) {
+	var key K
+	if limit < 1 || limit > maxPageSize {
+		return key, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidPage, maxPageSize)
+	}
+	if token == "" {
+		return key, nil
+	}
+	if err := sdk.DecodePageToken(scope, token, &key); err != nil {
+		return key, fmt.Errorf("%w: %v", ErrInvalidPage, err)
+	}
+	return key, nil
+}
+
+// paginate trims the extra row fetched beyond limit and returns the token of the next page,
+// which is empty when there is no extra row and so no next page.
+func paginate[T, K any](scope string, rows []T, limit int32, key func(T) K) ([]T, string) {
+	if len(rows) <= int(limit) {
+		return rows, ""
+	}
+	rows = rows[:limit]
+	// Sort keys are plain structs, so encoding cannot fail
+	token, _ := sdk.EncodePageToken(scope, key(rows[len(rows)-1]))
+	return rows, token
+}
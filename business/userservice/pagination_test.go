@@ -0,0 +1,107 @@
+package userservice
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	"github.com/fadedreams/gofinanceflow/foundation/sdk"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginate(t *testing.T) {
+	start := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	key := func(e db.AccountTransaction) sdk.Cursor {
+		return sdk.Cursor{Time: e.CreatedAt, ID: e.ID}
+	}
+	entries := []db.AccountTransaction{
+		{ID: 1, CreatedAt: start},
+		{ID: 2, CreatedAt: start.Add(time.Second)},
+		{ID: 3, CreatedAt: start.Add(2 * time.Second)},
+	}
+
+	page, token := paginate("scope", entries, 3, key)
+	require.Len(t, page, 3)
+	require.Empty(t, token)
+
+	page, token = paginate("scope", entries, 2, key)
+	require.Len(t, page, 2)
+	after, err := pageKey[sdk.Cursor]("scope", token, 2)
+	require.NoError(t, err)
+	require.Equal(t, key(entries[1]), after)
+}
+
+func TestPageKey(t *testing.T) {
+	after, err := pageKey[sdk.Cursor]("scope", "", 10)
+	require.NoError(t, err)
+	require.Equal(t, sdk.Cursor{}, after)
+
+	_, err = pageKey[sdk.Cursor]("scope", "", 0)
+	require.ErrorIs(t, err, ErrInvalidPage)
+	_, err = pageKey[sdk.Cursor]("scope", "", maxPageSize+1)
+	require.ErrorIs(t, err, ErrInvalidPage)
+
+	token := sdk.EncodeCursor("other", sdk.Cursor{ID: 1})
+	_, err = pageKey[sdk.Cursor]("scope", token, 10)
+	require.ErrorIs(t, err, ErrInvalidPage)
+}
+
+func TestListRecurringTransfersPage(t *testing.T) {
+	ctx := context.Background()
+	from := createTestAccount(t, 1000)
+	to := createTestAccount(t, 0)
+	var ids []int64
+	for range 3 {
+		recurring, err := testService.CreateRecurringTransfer(ctx, from.Owner, domain.CreateRecurringTransferParams{
+			FromAccountID: from.ID,
+			ToAccountID:   to.ID,
+			Amount:        10,
+			Schedule:      "0 9 * * *",
+		})
+		require.NoError(t, err)
+		ids = append(ids, recurring.ID)
+	}
+
+	first, err := testService.ListRecurringTransfersPage(ctx, from.Owner, domain.ListRecurringTransfersParams{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, first.RecurringTransfers, 2)
+	require.Equal(t, ids[0], first.RecurringTransfers[0].ID)
+	require.NotEmpty(t, first.NextPageToken)
+
+	last, err := testService.ListRecurringTransfersPage(ctx, from.Owner, domain.ListRecurringTransfersParams{Limit: 2, PageToken: first.NextPageToken})
+	require.NoError(t, err)
+	require.Len(t, last.RecurringTransfers, 1)
+	require.Equal(t, ids[2], last.RecurringTransfers[0].ID)
+	require.Empty(t, last.NextPageToken)
+
+	// A token of another owner's list is rejected
+	_, err = testService.ListRecurringTransfersPage(ctx, to.Owner, domain.ListRecurringTransfersParams{Limit: 2, PageToken: first.NextPageToken})
+	require.ErrorIs(t, err, ErrInvalidPage)
+}
+
+func TestListAccountAuditLogsPage(t *testing.T) {
+	ctx := context.Background()
+	account := createTestAccount(t, 0)
+	for _, action := range []string{domain.AccountActionFreeze, domain.AccountActionUnfreeze, domain.AccountActionFreeze} {
+		_, err := testService.ChangeAccountStatus(ctx, account.ID, "admin", true, action, domain.ChangeAccountStatusParams{Reason: "test"})
+		require.NoError(t, err)
+	}
+
+	// Audit logs are listed newest first
+	first, err := testService.ListAccountAuditLogsPage(ctx, account.ID, account.Owner, false, domain.ListAccountAuditLogsParams{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, first.AuditLogs, 2)
+	require.Greater(t, first.AuditLogs[0].ID, first.AuditLogs[1].ID)
+	require.NotEmpty(t, first.NextPageToken)
+
+	last, err := testService.ListAccountAuditLogsPage(ctx, account.ID, account.Owner, false, domain.ListAccountAuditLogsParams{Limit: 2, PageToken: first.NextPageToken})
+	require.NoError(t, err)
+	require.Len(t, last.AuditLogs, 1)
+	require.Less(t, last.AuditLogs[0].ID, first.AuditLogs[1].ID)
+	require.Empty(t, last.NextPageToken)
+
+	_, err = testService.ListAccountAuditLogsPage(ctx, account.ID, "someone-else", false, domain.ListAccountAuditLogsParams{Limit: 2})
+	require.Error(t, err)
+}
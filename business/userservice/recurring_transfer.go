@@ -0,0 +1,319 @@
+package userservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	"github.com/fadedreams/gofinanceflow/business/tasks"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/robfig/cron/v3"
+)
+
+// recurringDispatchBatchSize limits how many recurring transfers a single dispatch run handles.
+const recurringDispatchBatchSize = 100
+
+// CreateRecurringTransfer stores a standing order for the given owner.
+func (us *UserService) CreateRecurringTransfer(ctx context.Context, owner string, arg domain.CreateRecurringTransferParams) (*db.RecurringTransfer, error) {
+	if arg.Amount <= 0 {
+		return nil, fmt.Errorf("amount must be positive")
+	}
+	if arg.FromAccountID == arg.ToAccountID {
+		return nil, fmt.Errorf("from and to accounts must be different")
+	}
+	if arg.MaxOccurrences != nil && *arg.MaxOccurrences <= 0 {
+		return nil, fmt.Errorf("max_occurrences must be positive")
+	}
+	schedule, err := cron.ParseStandard(arg.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule: %v", err)
+	}
+
+	// A start in the past would make the dispatcher pay every occurrence that was missed
+	startAt := time.Now().UTC()
+	if arg.StartAt != nil {
+		if arg.StartAt.Before(startAt) {
+			return nil, fmt.Errorf("start_at must not be in the past")
+		}
+		startAt = arg.StartAt.UTC()
+	}
+	// Next returns the first activation strictly after its argument, so step back to include startAt itself.
+	firstRun := schedule.Next(startAt.Add(-time.Nanosecond))
+	if arg.EndAt != nil && firstRun.After(*arg.EndAt) {
+		return nil, fmt.Errorf("schedule has no occurrence before end_at")
+	}
+
+	fromAccount, err := us.store.GetAccount(ctx, arg.FromAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get 'from' account: %v", err)
+	}
+	if fromAccount.Owner != owner {
+		return nil, fmt.Errorf("'from' account does not belong to %s", owner)
+	}
+	if _, err := us.store.GetAccount(ctx, arg.ToAccountID); err != nil {
+		return nil, fmt.Errorf("failed to get 'to' account: %v", err)
+	}
+
+	params := db.CreateRecurringTransferParams{
+		Owner:         owner,
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		Schedule:      arg.Schedule,
+		StartAt:       startAt,
+		NextRunAt:     pgtype.Timestamptz{Time: firstRun, Valid: true},
+	}
+	if arg.EndAt != nil {
+		params.EndAt = pgtype.Timestamptz{Time: *arg.EndAt, Valid: true}
+	}
+	if arg.MaxOccurrences != nil {
+		params.MaxOccurrences = pgtype.Int4{Int32: *arg.MaxOccurrences, Valid: true}
+	}
+
+	recurring, err := us.store.CreateRecurringTransfer(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &recurring, nil
+}
+
+func (us *UserService) ListRecurringTransfers(ctx context.Context, owner string, arg domain.ListRecurringTransfersParams) ([]db.RecurringTransfer, error) {
+	return us.store.ListRecurringTransfers(ctx, db.ListRecurringTransfersParams{
+		Owner:  owner,
+		Limit:  arg.Limit,
+		Offset: arg.Offset,
+	})
+}
+
+// PauseRecurringTransfer stops creating occurrences until the recurring transfer is resumed.
+func (us *UserService) PauseRecurringTransfer(ctx context.Context, owner string, id int64) (*db.RecurringTransfer, error) {
+	return us.changeRecurringTransferStatus(ctx, owner, id, domain.RecurringTransferStatusPaused)
+}
+
+// ResumeRecurringTransfer reactivates a paused recurring transfer.
+// Occurrences missed while paused are skipped.
+func (us *UserService) ResumeRecurringTransfer(ctx context.Context, owner string, id int64) (*db.RecurringTransfer, error) {
+	return us.changeRecurringTransferStatus(ctx, owner, id, domain.RecurringTransferStatusActive)
+}
+
+// DeleteRecurringTransfer stops a recurring transfer for good while keeping its occurrence history.
+func (us *UserService) DeleteRecurringTransfer(ctx context.Context, owner string, id int64) (*db.RecurringTransfer, error) {
+	return us.changeRecurringTransferStatus(ctx, owner, id, domain.RecurringTransferStatusDeleted)
+}
+
+func (us *UserService) changeRecurringTransferStatus(ctx context.Context, owner string, id int64, status string) (*db.RecurringTransfer, error) {
+	var recurring db.RecurringTransfer
+
+	err := us.ExecuteInTransaction(ctx, func(q *db.Queries) error {
+		current, err := q.GetRecurringTransferForUpdate(ctx, id)
+		if err != nil || current.Owner != owner {
+			return fmt.Errorf("recurring transfer %d not found", id)
+		}
+
+		params := db.UpdateRecurringTransferStatusParams{
+			ID:     id,
+			Status: status,
+		}
+		switch status {
+		case domain.RecurringTransferStatusPaused:
+			if current.Status != domain.RecurringTransferStatusActive {
+				return fmt.Errorf("cannot pause a %s recurring transfer", current.Status)
+			}
+			params.NextRunAt = current.NextRunAt
+		case domain.RecurringTransferStatusActive:
+			if current.Status != domain.RecurringTransferStatusPaused {
+				return fmt.Errorf("cannot resume a %s recurring transfer", current.Status)
+			}
+			next, ok, err := nextRecurringRun(current, time.Now().UTC(), current.OccurrenceCount)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				params.Status = domain.RecurringTransferStatusCompleted
+			} else {
+				params.NextRunAt = pgtype.Timestamptz{Time: next, Valid: true}
+			}
+		case domain.RecurringTransferStatusDeleted:
+			if current.Status == domain.RecurringTransferStatusDeleted {
+				return fmt.Errorf("recurring transfer %d not found", id)
+			}
+		}
+
+		recurring, err = q.UpdateRecurringTransferStatus(ctx, params)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &recurring, nil
+}
+
+// nextRecurringRun returns the first activation of the recurring transfer after the given time,
+// or false when the end date or the maximum number of occurrences has been reached.
+func nextRecurringRun(recurring db.RecurringTransfer, after time.Time, occurrences int32) (time.Time, bool, error) {
+	if recurring.MaxOccurrences.Valid && occurrences >= recurring.MaxOccurrences.Int32 {
+		return time.Time{}, false, nil
+	}
+	schedule, err := cron.ParseStandard(recurring.Schedule)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid schedule: %v", err)
+	}
+	next := schedule.Next(after.UTC())
+	if recurring.EndAt.Valid && next.After(recurring.EndAt.Time) {
+		return time.Time{}, false, nil
+	}
+	return next, true, nil
+}
+
+// HandleRecurringTransferDispatchTask is the task handler for tasks.TypeRecurringTransferDispatch.
+func (us *UserService) HandleRecurringTransferDispatchTask(ctx context.Context, payload []byte) error {
+	return us.DispatchRecurringTransfers(ctx, time.Now())
+}
+
+// DispatchRecurringTransfers creates the occurrences of every recurring transfer due at now
+// and enqueues all occurrences that have not been executed yet.
+func (us *UserService) DispatchRecurringTransfers(ctx context.Context, now time.Time) error {
+	due, err := us.store.ListDueRecurringTransfers(ctx, db.ListDueRecurringTransfersParams{
+		DueAt: now,
+		Limit: recurringDispatchBatchSize,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list due recurring transfers: %v", err)
+	}
+	for _, recurring := range due {
+		if err := us.createRecurringTransferOccurrence(ctx, recurring.ID, now); err != nil {
+			log.Printf("Failed to dispatch recurring transfer %d: %v", recurring.ID, err)
+		}
+	}
+
+	// Pending occurrences also include those whose enqueue failed on an earlier run
+	pending, err := us.store.ListPendingRecurringTransferOccurrences(ctx, recurringDispatchBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to list pending occurrences: %v", err)
+	}
+	for _, occurrence := range pending {
+		if err := us.taskManager.EnqueueRecurringTransferOccurrenceTask(occurrence.ID); err != nil {
+			log.Printf("could not enqueue task: %v", err)
+		}
+	}
+	return nil
+}
+
+// createRecurringTransferOccurrence records the next due occurrence of a recurring transfer and advances its schedule.
+// The occurrence is unique per recurring transfer and scheduled time, so a repeated dispatch never creates it twice.
+// The schedule advances past now, so slots missed while the dispatcher was down are skipped rather than paid one by one.
+func (us *UserService) createRecurringTransferOccurrence(ctx context.Context, id int64, now time.Time) error {
+	return us.ExecuteInTransaction(ctx, func(q *db.Queries) error {
+		recurring, err := q.GetRecurringTransferForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if recurring.Status != domain.RecurringTransferStatusActive || !recurring.NextRunAt.Valid || recurring.NextRunAt.Time.After(now) {
+			return nil
+		}
+
+		_, err = q.CreateRecurringTransferOccurrence(ctx, db.CreateRecurringTransferOccurrenceParams{
+			RecurringTransferID: recurring.ID,
+			ScheduledFor:        recurring.NextRunAt.Time,
+		})
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("failed to create occurrence: %v", err)
+		}
+
+		params := db.AdvanceRecurringTransferParams{
+			ID:     recurring.ID,
+			Status: domain.RecurringTransferStatusActive,
+		}
+		next, ok, err := nextRecurringRun(recurring, now, recurring.OccurrenceCount+1)
+		if err != nil {
+			return err
+		}
+		if ok {
+			params.NextRunAt = pgtype.Timestamptz{Time: next, Valid: true}
+		} else {
+			params.Status = domain.RecurringTransferStatusCompleted
+		}
+		_, err = q.AdvanceRecurringTransfer(ctx, params)
+		return err
+	})
+}
+
+// HandleRecurringTransferOccurrenceTask is the task handler for tasks.TypeRecurringTransferOccurrence.
+func (us *UserService) HandleRecurringTransferOccurrenceTask(ctx context.Context, p tasks.RecurringTransferOccurrencePayload) error {
+	return us.ExecuteRecurringTransferOccurrence(ctx, p.OccurrenceID)
+}
+
+// ExecuteRecurringTransferOccurrence pays a pending occurrence.
+// The transfer and the occurrence update share one transaction, so a worker restart
+// either sees the occurrence executed or finds no money moved. A rejected payment fails the occurrence,
+// any other error is returned so the task is retried.
+func (us *UserService) ExecuteRecurringTransferOccurrence(ctx context.Context, id int64) error {
+	var params domain.HandleFundsTransferParams
+	var result domain.HandleFundsTransferResult
+	var transferErr error
+
+	err := us.ExecuteInTransaction(ctx, func(q *db.Queries) error {
+		occurrence, err := q.GetRecurringTransferOccurrenceForUpdate(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get occurrence: %v", err)
+		}
+		if occurrence.Status != domain.OccurrenceStatusPending {
+			return nil
+		}
+
+		recurring, err := q.GetRecurringTransfer(ctx, occurrence.RecurringTransferID)
+		if err != nil {
+			return fmt.Errorf("failed to get recurring transfer: %v", err)
+		}
+		if recurring.Status == domain.RecurringTransferStatusPaused || recurring.Status == domain.RecurringTransferStatusDeleted {
+			_, err = q.UpdateRecurringTransferOccurrence(ctx, db.UpdateRecurringTransferOccurrenceParams{
+				ID:            occurrence.ID,
+				Status:        domain.OccurrenceStatusFailed,
+				FailureReason: pgtype.Text{String: fmt.Sprintf("recurring transfer is %s", recurring.Status), Valid: true},
+			})
+			return err
+		}
+		params = domain.HandleFundsTransferParams{
+			FromAccountID: recurring.FromAccountID,
+			ToAccountID:   recurring.ToAccountID,
+			Amount:        recurring.Amount,
+		}
+
+		result, err = us.transferFunds(ctx, q, params)
+		if err != nil {
+			transferErr = err
+			return err
+		}
+
+		_, err = q.UpdateRecurringTransferOccurrence(ctx, db.UpdateRecurringTransferOccurrenceParams{
+			ID:         occurrence.ID,
+			Status:     domain.OccurrenceStatusExecuted,
+			TransferID: pgtype.Int8{Int64: result.Transfer.ID, Valid: true},
+		})
+		return err
+	})
+	if transferErr == nil || !isTransferRejected(transferErr) {
+		if err == nil && result.Transfer.ID != 0 {
+			observeTransfer(result)
+		}
+		return err
+	}
+
+	// The payment itself was rejected, so record it and do not retry the occurrence
+	failed := us.recordFailedTransfer(ctx, params, transferErr)
+	_, err = us.store.UpdateRecurringTransferOccurrence(ctx, db.UpdateRecurringTransferOccurrenceParams{
+		ID:            id,
+		Status:        domain.OccurrenceStatusFailed,
+		TransferID:    pgtype.Int8{Int64: failed.ID, Valid: failed.ID != 0},
+		FailureReason: pgtype.Text{String: transferErr.Error(), Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark occurrence as failed: %v", err)
+	}
+	return nil
+}
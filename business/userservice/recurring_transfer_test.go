@@ -0,0 +1,136 @@
+package userservice
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextRecurringRun(t *testing.T) {
+	monthly := db.RecurringTransfer{Schedule: "0 9 1 * *"}
+	after := time.Date(2026, time.January, 15, 12, 0, 0, 0, time.UTC)
+
+	next, ok, err := nextRecurringRun(monthly, after, 0)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, time.Date(2026, time.February, 1, 9, 0, 0, 0, time.UTC), next)
+
+	// The end date stops the schedule
+	ending := monthly
+	ending.EndAt = pgtype.Timestamptz{Time: time.Date(2026, time.January, 31, 0, 0, 0, 0, time.UTC), Valid: true}
+	_, ok, err = nextRecurringRun(ending, after, 0)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// So does the maximum number of occurrences
+	limited := monthly
+	limited.MaxOccurrences = pgtype.Int4{Int32: 3, Valid: true}
+	_, ok, err = nextRecurringRun(limited, after, 2)
+	require.NoError(t, err)
+	require.True(t, ok)
+	_, ok, err = nextRecurringRun(limited, after, 3)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, _, err = nextRecurringRun(db.RecurringTransfer{Schedule: "not a schedule"}, after, 0)
+	require.Error(t, err)
+}
+
+func TestCreateRecurringTransferRejectsPastStart(t *testing.T) {
+	from := createTestAccount(t, 1000)
+	to := createTestAccount(t, 0)
+
+	startAt := time.Now().Add(-24 * time.Hour)
+	_, err := testService.CreateRecurringTransfer(context.Background(), from.Owner, domain.CreateRecurringTransferParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        10,
+		Schedule:      "0 * * * *",
+		StartAt:       &startAt,
+	})
+	require.ErrorContains(t, err, "start_at")
+}
+
+func TestRecurringTransferSkipsMissedOccurrences(t *testing.T) {
+	ctx := context.Background()
+	from := createTestAccount(t, 1000)
+	to := createTestAccount(t, 0)
+
+	recurring, err := testService.CreateRecurringTransfer(ctx, from.Owner, domain.CreateRecurringTransferParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        10,
+		Schedule:      "0 * * * *",
+	})
+	require.NoError(t, err)
+
+	// The dispatcher was down for a day: one occurrence is due, the other 23 hourly slots are skipped
+	now := recurring.NextRunAt.Time.Add(24*time.Hour + 30*time.Minute)
+	require.NoError(t, testService.createRecurringTransferOccurrence(ctx, recurring.ID, now))
+	advanced, err := testQueries.GetRecurringTransfer(ctx, recurring.ID)
+	require.NoError(t, err)
+	require.True(t, advanced.NextRunAt.Time.After(now))
+	require.Equal(t, recurring.NextRunAt.Time.Add(25*time.Hour), advanced.NextRunAt.Time)
+
+	// A second dispatch at the same time has nothing left to do
+	require.NoError(t, testService.createRecurringTransferOccurrence(ctx, recurring.ID, now))
+	again, err := testQueries.GetRecurringTransfer(ctx, recurring.ID)
+	require.NoError(t, err)
+	require.Equal(t, advanced.NextRunAt, again.NextRunAt)
+	require.Equal(t, advanced.OccurrenceCount, again.OccurrenceCount)
+}
+
+func createTestOccurrence(t *testing.T, from, to db.Account, amount int64) db.RecurringTransferOccurrence {
+	ctx := context.Background()
+	recurring, err := testService.CreateRecurringTransfer(ctx, from.Owner, domain.CreateRecurringTransferParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        amount,
+		Schedule:      "0 * * * *",
+	})
+	require.NoError(t, err)
+	occurrence, err := testQueries.CreateRecurringTransferOccurrence(ctx, db.CreateRecurringTransferOccurrenceParams{
+		RecurringTransferID: recurring.ID,
+		ScheduledFor:        recurring.NextRunAt.Time,
+	})
+	require.NoError(t, err)
+	return occurrence
+}
+
+func TestExecuteRecurringTransferOccurrenceRetriesDatabaseErrors(t *testing.T) {
+	from := createTestAccount(t, 1000)
+	to := createTestAccount(t, 0)
+	occurrence := createTestOccurrence(t, from, to, 10)
+
+	// The transaction cannot start, which must not fail the occurrence for good
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.Error(t, testService.ExecuteRecurringTransferOccurrence(cancelled, occurrence.ID))
+	pending, err := testQueries.GetRecurringTransferOccurrenceForUpdate(context.Background(), occurrence.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.OccurrenceStatusPending, pending.Status)
+
+	// The retried task pays the occurrence
+	require.NoError(t, testService.ExecuteRecurringTransferOccurrence(context.Background(), occurrence.ID))
+	executed, err := testQueries.GetRecurringTransferOccurrenceForUpdate(context.Background(), occurrence.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.OccurrenceStatusExecuted, executed.Status)
+}
+
+func TestExecuteRecurringTransferOccurrenceInsufficientFunds(t *testing.T) {
+	from := createTestAccount(t, 5)
+	to := createTestAccount(t, 0)
+	occurrence := createTestOccurrence(t, from, to, 10)
+
+	// A rejected payment is final, so the task succeeds and is not retried
+	require.NoError(t, testService.ExecuteRecurringTransferOccurrence(context.Background(), occurrence.ID))
+	failed, err := testQueries.GetRecurringTransferOccurrenceForUpdate(context.Background(), occurrence.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.OccurrenceStatusFailed, failed.Status)
+	require.Contains(t, failed.FailureReason.String, ErrInsufficientFunds.Error())
+}
@@ -0,0 +1,182 @@
+package userservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	"github.com/fadedreams/gofinanceflow/business/email"
+	"github.com/fadedreams/gofinanceflow/business/tasks"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// CreateScheduledTransfer stores a future-dated transfer and enqueues its execution.
+func (us *UserService) CreateScheduledTransfer(ctx context.Context, owner string, arg domain.CreateScheduledTransferParams) (*db.ScheduledTransfer, error) {
+	if us.taskManager == nil {
+		return nil, fmt.Errorf("scheduled transfers are not available")
+	}
+	if arg.Amount <= 0 {
+		return nil, fmt.Errorf("amount must be positive")
+	}
+	if arg.FromAccountID == arg.ToAccountID {
+		return nil, fmt.Errorf("from and to accounts must be different")
+	}
+	if !arg.ExecuteAt.After(time.Now()) {
+		return nil, fmt.Errorf("execute_at must be in the future")
+	}
+
+	var scheduled db.ScheduledTransfer
+	err := us.ExecuteInTransaction(ctx, func(q *db.Queries) error {
+		fromAccount, err := q.GetAccount(ctx, arg.FromAccountID)
+		if err != nil {
+			return fmt.Errorf("failed to get 'from' account: %v", err)
+		}
+		if fromAccount.Owner != owner {
+			return fmt.Errorf("'from' account does not belong to %s", owner)
+		}
+		if _, err := q.GetAccount(ctx, arg.ToAccountID); err != nil {
+			return fmt.Errorf("failed to get 'to' account: %v", err)
+		}
+
+		scheduled, err = q.CreateScheduledTransfer(ctx, db.CreateScheduledTransferParams{
+			Owner:         owner,
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        arg.Amount,
+			ExecuteAt:     arg.ExecuteAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create scheduled transfer: %v", err)
+		}
+
+		// Enqueue before commit so a scheduled transfer is never stored without its task.
+		// A task whose transaction rolls back finds no row and is archived without retrying.
+		if err := us.taskManager.EnqueueScheduledTransferTask(scheduled.ID, scheduled.ExecuteAt); err != nil {
+			return fmt.Errorf("failed to enqueue scheduled transfer: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &scheduled, nil
+}
+
+func (us *UserService) ListScheduledTransfers(ctx context.Context, owner string, arg domain.ListScheduledTransfersParams) ([]db.ScheduledTransfer, error) {
+	return us.store.ListScheduledTransfers(ctx, db.ListScheduledTransfersParams{
+		Owner:  owner,
+		Limit:  arg.Limit,
+		Offset: arg.Offset,
+	})
+}
+
+// CancelScheduledTransfer cancels a scheduled transfer that has not started executing yet.
+func (us *UserService) CancelScheduledTransfer(ctx context.Context, owner string, id int64) (*db.ScheduledTransfer, error) {
+	scheduled, err := us.store.CancelScheduledTransfer(ctx, db.CancelScheduledTransferParams{
+		ID:    id,
+		Owner: owner,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("scheduled transfer %d cannot be cancelled", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &scheduled, nil
+}
+
+// HandleScheduledTransferTask is the task handler for tasks.TypeScheduledTransfer.
+func (us *UserService) HandleScheduledTransferTask(ctx context.Context, p tasks.ScheduledTransferPayload) error {
+	return us.ExecuteScheduledTransfer(ctx, p.ScheduledTransferID)
+}
+
+// ExecuteScheduledTransfer moves the money of a due scheduled transfer.
+// The row is locked and marked executed in the same transaction as the transfer,
+// so the task may safely be delivered more than once. Transfers that break a
+// business rule are marked failed, any other error is returned so the task is retried.
+func (us *UserService) ExecuteScheduledTransfer(ctx context.Context, id int64) error {
+	var scheduled db.ScheduledTransfer
+	var params domain.HandleFundsTransferParams
+	var result domain.HandleFundsTransferResult
+	var transferErr error
+
+	err := us.ExecuteInTransaction(ctx, func(q *db.Queries) error {
+		var err error
+		scheduled, err = q.GetScheduledTransferForUpdate(ctx, id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("scheduled transfer %d not found: %w", id, asynq.SkipRetry)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get scheduled transfer: %v", err)
+		}
+		if scheduled.Status != domain.ScheduledTransferStatusScheduled {
+			log.Printf("Skipping scheduled transfer %d: %s", id, scheduled.Status)
+			return nil
+		}
+		params = domain.HandleFundsTransferParams{
+			FromAccountID: scheduled.FromAccountID,
+			ToAccountID:   scheduled.ToAccountID,
+			Amount:        scheduled.Amount,
+		}
+
+		result, err = us.transferFunds(ctx, q, params)
+		if err != nil {
+			transferErr = err
+			return err
+		}
+
+		_, err = q.UpdateScheduledTransferStatus(ctx, db.UpdateScheduledTransferStatusParams{
+			ID:         scheduled.ID,
+			Status:     domain.ScheduledTransferStatusExecuted,
+			TransferID: pgtype.Int8{Int64: result.Transfer.ID, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to mark scheduled transfer as executed: %v", err)
+		}
+		return nil
+	})
+	if transferErr == nil || !isTransferRejected(transferErr) {
+		if err == nil && result.Transfer.ID != 0 {
+			observeTransfer(result)
+		}
+		return err
+	}
+
+	// The payment itself was rejected, so record it and do not retry the scheduled transfer
+	failed := us.recordFailedTransfer(ctx, params, transferErr)
+	return us.failScheduledTransfer(ctx, scheduled, failed, transferErr)
+}
+
+// failScheduledTransfer records a failed execution and notifies the owner by email.
+// A transfer cancelled in the meantime keeps its status and no email is sent.
+func (us *UserService) failScheduledTransfer(ctx context.Context, scheduled db.ScheduledTransfer, transfer db.Transfer, cause error) error {
+	_, err := us.store.UpdateScheduledTransferStatus(ctx, db.UpdateScheduledTransferStatusParams{
+		ID:            scheduled.ID,
+		Status:        domain.ScheduledTransferStatusFailed,
+		TransferID:    pgtype.Int8{Int64: transfer.ID, Valid: transfer.ID != 0},
+		FailureReason: pgtype.Text{String: cause.Error(), Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("Skipping failure of scheduled transfer %d: no longer scheduled", scheduled.ID)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to mark scheduled transfer as failed: %v", err)
+	}
+
+	err = us.taskManager.EnqueueEmailDeliveryTask(scheduled.Owner, email.TemplateScheduledTransferFailed, map[string]string{
+		"scheduled_transfer_id": strconv.FormatInt(scheduled.ID, 10),
+		"reason":                cause.Error(),
+	})
+	if err != nil {
+		log.Printf("could not enqueue task: %v", err)
+	}
+	return nil
+}
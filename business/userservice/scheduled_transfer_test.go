@@ -0,0 +1,137 @@
+package userservice
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestScheduledTransfer(t *testing.T, from, to db.Account, amount int64) db.ScheduledTransfer {
+	scheduled, err := testQueries.CreateScheduledTransfer(context.Background(), db.CreateScheduledTransferParams{
+		Owner:         from.Owner,
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        amount,
+		ExecuteAt:     time.Now(),
+	})
+	require.NoError(t, err)
+	return scheduled
+}
+
+func TestIsTransferRejected(t *testing.T) {
+	require.True(t, isTransferRejected(rejectTransfer("'from' account is %s", domain.AccountStatusFrozen)))
+	require.True(t, isTransferRejected(fmt.Errorf("%w in 'from' account", ErrInsufficientFunds)))
+	require.True(t, isTransferRejected(&domain.LimitExceededError{Scope: "account"}))
+	require.False(t, isTransferRejected(fmt.Errorf("failed to create transfer: %v", context.DeadlineExceeded)))
+}
+
+func TestExecuteScheduledTransfer(t *testing.T) {
+	ctx := context.Background()
+	from := createTestAccount(t, 1000)
+	to := createTestAccount(t, 0)
+	scheduled := createTestScheduledTransfer(t, from, to, 300)
+
+	require.NoError(t, testService.ExecuteScheduledTransfer(ctx, scheduled.ID))
+
+	executed, err := testQueries.GetScheduledTransfer(ctx, scheduled.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.ScheduledTransferStatusExecuted, executed.Status)
+	require.True(t, executed.TransferID.Valid)
+
+	// A redelivered task must not move the money twice
+	require.NoError(t, testService.ExecuteScheduledTransfer(ctx, scheduled.ID))
+	fromAccount, err := testQueries.GetAccount(ctx, from.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(700), fromAccount.Balance)
+	toAccount, err := testQueries.GetAccount(ctx, to.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(300), toAccount.Balance)
+}
+
+func TestExecuteScheduledTransferInsufficientFunds(t *testing.T) {
+	ctx := context.Background()
+	from := createTestAccount(t, 100)
+	to := createTestAccount(t, 0)
+	scheduled := createTestScheduledTransfer(t, from, to, 300)
+
+	// A rejected transfer is final, so the task succeeds and is not retried
+	require.NoError(t, testService.ExecuteScheduledTransfer(ctx, scheduled.ID))
+
+	failed, err := testQueries.GetScheduledTransfer(ctx, scheduled.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.ScheduledTransferStatusFailed, failed.Status)
+	require.Contains(t, failed.FailureReason.String, ErrInsufficientFunds.Error())
+
+	fromAccount, err := testQueries.GetAccount(ctx, from.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), fromAccount.Balance)
+}
+
+func TestExecuteScheduledTransferRetriesDatabaseErrors(t *testing.T) {
+	from := createTestAccount(t, 1000)
+	to := createTestAccount(t, 0)
+	scheduled := createTestScheduledTransfer(t, from, to, 300)
+
+	// The transaction cannot start, which must not fail the scheduled transfer for good
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.Error(t, testService.ExecuteScheduledTransfer(cancelled, scheduled.ID))
+
+	pending, err := testQueries.GetScheduledTransfer(context.Background(), scheduled.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.ScheduledTransferStatusScheduled, pending.Status)
+
+	// The retried task goes through
+	require.NoError(t, testService.ExecuteScheduledTransfer(context.Background(), scheduled.ID))
+	executed, err := testQueries.GetScheduledTransfer(context.Background(), scheduled.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.ScheduledTransferStatusExecuted, executed.Status)
+}
+
+func TestExecuteScheduledTransferSkipsCancelled(t *testing.T) {
+	ctx := context.Background()
+	from := createTestAccount(t, 1000)
+	to := createTestAccount(t, 0)
+	scheduled := createTestScheduledTransfer(t, from, to, 300)
+
+	_, err := testService.CancelScheduledTransfer(ctx, from.Owner, scheduled.ID)
+	require.NoError(t, err)
+	require.NoError(t, testService.ExecuteScheduledTransfer(ctx, scheduled.ID))
+
+	cancelled, err := testQueries.GetScheduledTransfer(ctx, scheduled.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.ScheduledTransferStatusCancelled, cancelled.Status)
+	require.False(t, cancelled.TransferID.Valid)
+
+	fromAccount, err := testQueries.GetAccount(ctx, from.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), fromAccount.Balance)
+}
+
+func TestExecuteScheduledTransferNotFound(t *testing.T) {
+	// The transaction that enqueued the task rolled back, so there is nothing to retry
+	err := testService.ExecuteScheduledTransfer(context.Background(), -1)
+	require.ErrorIs(t, err, asynq.SkipRetry)
+}
+
+func TestFailScheduledTransferKeepsCancellation(t *testing.T) {
+	ctx := context.Background()
+	from := createTestAccount(t, 1000)
+	to := createTestAccount(t, 0)
+	scheduled := createTestScheduledTransfer(t, from, to, 300)
+
+	_, err := testService.CancelScheduledTransfer(ctx, from.Owner, scheduled.ID)
+	require.NoError(t, err)
+	require.NoError(t, testService.failScheduledTransfer(ctx, scheduled, db.Transfer{}, ErrInsufficientFunds))
+
+	cancelled, err := testQueries.GetScheduledTransfer(ctx, scheduled.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.ScheduledTransferStatusCancelled, cancelled.Status)
+	require.False(t, cancelled.FailureReason.Valid)
+}
@@ -0,0 +1,116 @@
+package userservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ErrInvalidSearch is returned for transaction searches with invalid filters or sort orders.
+var ErrInvalidSearch = errors.New("invalid search")
+
+// SearchTransactions searches transfers and account transactions together, one page at a time.
+// Callers whose role cannot search every account only see transactions of their own accounts.
+func (us *UserService) SearchTransactions(ctx context.Context, actor string, role string, arg domain.SearchTransactionsParams) (domain.TransactionSearchPage, error) {
+	if err := validateTransactionSearch(&arg); err != nil {
+		return domain.TransactionSearchPage{}, err
+	}
+
+	scope := fmt.Sprintf("transaction_search:%s:%s", arg.Sort, arg.Order)
+	after, err := pageKey[db.TransactionSearchKey](scope, arg.PageToken, arg.Limit)
+	if err != nil {
+		return domain.TransactionSearchPage{}, err
+	}
+
+	params := db.SearchTransactionsParams{
+		SortByAmount: arg.Sort == domain.TransactionSortAmount,
+		Descending:   arg.Order == domain.SortOrderDesc,
+		Limit:        arg.Limit + 1,
+	}
+	if arg.PageToken != "" {
+		params.After = &after
+	}
+	if !domain.CanSearchAllTransactions(role) {
+		params.Owner = pgtype.Text{String: actor, Valid: true}
+	}
+	if arg.AccountID != 0 {
+		params.AccountID = pgtype.Int8{Int64: arg.AccountID, Valid: true}
+	}
+	if arg.CounterpartyAccountID != 0 {
+		params.CounterpartyAccountID = pgtype.Int8{Int64: arg.CounterpartyAccountID, Valid: true}
+	}
+	if arg.MinAmount != 0 {
+		params.MinAmount = pgtype.Int8{Int64: arg.MinAmount, Valid: true}
+	}
+	if arg.MaxAmount != 0 {
+		params.MaxAmount = pgtype.Int8{Int64: arg.MaxAmount, Valid: true}
+	}
+	if !arg.From.IsZero() {
+		params.StartAt = pgtype.Timestamptz{Time: arg.From, Valid: true}
+	}
+	if !arg.To.IsZero() {
+		params.EndAt = pgtype.Timestamptz{Time: arg.To, Valid: true}
+	}
+	if arg.Type != "" {
+		params.Kind = pgtype.Text{String: arg.Type, Valid: true}
+	}
+	if arg.Status != "" {
+		params.Status = pgtype.Text{String: arg.Status, Valid: true}
+	}
+	if arg.Query != "" {
+		params.Query = pgtype.Text{String: arg.Query, Valid: true}
+	}
+
+	results, err := us.store.SearchTransactions(ctx, params)
+	if err != nil {
+		return domain.TransactionSearchPage{}, fmt.Errorf("failed to search transactions: %v", err)
+	}
+
+	var page domain.TransactionSearchPage
+	page.Results, page.NextPageToken = paginate(scope, results, arg.Limit, transactionSearchKey)
+	return page, nil
+}
+
+// transactionSearchKey returns the sort key of a search result.
+func transactionSearchKey(r db.TransactionSearchResult) db.TransactionSearchKey {
+	amount := r.Amount
+	if amount < 0 {
+		amount = -amount
+	}
+	return db.TransactionSearchKey{CreatedAt: r.CreatedAt, Amount: amount, Kind: r.Kind, ID: r.ID}
+}
+
+// validateTransactionSearch checks the filters of a search and fills in the default sort order,
+// newest first.
+func validateTransactionSearch(arg *domain.SearchTransactionsParams) error {
+	if arg.Sort == "" {
+		arg.Sort = domain.TransactionSortCreatedAt
+	}
+	if arg.Order == "" {
+		arg.Order = domain.SortOrderDesc
+	}
+
+	switch {
+	case arg.Sort != domain.TransactionSortCreatedAt && arg.Sort != domain.TransactionSortAmount:
+		return fmt.Errorf("%w: unknown sort %q", ErrInvalidSearch, arg.Sort)
+	case arg.Order != domain.SortOrderAsc && arg.Order != domain.SortOrderDesc:
+		return fmt.Errorf("%w: unknown order %q", ErrInvalidSearch, arg.Order)
+	case arg.Type != "" && arg.Type != domain.TransactionKindTransfer && arg.Type != domain.TransactionKindEntry:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidSearch, arg.Type)
+	case arg.Status != "" && arg.Status != domain.TransactionStatusPosted && !domain.IsValidTransferStatus(arg.Status):
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidSearch, arg.Status)
+	case arg.MinAmount < 0 || arg.MaxAmount < 0:
+		return fmt.Errorf("%w: amounts must not be negative", ErrInvalidSearch)
+	case arg.MaxAmount != 0 && arg.MinAmount > arg.MaxAmount:
+		return fmt.Errorf("%w: min_amount must not exceed max_amount", ErrInvalidSearch)
+	case !arg.From.IsZero() && !arg.To.IsZero() && !arg.From.Before(arg.To):
+		return fmt.Errorf("%w: from must be before to", ErrInvalidSearch)
+	case arg.CounterpartyAccountID != 0 && arg.CounterpartyAccountID == arg.AccountID:
+		return fmt.Errorf("%w: counterparty must differ from the account", ErrInvalidSearch)
+	}
+	return nil
+}
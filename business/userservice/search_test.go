@@ -0,0 +1,55 @@
+package userservice
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTransactionSearch(t *testing.T) {
+	day := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name    string
+		arg     domain.SearchTransactionsParams
+		wantErr bool
+	}{
+		{name: "defaults", arg: domain.SearchTransactionsParams{}},
+		{name: "all filters", arg: domain.SearchTransactionsParams{
+			AccountID: 1, CounterpartyAccountID: 2, MinAmount: 10, MaxAmount: 100,
+			From: day, To: day.AddDate(0, 0, 1), Type: domain.TransactionKindTransfer,
+			Status: domain.TransferStatusCompleted, Query: "rent", Sort: domain.TransactionSortAmount, Order: domain.SortOrderAsc,
+		}},
+		{name: "posted status", arg: domain.SearchTransactionsParams{Status: domain.TransactionStatusPosted}},
+		{name: "unknown sort", arg: domain.SearchTransactionsParams{Sort: "id"}, wantErr: true},
+		{name: "unknown order", arg: domain.SearchTransactionsParams{Order: "up"}, wantErr: true},
+		{name: "unknown type", arg: domain.SearchTransactionsParams{Type: "hold"}, wantErr: true},
+		{name: "unknown status", arg: domain.SearchTransactionsParams{Status: "lost"}, wantErr: true},
+		{name: "inverted amounts", arg: domain.SearchTransactionsParams{MinAmount: 100, MaxAmount: 10}, wantErr: true},
+		{name: "inverted dates", arg: domain.SearchTransactionsParams{From: day, To: day}, wantErr: true},
+		{name: "counterparty is account", arg: domain.SearchTransactionsParams{AccountID: 1, CounterpartyAccountID: 1}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			arg := tc.arg
+			err := validateTransactionSearch(&arg)
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrInvalidSearch)
+				return
+			}
+			require.NoError(t, err)
+			require.NotEmpty(t, arg.Sort)
+			require.NotEmpty(t, arg.Order)
+		})
+	}
+}
+
+func TestTransactionSearchKey(t *testing.T) {
+	key := transactionSearchKey(db.TransactionSearchResult{Kind: domain.TransactionKindEntry, ID: 7, Amount: -250})
+	require.Equal(t, int64(250), key.Amount)
+	require.Equal(t, domain.TransactionKindEntry, key.Kind)
+}
@@ -0,0 +1,57 @@
+package userservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fadedreams/gofinanceflow/business/statement"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxStatementPeriod limits the date range of a single statement.
+const maxStatementPeriod = 366 * 24 * time.Hour
+
+// ErrInvalidStatement is returned for statements with an invalid date range.
+var ErrInvalidStatement = errors.New("invalid statement")
+
+// GetAccountStatement builds the statement of an account for [from, to) on behalf of its owner or an admin.
+func (us *UserService) GetAccountStatement(ctx context.Context, accountID int64, actor string, admin bool, from, to time.Time) (statement.Statement, error) {
+	if !from.Before(to) {
+		return statement.Statement{}, fmt.Errorf("%w: from must be before to", ErrInvalidStatement)
+	}
+	if to.Sub(from) > maxStatementPeriod {
+		return statement.Statement{}, fmt.Errorf("%w: statement period must not exceed a year", ErrInvalidStatement)
+	}
+
+	account, err := us.store.GetAccount(ctx, accountID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && !admin && account.Owner != actor) {
+		return statement.Statement{}, fmt.Errorf("%w: account %d", ErrAccountNotFound, accountID)
+	}
+	if err != nil {
+		return statement.Statement{}, fmt.Errorf("failed to get account: %v", err)
+	}
+
+	// The balance at from is derived from the current balance, which also covers
+	// initial balances that have no account transaction
+	opening, err := us.store.GetAccountBalanceAt(ctx, db.GetAccountBalanceAtParams{
+		AccountID: accountID,
+		At:        from,
+	})
+	if err != nil {
+		return statement.Statement{}, fmt.Errorf("failed to get opening balance: %v", err)
+	}
+
+	entries, err := us.store.ListAccountTransactionsInRange(ctx, db.ListAccountTransactionsInRangeParams{
+		AccountID: accountID,
+		StartAt:   from,
+		EndAt:     to,
+	})
+	if err != nil {
+		return statement.Statement{}, fmt.Errorf("failed to list account transactions: %v", err)
+	}
+
+	return statement.New(account, from, to, opening, entries), nil
+}
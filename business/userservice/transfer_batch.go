@@ -0,0 +1,397 @@
+package userservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/fadedreams/gofinanceflow/business/batch"
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	"github.com/fadedreams/gofinanceflow/business/tasks"
+	"github.com/fadedreams/gofinanceflow/foundation/metrics"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// CreateTransferBatch validates every line of a batch, stores it and executes it in the background.
+// Invalid lines are reported together in a *domain.BatchValidationError and nothing is stored.
+func (us *UserService) CreateTransferBatch(ctx context.Context, owner string, mode string, lines []batch.Line) (domain.TransferBatchResult, error) {
+	var result domain.TransferBatchResult
+	if !domain.IsValidTransferBatchMode(mode) {
+		return result, fmt.Errorf("invalid batch mode %q", mode)
+	}
+	if len(lines) == 0 {
+		return result, fmt.Errorf("batch has no transfers")
+	}
+	if len(lines) > batch.MaxLines {
+		return result, fmt.Errorf("batch has %d transfers, at most %d are allowed", len(lines), batch.MaxLines)
+	}
+
+	lineErrors, err := us.validateTransferBatch(ctx, owner, lines)
+	if err != nil {
+		return result, err
+	}
+	if len(lineErrors) > 0 {
+		return result, &domain.BatchValidationError{Lines: lineErrors}
+	}
+
+	var total int64
+	for _, line := range lines {
+		total += line.Amount
+	}
+
+	err = us.ExecuteInTransaction(ctx, func(q *db.Queries) error {
+		var err error
+		result.TransferBatch, err = q.CreateTransferBatch(ctx, db.CreateTransferBatchParams{
+			Owner:       owner,
+			Mode:        mode,
+			LineCount:   int32(len(lines)),
+			TotalAmount: total,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create transfer batch: %v", err)
+		}
+
+		result.Lines = make([]db.TransferBatchLine, 0, len(lines))
+		for _, line := range lines {
+			created, err := q.CreateTransferBatchLine(ctx, db.CreateTransferBatchLineParams{
+				BatchID:       result.ID,
+				LineNumber:    int32(line.LineNumber),
+				FromAccountID: line.FromAccountID,
+				ToAccountID:   line.ToAccountID,
+				Amount:        line.Amount,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create line %d: %v", line.LineNumber, err)
+			}
+			result.Lines = append(result.Lines, created)
+		}
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if err := us.taskManager.EnqueueTransferBatchTask(result.ID); err != nil {
+		return result, fmt.Errorf("failed to enqueue transfer batch: %v", err)
+	}
+	return result, nil
+}
+
+// validateTransferBatch checks that every line moves a positive amount between two active accounts
+// of the same currency, that the owner owns every source account and that every source account
+// can fund all of its lines and their fees together.
+func (us *UserService) validateTransferBatch(ctx context.Context, owner string, lines []batch.Line) ([]domain.BatchLineError, error) {
+	var lineErrors []domain.BatchLineError
+	fail := func(line batch.Line, format string, args ...interface{}) {
+		lineErrors = append(lineErrors, domain.BatchLineError{Line: line.LineNumber, Error: fmt.Sprintf(format, args...)})
+	}
+
+	accounts := make(map[int64]db.Account)
+	getAccount := func(id int64) (db.Account, bool, error) {
+		if account, ok := accounts[id]; ok {
+			return account, true, nil
+		}
+		account, err := us.store.GetAccount(ctx, id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return account, false, nil
+		}
+		if err != nil {
+			return account, false, fmt.Errorf("failed to get account %d: %v", id, err)
+		}
+		accounts[id] = account
+		return account, true, nil
+	}
+
+	// debits holds the running total every source account has to fund, fees included
+	debits := make(map[int64]int64)
+	for _, line := range lines {
+		if line.Amount <= 0 {
+			fail(line, "amount must be positive")
+			continue
+		}
+		if line.FromAccountID == line.ToAccountID {
+			fail(line, "cannot transfer to the same account")
+			continue
+		}
+
+		fromAccount, found, err := getAccount(line.FromAccountID)
+		if err != nil {
+			return nil, err
+		}
+		if !found || fromAccount.Owner != owner {
+			fail(line, "'from' account %d not found", line.FromAccountID)
+			continue
+		}
+		toAccount, found, err := getAccount(line.ToAccountID)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			fail(line, "'to' account %d not found", line.ToAccountID)
+			continue
+		}
+
+		if fromAccount.Status != domain.AccountStatusActive {
+			fail(line, "'from' account is %s", fromAccount.Status)
+			continue
+		}
+		if toAccount.Status != domain.AccountStatusActive {
+			fail(line, "'to' account is %s", toAccount.Status)
+			continue
+		}
+		if fromAccount.Currency != toAccount.Currency {
+			fail(line, "currency mismatch: %s to %s", fromAccount.Currency, toAccount.Currency)
+			continue
+		}
+
+		_, totalFee, err := transferFees(ctx, us.store, fromAccount, line.Amount)
+		if err != nil {
+			return nil, err
+		}
+		debits[fromAccount.ID] += line.Amount + totalFee
+
+		policy, err := accountPolicy(ctx, us.store, fromAccount.ID)
+		if err != nil {
+			return nil, err
+		}
+		available, err := availableBalance(ctx, us.store, fromAccount)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkBalancePolicy(policy, available, debits[fromAccount.ID]); err != nil {
+			fail(line, "%v in account %d: lines up to here need %d", err, fromAccount.ID, debits[fromAccount.ID])
+		}
+	}
+	return lineErrors, nil
+}
+
+// ListTransferBatches lists the transfer batches of a user, newest first.
+func (us *UserService) ListTransferBatches(ctx context.Context, owner string, arg domain.ListTransferBatchesParams) ([]db.TransferBatch, error) {
+	return us.store.ListTransferBatches(ctx, db.ListTransferBatchesParams{
+		Owner:  owner,
+		Limit:  arg.Limit,
+		Offset: arg.Offset,
+	})
+}
+
+// GetTransferBatch returns a transfer batch and the result of every line on behalf of its owner or an admin.
+func (us *UserService) GetTransferBatch(ctx context.Context, id int64, actor string, admin bool) (domain.TransferBatchResult, error) {
+	var result domain.TransferBatchResult
+
+	transferBatch, err := us.store.GetTransferBatch(ctx, id)
+	if err != nil {
+		return result, fmt.Errorf("transfer batch %d not found", id)
+	}
+	if !admin && transferBatch.Owner != actor {
+		return result, fmt.Errorf("transfer batch %d not found", id)
+	}
+
+	result.TransferBatch = transferBatch
+	result.Lines, err = us.store.ListTransferBatchLines(ctx, id)
+	if err != nil {
+		return result, fmt.Errorf("failed to list transfer batch lines: %v", err)
+	}
+	return result, nil
+}
+
+// HandleTransferBatchTask handles the task that executes the transfers of a batch.
+func (us *UserService) HandleTransferBatchTask(ctx context.Context, p tasks.TransferBatchPayload) error {
+	return us.ExecuteTransferBatch(ctx, p.BatchID)
+}
+
+// ExecuteTransferBatch executes the pending lines of a batch in line order.
+// Lines are locked before they are executed and finished lines are never executed again,
+// so an interrupted batch may safely be retried, even while an earlier attempt is still running.
+func (us *UserService) ExecuteTransferBatch(ctx context.Context, id int64) error {
+	transferBatch, err := us.store.ClaimTransferBatch(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("Skipping transfer batch %d: already finished", id)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to claim transfer batch: %v", err)
+	}
+
+	if transferBatch.Mode == domain.TransferBatchModeAllOrNothing {
+		err = us.executeAllOrNothing(ctx, id)
+	} else {
+		var lines []db.TransferBatchLine
+		lines, err = us.store.ListTransferBatchLines(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to list transfer batch lines: %v", err)
+		}
+		err = us.executeBestEffort(ctx, lines)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Derive the batch status from the stored lines
+	lines, err := us.store.ListTransferBatchLines(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to list transfer batch lines: %v", err)
+	}
+	completed := 0
+	for _, line := range lines {
+		if line.Status == domain.TransferBatchLineStatusCompleted {
+			completed++
+		}
+	}
+	status := domain.TransferBatchStatusPartiallyCompleted
+	switch completed {
+	case len(lines):
+		status = domain.TransferBatchStatusCompleted
+	case 0:
+		status = domain.TransferBatchStatusFailed
+	}
+
+	if _, err := us.store.CompleteTransferBatch(ctx, db.CompleteTransferBatchParams{ID: id, Status: status}); err != nil {
+		return fmt.Errorf("failed to complete transfer batch: %v", err)
+	}
+	return nil
+}
+
+// executeAllOrNothing executes every line in one transaction. If a line is rejected, no money moves,
+// the rejected line records the reason and every other line records that it was not executed.
+// The lines are locked and re-read first, so an overlapping attempt of the same batch waits and then finds them done.
+// Any other error is returned so the task is retried.
+func (us *UserService) executeAllOrNothing(ctx context.Context, batchID int64) error {
+	var lines []db.TransferBatchLine
+	var failedLine db.TransferBatchLine
+	var cause error
+	var results []domain.HandleFundsTransferResult
+
+	err := us.ExecuteInTransaction(ctx, func(q *db.Queries) error {
+		var err error
+		lines, err = q.ListTransferBatchLinesForUpdate(ctx, batchID)
+		if err != nil {
+			return fmt.Errorf("failed to lock transfer batch lines: %v", err)
+		}
+
+		// Lock every account up front in id order, so the batch cannot deadlock with concurrent transfers
+		if len(lines) > 0 {
+			others := make([]int64, 0, 2*len(lines))
+			for _, line := range lines {
+				others = append(others, line.FromAccountID, line.ToAccountID)
+			}
+			if _, _, err := lockAccounts(ctx, q, lines[0].FromAccountID, lines[0].ToAccountID, others...); err != nil {
+				return err
+			}
+		}
+
+		for _, line := range lines {
+			if line.Status != domain.TransferBatchLineStatusPending {
+				continue
+			}
+			result, err := us.transferFunds(ctx, q, domain.HandleFundsTransferParams{
+				FromAccountID: line.FromAccountID,
+				ToAccountID:   line.ToAccountID,
+				Amount:        line.Amount,
+			})
+			if err != nil {
+				failedLine, cause = line, err
+				return err
+			}
+			results = append(results, result)
+			if _, err := q.CompleteTransferBatchLine(ctx, db.CompleteTransferBatchLineParams{
+				ID:         line.ID,
+				TransferID: pgtype.Int8{Int64: result.Transfer.ID, Valid: true},
+			}); err != nil {
+				return fmt.Errorf("failed to complete line %d: %v", line.LineNumber, err)
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		for _, result := range results {
+			observeTransfer(result)
+		}
+		return nil
+	}
+	if cause == nil || !isTransferRejected(cause) {
+		// The batch could not be executed at all, let the task be retried
+		return err
+	}
+	metrics.TransfersFailed.Inc()
+
+	return us.ExecuteInTransaction(ctx, func(q *db.Queries) error {
+		for _, line := range lines {
+			reason := fmt.Sprintf("not executed: line %d failed", failedLine.LineNumber)
+			if line.ID == failedLine.ID {
+				reason = cause.Error()
+			}
+			// Lines that are no longer pending were finished by another attempt
+			_, err := q.FailTransferBatchLine(ctx, db.FailTransferBatchLineParams{
+				ID:            line.ID,
+				FailureReason: pgtype.Text{String: reason, Valid: true},
+			})
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("failed to fail line %d: %v", line.LineNumber, err)
+			}
+		}
+		return nil
+	})
+}
+
+// executeBestEffort executes every pending line in its own transaction, recording rejected lines and moving on.
+// Each line is locked and re-read in its transaction, so a line an overlapping attempt already finished is skipped.
+// Any error other than a rejected transfer is returned so the task is retried.
+func (us *UserService) executeBestEffort(ctx context.Context, lines []db.TransferBatchLine) error {
+	for _, line := range lines {
+		if line.Status != domain.TransferBatchLineStatusPending {
+			continue
+		}
+
+		var lineErr error
+		var result domain.HandleFundsTransferResult
+		err := us.ExecuteInTransaction(ctx, func(q *db.Queries) error {
+			locked, err := q.GetTransferBatchLineForUpdate(ctx, line.ID)
+			if err != nil {
+				return fmt.Errorf("failed to lock line %d: %v", line.LineNumber, err)
+			}
+			if locked.Status != domain.TransferBatchLineStatusPending {
+				return nil
+			}
+
+			result, err = us.transferFunds(ctx, q, domain.HandleFundsTransferParams{
+				FromAccountID: line.FromAccountID,
+				ToAccountID:   line.ToAccountID,
+				Amount:        line.Amount,
+			})
+			if err != nil {
+				lineErr = err
+				return err
+			}
+			if _, err := q.CompleteTransferBatchLine(ctx, db.CompleteTransferBatchLineParams{
+				ID:         line.ID,
+				TransferID: pgtype.Int8{Int64: result.Transfer.ID, Valid: true},
+			}); err != nil {
+				return fmt.Errorf("failed to complete line %d: %v", line.LineNumber, err)
+			}
+			return nil
+		})
+		if err == nil {
+			if result.Transfer.ID != 0 {
+				observeTransfer(result)
+			}
+			continue
+		}
+		if lineErr == nil || !isTransferRejected(lineErr) {
+			return err
+		}
+		metrics.TransfersFailed.Inc()
+
+		// A line that is no longer pending was finished by another attempt
+		_, err = us.store.FailTransferBatchLine(ctx, db.FailTransferBatchLineParams{
+			ID:            line.ID,
+			FailureReason: pgtype.Text{String: lineErr.Error(), Valid: true},
+		})
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("failed to fail line %d: %v", line.LineNumber, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package userservice
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fadedreams/gofinanceflow/business/batch"
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	"github.com/fadedreams/gofinanceflow/business/tasks/taskstest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteTransferBatchRedeliveryPaysOnce(t *testing.T) {
+	ctx := context.Background()
+	service := NewUserService(testService.connPool, testQueries, taskstest.New(t))
+	from := createTestAccount(t, 1000)
+	to := createTestAccount(t, 0)
+
+	created, err := service.CreateTransferBatch(ctx, from.Owner, domain.TransferBatchModeAllOrNothing, []batch.Line{
+		{LineNumber: 1, FromAccountID: from.ID, ToAccountID: to.ID, Amount: 100},
+		{LineNumber: 2, FromAccountID: from.ID, ToAccountID: to.ID, Amount: 200},
+	})
+	require.NoError(t, err)
+	require.NoError(t, service.ExecuteTransferBatch(ctx, created.ID))
+
+	// A redelivered task that claims the batch while it looks unfinished finds every line done
+	_, err = testService.connPool.Exec(ctx, "UPDATE transfer_batches SET status = 'processing' WHERE id = $1", created.ID)
+	require.NoError(t, err)
+	require.NoError(t, service.ExecuteTransferBatch(ctx, created.ID))
+
+	account, err := testQueries.GetAccount(ctx, from.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(700), account.Balance)
+	result, err := service.GetTransferBatch(ctx, created.ID, from.Owner, false)
+	require.NoError(t, err)
+	require.Equal(t, domain.TransferBatchStatusCompleted, result.Status)
+}
+
+func TestExecuteTransferBatchBestEffortRejectsLine(t *testing.T) {
+	ctx := context.Background()
+	service := NewUserService(testService.connPool, testQueries, taskstest.New(t))
+	from := createTestAccount(t, 1000)
+	to := createTestAccount(t, 0)
+
+	created, err := service.CreateTransferBatch(ctx, from.Owner, domain.TransferBatchModeBestEffort, []batch.Line{
+		{LineNumber: 1, FromAccountID: from.ID, ToAccountID: to.ID, Amount: 300},
+		{LineNumber: 2, FromAccountID: from.ID, ToAccountID: to.ID, Amount: 300},
+	})
+	require.NoError(t, err)
+
+	// The balance drops after validation, so the second line is rejected when the batch runs
+	_, err = service.HandleFundsTransfer(ctx, domain.HandleFundsTransferParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        600,
+	})
+	require.NoError(t, err)
+	require.NoError(t, service.ExecuteTransferBatch(ctx, created.ID))
+
+	result, err := service.GetTransferBatch(ctx, created.ID, from.Owner, false)
+	require.NoError(t, err)
+	require.Equal(t, domain.TransferBatchStatusPartiallyCompleted, result.Status)
+	require.Equal(t, domain.TransferBatchLineStatusCompleted, result.Lines[0].Status)
+	require.Equal(t, domain.TransferBatchLineStatusFailed, result.Lines[1].Status)
+	require.Contains(t, result.Lines[1].FailureReason.String, ErrInsufficientFunds.Error())
+}
@@ -0,0 +1,173 @@
+package userservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// transferUsage holds the outgoing transfers counted against a limit.
+type transferUsage struct {
+	DailyAmount   int64
+	DailyCount    int64
+	MonthlyAmount int64
+	MonthlyCount  int64
+}
+
+// GetTransferLimit returns the stored limits of a role, user or account.
+func (us *UserService) GetTransferLimit(ctx context.Context, scope, subject string) (db.TransferLimit, error) {
+	return us.store.GetTransferLimit(ctx, db.GetTransferLimitParams{Scope: scope, Subject: subject})
+}
+
+// UpdateTransferLimit stores the limits of a role, user or account.
+// User and account limits override the defaults of the owner's role.
+func (us *UserService) UpdateTransferLimit(ctx context.Context, scope, subject, updatedBy string, arg domain.UpdateTransferLimitParams) (db.TransferLimit, error) {
+	if !domain.IsValidTransferLimitScope(scope) {
+		return db.TransferLimit{}, fmt.Errorf("unknown transfer limit scope %q", scope)
+	}
+	for _, limit := range []*int64{arg.PerTransferAmount, arg.DailyAmount, arg.MonthlyAmount} {
+		if limit != nil && *limit < 0 {
+			return db.TransferLimit{}, fmt.Errorf("limits must not be negative")
+		}
+	}
+	for _, limit := range []*int32{arg.DailyCount, arg.MonthlyCount} {
+		if limit != nil && *limit < 0 {
+			return db.TransferLimit{}, fmt.Errorf("limits must not be negative")
+		}
+	}
+
+	return us.store.UpsertTransferLimit(ctx, db.UpsertTransferLimitParams{
+		Scope:             scope,
+		Subject:           subject,
+		PerTransferAmount: optionalInt8(arg.PerTransferAmount),
+		DailyAmount:       optionalInt8(arg.DailyAmount),
+		DailyCount:        optionalInt4(arg.DailyCount),
+		MonthlyAmount:     optionalInt8(arg.MonthlyAmount),
+		MonthlyCount:      optionalInt4(arg.MonthlyCount),
+		UpdatedBy:         pgtype.Text{String: updatedBy, Valid: true},
+	})
+}
+
+// DeleteTransferLimit removes the limits of a role, user or account.
+// Users and accounts fall back to the defaults of the owner's role.
+func (us *UserService) DeleteTransferLimit(ctx context.Context, scope, subject string) error {
+	return us.store.DeleteTransferLimit(ctx, db.DeleteTransferLimitParams{Scope: scope, Subject: subject})
+}
+
+// checkTransferLimits verifies that an external transfer of amount from the account stays within
+// the limits of the account and of its owner; transfers between the owner's own accounts are
+// not counted. It must run inside the transfer's transaction after the account has been
+// locked; the owner is locked here so concurrent transfers from other accounts of the same
+// owner are counted.
+func checkTransferLimits(ctx context.Context, q *db.Queries, fromAccount db.Account, amount int64, now time.Time) error {
+	if err := q.LockOwnerTransferLimits(ctx, fromAccount.Owner); err != nil {
+		return fmt.Errorf("failed to lock transfer limits: %v", err)
+	}
+
+	now = now.UTC()
+	dayStart := now.Truncate(24 * time.Hour)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	// Account limits only apply when an admin has set them
+	accountID := strconv.FormatInt(fromAccount.ID, 10)
+	accountLimit, err := q.GetTransferLimit(ctx, db.GetTransferLimitParams{Scope: domain.TransferLimitScopeAccount, Subject: accountID})
+	if err == nil {
+		row, err := q.GetAccountTransferUsage(ctx, db.GetAccountTransferUsageParams{
+			DayStart:   dayStart,
+			AccountID:  fromAccount.ID,
+			MonthStart: monthStart,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get account transfer usage: %v", err)
+		}
+		if err := checkTransferLimit(accountLimit, transferUsage(row), amount); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to get account transfer limit: %v", err)
+	}
+
+	// Owner limits fall back to the defaults of the owner's role
+	ownerLimit, err := ownerTransferLimit(ctx, q, fromAccount.Owner)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	row, err := q.GetOwnerTransferUsage(ctx, db.GetOwnerTransferUsageParams{
+		DayStart:   dayStart,
+		Owner:      fromAccount.Owner,
+		MonthStart: monthStart,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get owner transfer usage: %v", err)
+	}
+	return checkTransferLimit(ownerLimit, transferUsage(row), amount)
+}
+
+// ownerTransferLimit returns the limits of a user, or those of the user's role.
+func ownerTransferLimit(ctx context.Context, q *db.Queries, owner string) (db.TransferLimit, error) {
+	limit, err := q.GetTransferLimit(ctx, db.GetTransferLimitParams{Scope: domain.TransferLimitScopeUser, Subject: owner})
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return limit, err
+	}
+
+	user, err := q.GetUser(ctx, owner)
+	if err != nil {
+		return limit, fmt.Errorf("failed to get account owner: %v", err)
+	}
+	return q.GetTransferLimit(ctx, db.GetTransferLimitParams{Scope: domain.TransferLimitScopeRole, Subject: user.Role})
+}
+
+// checkTransferLimit returns a *domain.LimitExceededError when a transfer of amount on top
+// of the usage breaks the limit.
+func checkTransferLimit(limit db.TransferLimit, usage transferUsage, amount int64) error {
+	exceeded := func(name string, max, used int64) error {
+		return &domain.LimitExceededError{
+			Scope:   limit.Scope,
+			Subject: limit.Subject,
+			Limit:   name,
+			Max:     max,
+			Used:    used,
+		}
+	}
+
+	if limit.PerTransferAmount.Valid && amount > limit.PerTransferAmount.Int64 {
+		return exceeded("per-transfer amount", limit.PerTransferAmount.Int64, 0)
+	}
+	if limit.DailyAmount.Valid && usage.DailyAmount+amount > limit.DailyAmount.Int64 {
+		return exceeded("daily amount", limit.DailyAmount.Int64, usage.DailyAmount)
+	}
+	if limit.DailyCount.Valid && usage.DailyCount+1 > int64(limit.DailyCount.Int32) {
+		return exceeded("daily count", int64(limit.DailyCount.Int32), usage.DailyCount)
+	}
+	if limit.MonthlyAmount.Valid && usage.MonthlyAmount+amount > limit.MonthlyAmount.Int64 {
+		return exceeded("monthly amount", limit.MonthlyAmount.Int64, usage.MonthlyAmount)
+	}
+	if limit.MonthlyCount.Valid && usage.MonthlyCount+1 > int64(limit.MonthlyCount.Int32) {
+		return exceeded("monthly count", int64(limit.MonthlyCount.Int32), usage.MonthlyCount)
+	}
+	return nil
+}
+
+func optionalInt8(v *int64) pgtype.Int8 {
+	if v == nil {
+		return pgtype.Int8{}
+	}
+	return pgtype.Int8{Int64: *v, Valid: true}
+}
+
+func optionalInt4(v *int32) pgtype.Int4 {
+	if v == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *v, Valid: true}
+}
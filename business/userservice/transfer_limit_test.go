@@ -0,0 +1,50 @@
+package userservice
+
+import (
+	"testing"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckTransferLimit(t *testing.T) {
+	limit := db.TransferLimit{
+		Scope:             domain.TransferLimitScopeRole,
+		Subject:           "depositor",
+		PerTransferAmount: pgtype.Int8{Int64: 500, Valid: true},
+		DailyAmount:       pgtype.Int8{Int64: 1000, Valid: true},
+		DailyCount:        pgtype.Int4{Int32: 3, Valid: true},
+		MonthlyAmount:     pgtype.Int8{Int64: 5000, Valid: true},
+	}
+
+	testCases := []struct {
+		name    string
+		usage   transferUsage
+		amount  int64
+		limited string
+	}{
+		{"within limits", transferUsage{DailyAmount: 400, DailyCount: 1, MonthlyAmount: 400, MonthlyCount: 1}, 500, ""},
+		{"per-transfer amount", transferUsage{}, 501, "per-transfer amount"},
+		{"daily amount", transferUsage{DailyAmount: 600, DailyCount: 2, MonthlyAmount: 600, MonthlyCount: 2}, 401, "daily amount"},
+		{"daily count", transferUsage{DailyAmount: 30, DailyCount: 3, MonthlyAmount: 30, MonthlyCount: 3}, 10, "daily count"},
+		{"monthly amount", transferUsage{MonthlyAmount: 4900, MonthlyCount: 40}, 101, "monthly amount"},
+		{"unset monthly count is unlimited", transferUsage{MonthlyAmount: 100, MonthlyCount: 100000}, 100, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkTransferLimit(limit, tc.usage, tc.amount)
+			if tc.limited == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			var limitErr *domain.LimitExceededError
+			require.ErrorAs(t, err, &limitErr)
+			require.Equal(t, tc.limited, limitErr.Limit)
+			require.Contains(t, err.Error(), domain.ErrorCodeLimitExceeded)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package userservice
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransfersAreOnlyVisibleToTheirAccountOwners(t *testing.T) {
+	ctx := context.Background()
+	from := createTestAccount(t, 1000)
+	to := createTestAccount(t, 0)
+	stranger := createTestAccount(t, 0)
+
+	result, err := testService.HandleFundsTransfer(ctx, domain.HandleFundsTransferParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+	id := result.Transfer.ID
+
+	for _, owner := range []string{from.Owner, to.Owner} {
+		transfer, err := testService.GetTransfer(ctx, id, owner, false)
+		require.NoError(t, err)
+		require.Equal(t, id, transfer.ID)
+	}
+	_, err = testService.GetTransfer(ctx, id, stranger.Owner, false)
+	require.Error(t, err)
+	_, err = testService.GetTransfer(ctx, id, stranger.Owner, true)
+	require.NoError(t, err)
+
+	params := domain.ListTransfersParams{AccountID: from.ID, Limit: 10}
+	transfers, err := testService.ListTransfers(ctx, from.Owner, false, params)
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	_, err = testService.ListTransfers(ctx, stranger.Owner, false, params)
+	require.Error(t, err)
+}
+
+func TestTransferRejectsNonPositiveAmount(t *testing.T) {
+	ctx := context.Background()
+	from := createTestAccount(t, 0)
+	victim := createTestAccount(t, 1000)
+
+	for _, amount := range []int64{0, -500} {
+		_, err := testService.HandleFundsTransfer(ctx, domain.HandleFundsTransferParams{
+			FromAccountID: from.ID,
+			ToAccountID:   victim.ID,
+			Amount:        amount,
+		})
+		require.Error(t, err)
+		require.True(t, isTransferRejected(err))
+	}
+
+	account, err := testQueries.GetAccount(ctx, victim.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), account.Balance)
+}
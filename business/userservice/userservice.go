@@ -2,18 +2,27 @@ package userservice
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"slices"
+	"time"
 
 	"github.com/fadedreams/gofinanceflow/business/domain"
+	"github.com/fadedreams/gofinanceflow/business/tasks"
+	"github.com/fadedreams/gofinanceflow/business/webhook"
+	"github.com/fadedreams/gofinanceflow/foundation/metrics"
 	"github.com/fadedreams/gofinanceflow/foundation/sdk"
 	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type UserService struct {
-	connPool *pgxpool.Pool
-	store    *db.Queries
+	connPool      *pgxpool.Pool
+	store         *db.Queries
+	taskManager   tasks.TaskManager
+	webhookClient *webhook.Client
 }
 
 //	func NewUserService(store *db.Queries) *UserService {
@@ -21,10 +30,12 @@ type UserService struct {
 //			store: store,
 //		}
 //	}
-func NewUserService(dbPool *pgxpool.Pool, store *db.Queries) *UserService {
+func NewUserService(dbPool *pgxpool.Pool, store *db.Queries, taskManager tasks.TaskManager) *UserService {
 	return &UserService{
-		connPool: dbPool,
-		store:    store,
+		connPool:      dbPool,
+		store:         store,
+		taskManager:   taskManager,
+		webhookClient: webhook.NewClient(webhookTimeout),
 	}
 }
 
@@ -36,8 +47,21 @@ func (us *UserService) GetUser(ctx context.Context, username string) (*db.User,
 	return &user, nil
 }
 
+// CreateUser creates a user and records a domain.EventUserCreated event in the same transaction.
 func (us *UserService) CreateUser(ctx context.Context, params db.CreateUserParams) (*db.User, error) {
-	user, err := us.store.CreateUser(ctx, params)
+	var user db.User
+	err := us.ExecuteInTransaction(ctx, func(q *db.Queries) error {
+		var err error
+		user, err = q.CreateUser(ctx, params)
+		if err != nil {
+			return err
+		}
+		return recordEvent(ctx, q, domain.AggregateUser, user.Username, domain.EventUserCreated, domain.UserCreatedEvent{
+			Username: user.Username,
+			FullName: user.FullName,
+			Email:    user.Email,
+		})
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -83,58 +107,326 @@ func (us *UserService) HandleFundsTransfer(ctx context.Context, arg domain.Handl
 	var result domain.HandleFundsTransferResult
 
 	err := us.ExecuteInTransaction(ctx, func(q *db.Queries) error {
-		// Check if 'from' account has sufficient balance
-		fromAccount, err := q.GetAccount(ctx, arg.FromAccountID)
+		var err error
+		result, err = us.transferFunds(ctx, q, arg)
+		return err
+	})
+	if err != nil {
+		// The transaction has been rolled back, so record the failed attempt on its own
+		result.Transfer = us.recordFailedTransfer(ctx, arg, err)
+		return result, err
+	}
+
+	observeTransfer(result)
+	return result, nil
+}
+
+// transferFunds moves money between two accounts using the given transaction-bound queries.
+func (us *UserService) transferFunds(ctx context.Context, q *db.Queries, arg domain.HandleFundsTransferParams) (domain.HandleFundsTransferResult, error) {
+	var result domain.HandleFundsTransferResult
+	// A negative amount would pull money out of the 'to' account without any of its checks
+	if arg.Amount <= 0 {
+		return result, rejectTransfer("amount must be positive")
+	}
+	if len(arg.Description) > domain.MaxTransferDescriptionLength {
+		return result, rejectTransfer("description must not exceed %d characters", domain.MaxTransferDescriptionLength)
+	}
+
+	// Evaluate the fees first so the fee accounts can be locked together with both accounts
+	source, err := q.GetAccount(ctx, arg.FromAccountID)
+	if err != nil {
+		return result, fmt.Errorf("failed to get 'from' account: %v", err)
+	}
+	result.Fees, result.TotalFee, err = transferFees(ctx, q, source, arg.Amount)
+	if err != nil {
+		return result, err
+	}
+	feeAccountIDs := make([]int64, 0, len(result.Fees))
+	for _, fee := range result.Fees {
+		feeAccountIDs = append(feeAccountIDs, fee.FeeAccountID)
+	}
+
+	// Lock all accounts so balances, holds, statuses and policies cannot change until commit
+	fromAccount, toAccount, err := lockAccounts(ctx, q, arg.FromAccountID, arg.ToAccountID, feeAccountIDs...)
+	if err != nil {
+		return result, err
+	}
+
+	// Frozen and closed accounts can neither send nor receive money
+	if fromAccount.Status != domain.AccountStatusActive {
+		return result, rejectTransfer("'from' account is %s", fromAccount.Status)
+	}
+	if toAccount.Status != domain.AccountStatusActive {
+		return result, rejectTransfer("'to' account is %s", toAccount.Status)
+	}
+
+	// Check if 'from' account has sufficient available balance under its policy
+	fromPolicy, err := accountPolicy(ctx, q, arg.FromAccountID)
+	if err != nil {
+		return result, err
+	}
+	available, err := availableBalance(ctx, q, fromAccount)
+	if err != nil {
+		return result, err
+	}
+	if err := checkBalancePolicy(fromPolicy, available, arg.Amount+result.TotalFee); err != nil {
+		return result, fmt.Errorf("%w in 'from' account", err)
+	}
+
+	// Check the velocity limits of the 'from' account and its owner,
+	// moving money between one's own accounts is not an external transfer
+	if fromAccount.Owner != toAccount.Owner {
+		if err := checkTransferLimits(ctx, q, fromAccount, arg.Amount, time.Now()); err != nil {
+			return result, err
+		}
+	}
+
+	// Create the transfer record
+	result.Transfer, err = q.CreateTransfer(ctx, db.CreateTransferParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		Status:        domain.TransferStatusPending,
+		Description:   arg.Description,
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to create transfer: %v", err)
+	}
+
+	// Create account transactions for both accounts
+	result.FromEntry, err = q.CreateAccountTransactions(ctx, db.CreateAccountTransactionsParams{
+		AccountID: arg.FromAccountID,
+		Amount:    -arg.Amount,
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to create 'from' account transaction: %v", err)
+	}
+
+	result.ToEntry, err = q.CreateAccountTransactions(ctx, db.CreateAccountTransactionsParams{
+		AccountID: arg.ToAccountID,
+		Amount:    arg.Amount,
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to create 'to' account transaction: %v", err)
+	}
+
+	// Adjust account balances
+	if arg.FromAccountID < arg.ToAccountID {
+		result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
+	} else {
+		result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
+	}
+	if err != nil {
+		return result, fmt.Errorf("failed to adjust account balances: %v", err)
+	}
+
+	// Post the fees to the fee accounts
+	if len(result.Fees) > 0 {
+		if err := postTransferFees(ctx, q, result.Transfer, result.Fees); err != nil {
+			return result, err
+		}
+		if result.FromAccount, err = q.GetAccount(ctx, arg.FromAccountID); err != nil {
+			return result, fmt.Errorf("failed to get 'from' account: %v", err)
+		}
+	}
+
+	// Mark the transfer as completed
+	result.Transfer, err = q.UpdateTransferStatus(ctx, db.UpdateTransferStatusParams{
+		ID:     result.Transfer.ID,
+		Status: domain.TransferStatusCompleted,
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to complete transfer: %v", err)
+	}
+
+	err = recordEvent(ctx, q, domain.AggregateAccount, arg.FromAccountID, domain.EventTransferCompleted, domain.TransferCompletedEvent{
+		TransferID:    result.Transfer.ID,
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		FromOwner:     result.FromAccount.Owner,
+		ToOwner:       result.ToAccount.Owner,
+		Amount:        arg.Amount,
+		Fee:           result.TotalFee,
+		Currency:      result.FromAccount.Currency,
+		FromBalance:   result.FromAccount.Balance,
+		Description:   arg.Description,
+		CompletedAt:   time.Now(),
+	})
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// transferRejection is a transfer that breaks a business rule and fails the same way when retried.
+type transferRejection struct {
+	msg string
+}
+
+func (e *transferRejection) Error() string {
+	return e.msg
+}
+
+func rejectTransfer(format string, args ...any) error {
+	return &transferRejection{msg: fmt.Sprintf(format, args...)}
+}
+
+// isTransferRejected reports whether a transferFunds error is a business rule violation
+// rather than a database failure that may succeed on retry.
+func isTransferRejected(err error) bool {
+	var rejection *transferRejection
+	var limitErr *domain.LimitExceededError
+	return errors.As(err, &rejection) || errors.As(err, &limitErr) || errors.Is(err, ErrInsufficientFunds)
+}
+
+// recordFailedTransfer stores a failed transfer outside of the rolled back transaction.
+// Attempts that reference unknown accounts cannot be stored and are only logged.
+func (us *UserService) recordFailedTransfer(ctx context.Context, arg domain.HandleFundsTransferParams, cause error) db.Transfer {
+	transfer, err := us.store.CreateTransfer(ctx, db.CreateTransferParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		Status:        domain.TransferStatusFailed,
+		FailureReason: pgtype.Text{String: cause.Error(), Valid: true},
+		Description:   arg.Description,
+	})
+	if err != nil {
+		log.Printf("Failed to record failed transfer: %v", err)
+	}
+	metrics.TransfersFailed.Inc()
+	return transfer
+}
+
+// observeTransfer counts a committed transfer and the amount it moved in the business metrics.
+// It must only be called once the transaction of transferFunds has been committed.
+func observeTransfer(result domain.HandleFundsTransferResult) {
+	currency := result.FromAccount.Currency
+	metrics.TransfersCompleted.WithLabelValues(currency).Inc()
+	metrics.TransferAmount.WithLabelValues(currency).Add(float64(result.Transfer.Amount))
+}
+
+// ReverseTransfer moves the money of a completed transfer back to its source account
+// and refunds the fees it charged from the fee accounts.
+func (us *UserService) ReverseTransfer(ctx context.Context, transferID int64) (domain.HandleFundsTransferResult, error) {
+	var result domain.HandleFundsTransferResult
+
+	err := us.ExecuteInTransaction(ctx, func(q *db.Queries) error {
+		transfer, err := q.GetTransferForUpdate(ctx, transferID)
 		if err != nil {
-			return fmt.Errorf("failed to get 'from' account: %v", err)
+			return fmt.Errorf("failed to get transfer: %v", err)
 		}
-		if fromAccount.Balance < arg.Amount {
-			return fmt.Errorf("insufficient funds in 'from' account")
+		if !domain.CanTransitionTransfer(transfer.Status, domain.TransferStatusReversed) {
+			return fmt.Errorf("cannot reverse a %s transfer", transfer.Status)
 		}
 
-		// Create the transfer record
-		result.Transfer, err = q.CreateTransfer(ctx, db.CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.Amount,
-		})
+		fees, err := q.ListTransferFees(ctx, transfer.ID)
 		if err != nil {
-			return fmt.Errorf("failed to create transfer: %v", err)
+			return fmt.Errorf("failed to get transfer fees: %v", err)
+		}
+		feeAccountIDs := make([]int64, 0, len(fees))
+		for _, fee := range fees {
+			feeAccountIDs = append(feeAccountIDs, fee.FeeAccountID)
+			result.TotalFee += fee.Amount
 		}
 
-		// Create account transactions for both accounts
+		// Closed accounts must keep a zero balance, frozen ones may still be corrected
+		fromAccount, toAccount, err := lockAccounts(ctx, q, transfer.FromAccountID, transfer.ToAccountID, feeAccountIDs...)
+		if err != nil {
+			return err
+		}
+		if fromAccount.Status == domain.AccountStatusClosed || toAccount.Status == domain.AccountStatusClosed {
+			return fmt.Errorf("cannot reverse a transfer involving a closed account")
+		}
+
+		// Create the compensating account transactions
 		result.FromEntry, err = q.CreateAccountTransactions(ctx, db.CreateAccountTransactionsParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
+			AccountID: transfer.FromAccountID,
+			Amount:    transfer.Amount,
 		})
 		if err != nil {
 			return fmt.Errorf("failed to create 'from' account transaction: %v", err)
 		}
 
 		result.ToEntry, err = q.CreateAccountTransactions(ctx, db.CreateAccountTransactionsParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
+			AccountID: transfer.ToAccountID,
+			Amount:    -transfer.Amount,
 		})
 		if err != nil {
 			return fmt.Errorf("failed to create 'to' account transaction: %v", err)
 		}
 
 		// Adjust account balances
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
+		if transfer.FromAccountID < transfer.ToAccountID {
+			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, transfer.FromAccountID, transfer.Amount, transfer.ToAccountID, -transfer.Amount)
 		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
+			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, transfer.ToAccountID, -transfer.Amount, transfer.FromAccountID, transfer.Amount)
 		}
 		if err != nil {
 			return fmt.Errorf("failed to adjust account balances: %v", err)
 		}
 
+		// Refund the fees to the 'from' account
+		if len(fees) > 0 {
+			if err := reverseTransferFees(ctx, q, transfer, fees); err != nil {
+				return err
+			}
+			if result.FromAccount, err = q.GetAccount(ctx, transfer.FromAccountID); err != nil {
+				return fmt.Errorf("failed to get 'from' account: %v", err)
+			}
+		}
+
+		result.Transfer, err = q.UpdateTransferStatus(ctx, db.UpdateTransferStatusParams{
+			ID:     transfer.ID,
+			Status: domain.TransferStatusReversed,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to reverse transfer: %v", err)
+		}
+
 		return nil
 	})
 
 	return result, err
 }
 
+// GetTransfer returns a transfer on behalf of the owner of its 'from' or 'to' account, or an admin.
+// Transfers the actor may not see are reported as not found.
+func (us *UserService) GetTransfer(ctx context.Context, id int64, actor string, admin bool) (*db.Transfer, error) {
+	transfer, err := us.store.GetTransfer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !admin && !us.ownsAccount(ctx, transfer.FromAccountID, actor) && !us.ownsAccount(ctx, transfer.ToAccountID, actor) {
+		return nil, fmt.Errorf("transfer %d not found", id)
+	}
+	return &transfer, nil
+}
+
+// ListTransfers lists the transfers of an account with offset paging, on behalf of its owner or an admin.
+func (us *UserService) ListTransfers(ctx context.Context, actor string, admin bool, arg domain.ListTransfersParams) ([]db.Transfer, error) {
+	if !admin && !us.ownsAccount(ctx, arg.AccountID, actor) {
+		return nil, fmt.Errorf("account %d not found", arg.AccountID)
+	}
+
+	params := db.ListTransfersParams{
+		FromAccountID: arg.AccountID,
+		ToAccountID:   arg.AccountID,
+		Limit:         arg.Limit,
+		Offset:        arg.Offset,
+	}
+	if arg.Status != "" {
+		params.Status = pgtype.Text{String: arg.Status, Valid: true}
+	}
+	return us.store.ListTransfers(ctx, params)
+}
+
+// ownsAccount reports whether an account exists and belongs to actor.
+func (us *UserService) ownsAccount(ctx context.Context, accountID int64, actor string) bool {
+	account, err := us.store.GetAccount(ctx, accountID)
+	return err == nil && account.Owner == actor
+}
+
 func (us *UserService) ExecuteInTransaction(ctx context.Context, fn func(*db.Queries) error) error {
 	tx, err := us.connPool.Begin(ctx)
 	if err != nil {
@@ -171,6 +463,41 @@ func (us *UserService) ExecuteInTransaction(ctx context.Context, fn func(*db.Que
 	return nil
 }
 
+// lockAccounts locks both accounts of a transfer, and any other accounts it touches, in ascending id order.
+func lockAccounts(ctx context.Context, q *db.Queries, fromAccountID, toAccountID int64, otherAccountIDs ...int64) (fromAccount db.Account, toAccount db.Account, err error) {
+	// Lock in id order so concurrent transfers cannot deadlock
+	ids := append([]int64{fromAccountID, toAccountID}, otherAccountIDs...)
+	slices.Sort(ids)
+	for _, id := range slices.Compact(ids) {
+		account, err := q.GetAccountForUpdate(ctx, id)
+		switch {
+		case err != nil && id == fromAccountID:
+			return fromAccount, toAccount, fmt.Errorf("failed to get 'from' account: %v", err)
+		case err != nil && id == toAccountID:
+			return fromAccount, toAccount, fmt.Errorf("failed to get 'to' account: %v", err)
+		case err != nil:
+			return fromAccount, toAccount, fmt.Errorf("failed to get account %d: %v", id, err)
+		}
+
+		if id == fromAccountID {
+			fromAccount = account
+		}
+		if id == toAccountID {
+			toAccount = account
+		}
+	}
+	return fromAccount, toAccount, nil
+}
+
+// availableBalance returns the balance of the account minus its active holds.
+func availableBalance(ctx context.Context, q *db.Queries, account db.Account) (int64, error) {
+	held, err := q.GetAccountHeldAmount(ctx, account.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get held amount: %v", err)
+	}
+	return account.Balance - held, nil
+}
+
 func addMoney(
 	ctx context.Context,
 	q *db.Queries,
@@ -0,0 +1,383 @@
+package userservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	"github.com/fadedreams/gofinanceflow/business/tasks"
+	"github.com/fadedreams/gofinanceflow/business/webhook"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// webhookTimeout limits how long a subscriber may take to answer a webhook request.
+const webhookTimeout = 10 * time.Second
+
+// webhookMaxAttempts is the number of attempts after which a delivery fails for good.
+const webhookMaxAttempts = 8
+
+// webhookSweepDelay is how long past its due time a pending delivery is left before the sweeper enqueues it again.
+const webhookSweepDelay = 15 * time.Minute
+
+// webhookSweepBatchSize is the number of overdue deliveries the sweeper enqueues per run.
+const webhookSweepBatchSize = 100
+
+// webhookMaxConsecutiveFailures is the number of failed attempts in a row that disables a subscription.
+const webhookMaxConsecutiveFailures = 20
+
+// maxWebhookEventTypes limits the event patterns of a single subscription.
+const maxWebhookEventTypes = 20
+
+// CreateWebhookSubscription subscribes a URL of the owner to events matching the given patterns.
+func (us *UserService) CreateWebhookSubscription(ctx context.Context, owner string, arg domain.CreateWebhookSubscriptionParams) (domain.CreateWebhookSubscriptionResponse, error) {
+	if err := us.webhookClient.ValidateURL(arg.URL); err != nil {
+		return domain.CreateWebhookSubscriptionResponse{}, err
+	}
+	if len(arg.EventTypes) == 0 || len(arg.EventTypes) > maxWebhookEventTypes {
+		return domain.CreateWebhookSubscriptionResponse{}, fmt.Errorf("between 1 and %d event types are required", maxWebhookEventTypes)
+	}
+	for _, pattern := range arg.EventTypes {
+		if !slices.ContainsFunc(domain.EventTypes, func(eventType string) bool { return tasks.MatchEvent(pattern, eventType) }) {
+			return domain.CreateWebhookSubscriptionResponse{}, fmt.Errorf("event type %q matches no event", pattern)
+		}
+	}
+	if arg.Secret == "" {
+		secret, err := webhook.GenerateSecret()
+		if err != nil {
+			return domain.CreateWebhookSubscriptionResponse{}, fmt.Errorf("failed to generate secret: %v", err)
+		}
+		arg.Secret = secret
+	}
+
+	sub, err := us.store.CreateWebhookSubscription(ctx, db.CreateWebhookSubscriptionParams{
+		Owner:      owner,
+		Url:        arg.URL,
+		EventTypes: arg.EventTypes,
+		Secret:     arg.Secret,
+	})
+	if err != nil {
+		return domain.CreateWebhookSubscriptionResponse{}, fmt.Errorf("failed to create webhook subscription: %v", err)
+	}
+	return domain.CreateWebhookSubscriptionResponse{
+		WebhookSubscription: domain.NewWebhookSubscription(sub),
+		Secret:              sub.Secret,
+	}, nil
+}
+
+// ListWebhookSubscriptions returns the owner's webhook subscriptions.
+func (us *UserService) ListWebhookSubscriptions(ctx context.Context, owner string) ([]domain.WebhookSubscription, error) {
+	subs, err := us.store.ListWebhookSubscriptions(ctx, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %v", err)
+	}
+	result := make([]domain.WebhookSubscription, 0, len(subs))
+	for _, sub := range subs {
+		result = append(result, domain.NewWebhookSubscription(sub))
+	}
+	return result, nil
+}
+
+// GetWebhookSubscription returns a webhook subscription on behalf of its owner or an admin.
+func (us *UserService) GetWebhookSubscription(ctx context.Context, id int64, actor string, admin bool) (domain.WebhookSubscription, error) {
+	sub, err := us.webhookSubscription(ctx, id, actor, admin)
+	if err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	return domain.NewWebhookSubscription(sub), nil
+}
+
+// DeleteWebhookSubscription deletes a webhook subscription and its delivery log.
+func (us *UserService) DeleteWebhookSubscription(ctx context.Context, id int64, actor string, admin bool) error {
+	if _, err := us.webhookSubscription(ctx, id, actor, admin); err != nil {
+		return err
+	}
+	if err := us.store.DeleteWebhookSubscription(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %v", err)
+	}
+	return nil
+}
+
+// EnableWebhookSubscription re-enables a subscription that was disabled after repeated failures.
+func (us *UserService) EnableWebhookSubscription(ctx context.Context, id int64, actor string, admin bool) (domain.WebhookSubscription, error) {
+	if _, err := us.webhookSubscription(ctx, id, actor, admin); err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	sub, err := us.store.EnableWebhookSubscription(ctx, id)
+	if err != nil {
+		return domain.WebhookSubscription{}, fmt.Errorf("failed to enable webhook subscription: %v", err)
+	}
+	return domain.NewWebhookSubscription(sub), nil
+}
+
+// ListWebhookDeliveries returns the delivery log of a subscription, newest first.
+func (us *UserService) ListWebhookDeliveries(ctx context.Context, id int64, actor string, admin bool, arg domain.ListWebhookDeliveriesParams) ([]db.WebhookDelivery, error) {
+	if _, err := us.webhookSubscription(ctx, id, actor, admin); err != nil {
+		return nil, err
+	}
+	if arg.Limit <= 0 || arg.Limit > maxPageSize {
+		arg.Limit = maxPageSize
+	}
+	deliveries, err := us.store.ListWebhookDeliveries(ctx, db.ListWebhookDeliveriesParams{
+		SubscriptionID: id,
+		Limit:          arg.Limit,
+		Offset:         arg.Offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %v", err)
+	}
+	return deliveries, nil
+}
+
+// RedeliverWebhook sends the event of a delivery to the subscription again as a new delivery.
+func (us *UserService) RedeliverWebhook(ctx context.Context, id, deliveryID int64, actor string, admin bool) (db.WebhookDelivery, error) {
+	sub, err := us.webhookSubscription(ctx, id, actor, admin)
+	if err != nil {
+		return db.WebhookDelivery{}, err
+	}
+	if sub.Status != domain.WebhookStatusActive {
+		return db.WebhookDelivery{}, fmt.Errorf("webhook subscription %d is %s", id, sub.Status)
+	}
+	original, err := us.store.GetWebhookDelivery(ctx, deliveryID)
+	if err != nil || original.SubscriptionID != id {
+		return db.WebhookDelivery{}, fmt.Errorf("webhook delivery %d not found", deliveryID)
+	}
+
+	delivery, err := us.store.CreateWebhookRedelivery(ctx, original.ID)
+	if err != nil {
+		return db.WebhookDelivery{}, fmt.Errorf("failed to create webhook redelivery: %v", err)
+	}
+	if err := us.taskManager.EnqueueWebhookDeliveryTask(delivery.ID, 0, 0); err != nil {
+		// The delivery is pending, so the sweeper enqueues it once it is overdue
+		log.Printf("Failed to enqueue webhook delivery %d: %v", delivery.ID, err)
+	}
+	return delivery, nil
+}
+
+// webhookSubscription returns a subscription if the actor may manage it.
+func (us *UserService) webhookSubscription(ctx context.Context, id int64, actor string, admin bool) (db.WebhookSubscription, error) {
+	sub, err := us.store.GetWebhookSubscription(ctx, id)
+	if err != nil {
+		return db.WebhookSubscription{}, fmt.Errorf("webhook subscription %d not found", id)
+	}
+	if !admin && sub.Owner != actor {
+		return db.WebhookSubscription{}, fmt.Errorf("webhook subscription %d not found", id)
+	}
+	return sub, nil
+}
+
+// WebhookEventHandler returns the task handler that fans a domain event out to the matching
+// webhook subscriptions of the users involved. Deliveries are created idempotently,
+// so the handler may run more than once for the same event.
+func (us *UserService) WebhookEventHandler(eventType string) func(ctx context.Context, payload []byte) error {
+	return func(ctx context.Context, payload []byte) error {
+		owners, err := eventOwners(eventType, payload)
+		if err != nil {
+			return err
+		}
+		subs, err := us.store.ListActiveWebhookSubscriptions(ctx, owners)
+		if err != nil {
+			return fmt.Errorf("failed to list webhook subscriptions: %v", err)
+		}
+
+		eventID, body, err := webhook.NewEnvelope(eventType, payload, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to encode webhook: %v", err)
+		}
+		for _, sub := range subs {
+			if !slices.ContainsFunc(sub.EventTypes, func(pattern string) bool { return tasks.MatchEvent(pattern, eventType) }) {
+				continue
+			}
+			delivery, err := us.store.CreateWebhookDelivery(ctx, db.CreateWebhookDeliveryParams{
+				SubscriptionID: sub.ID,
+				EventID:        eventID,
+				EventType:      eventType,
+				Payload:        body,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create webhook delivery: %v", err)
+			}
+			if delivery.Status != domain.WebhookDeliveryStatusPending || delivery.Attempts > 0 {
+				continue
+			}
+			err = us.taskManager.EnqueueWebhookDeliveryTask(delivery.ID, 0, 0)
+			if err != nil && !errors.Is(err, tasks.ErrDuplicateTask) {
+				return fmt.Errorf("failed to enqueue webhook delivery: %v", err)
+			}
+		}
+		return nil
+	}
+}
+
+// eventOwners returns the users a domain event concerns.
+func eventOwners(eventType string, payload []byte) ([]string, error) {
+	var owners []string
+	var err error
+	switch eventType {
+	case domain.EventUserCreated:
+		var e domain.UserCreatedEvent
+		err = json.Unmarshal(payload, &e)
+		owners = []string{e.Username}
+	case domain.EventAccountCreated:
+		var e domain.AccountCreatedEvent
+		err = json.Unmarshal(payload, &e)
+		owners = []string{e.Owner}
+	case domain.EventTransferCompleted:
+		var e domain.TransferCompletedEvent
+		err = json.Unmarshal(payload, &e)
+		owners = []string{e.FromOwner}
+		if e.ToOwner != e.FromOwner {
+			owners = append(owners, e.ToOwner)
+		}
+	default:
+		return nil, fmt.Errorf("unknown event type %q", eventType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal failed: %v", err)
+	}
+	return owners, nil
+}
+
+// HandleWebhookDeliveryTask makes one attempt to deliver a webhook. A failed attempt is rescheduled
+// with exponential backoff until webhookMaxAttempts, and counts towards disabling the subscription.
+// The next attempt is enqueued before this one is recorded, so a recorded failure always has a successor;
+// if recording fails, the task is retried and the successor waits until the attempt is recorded.
+func (us *UserService) HandleWebhookDeliveryTask(ctx context.Context, p tasks.WebhookDeliveryPayload) error {
+	delivery, err := us.store.GetWebhookDelivery(ctx, p.DeliveryID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("Skipping webhook delivery %d: not found", p.DeliveryID)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get webhook delivery: %v", err)
+	}
+	if delivery.Status != domain.WebhookDeliveryStatusPending || int(delivery.Attempts) > p.Attempt {
+		log.Printf("Skipping webhook delivery %d: attempt %d was already made", delivery.ID, p.Attempt)
+		return nil
+	}
+	if int(delivery.Attempts) < p.Attempt {
+		return fmt.Errorf("webhook delivery %d: attempt %d is not recorded yet", delivery.ID, p.Attempt-1)
+	}
+	sub, err := us.store.GetWebhookSubscription(ctx, delivery.SubscriptionID)
+	if err != nil {
+		return fmt.Errorf("failed to get webhook subscription: %v", err)
+	}
+	if sub.Status != domain.WebhookStatusActive {
+		_, err := us.recordWebhookAttempt(ctx, delivery, sub, 0, fmt.Errorf("subscription is %s", sub.Status))
+		return err
+	}
+
+	code, sendErr := us.webhookClient.Deliver(ctx, sub.Url, sub.Secret, strconv.FormatInt(delivery.ID, 10), delivery.EventType, delivery.Payload)
+	attempts := p.Attempt + 1
+	if sendErr != nil && attempts < webhookMaxAttempts {
+		err := us.taskManager.EnqueueWebhookDeliveryTask(delivery.ID, attempts, webhook.Backoff(attempts))
+		if err != nil && !errors.Is(err, tasks.ErrDuplicateTask) {
+			return fmt.Errorf("failed to enqueue webhook delivery: %v", err)
+		}
+	}
+
+	status, err := us.recordWebhookAttempt(ctx, delivery, sub, code, sendErr)
+	if err != nil {
+		return err
+	}
+	if status == domain.WebhookDeliveryStatusFailed {
+		log.Printf("Webhook delivery %d failed after %d attempts: %v", delivery.ID, attempts, sendErr)
+	}
+	return nil
+}
+
+// recordWebhookAttempt adds an attempt with its response code to the delivery log and returns the new status
+// of the delivery. The failure streak of the subscription is updated in the same transaction, and only if the
+// delivery still expects this attempt, so an attempt that is recorded twice is counted once.
+func (us *UserService) recordWebhookAttempt(ctx context.Context, delivery db.WebhookDelivery, sub db.WebhookSubscription, code int, cause error) (string, error) {
+	attempts := int(delivery.Attempts) + 1
+	arg := db.RecordWebhookDeliveryAttemptParams{
+		ID:            delivery.ID,
+		Attempts:      delivery.Attempts,
+		ResponseCode:  pgtype.Int4{Int32: int32(code), Valid: code != 0},
+		NextAttemptAt: time.Now().Add(webhook.Backoff(attempts)),
+	}
+	if cause != nil {
+		arg.LastError = pgtype.Text{String: cause.Error(), Valid: true}
+	}
+
+	err := us.ExecuteInTransaction(ctx, func(q *db.Queries) error {
+		switch {
+		case sub.Status != domain.WebhookStatusActive:
+			arg.Status = domain.WebhookDeliveryStatusFailed
+		case cause == nil:
+			arg.Status = domain.WebhookDeliveryStatusSucceeded
+			if err := q.RecordWebhookSuccess(ctx, sub.ID); err != nil {
+				return fmt.Errorf("failed to record webhook success: %v", err)
+			}
+		default:
+			updated, err := q.RecordWebhookFailure(ctx, db.RecordWebhookFailureParams{
+				ID:          sub.ID,
+				MaxFailures: webhookMaxConsecutiveFailures,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to record webhook failure: %v", err)
+			}
+			arg.Status = domain.WebhookDeliveryStatusPending
+			if attempts >= webhookMaxAttempts || updated.Status != domain.WebhookStatusActive {
+				arg.Status = domain.WebhookDeliveryStatusFailed
+			}
+		}
+		// No row means the attempt was already recorded, which rolls back the subscription update
+		_, err := q.RecordWebhookDeliveryAttempt(ctx, arg)
+		return err
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("Skipping webhook delivery %d: attempt %d was already recorded", delivery.ID, delivery.Attempts)
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to record webhook delivery attempt: %v", err)
+	}
+	return arg.Status, nil
+}
+
+// HandleWebhookSweepTask is the task handler for tasks.TypeWebhookSweep.
+func (us *UserService) HandleWebhookSweepTask(ctx context.Context, payload []byte) error {
+	enqueued, err := us.SweepWebhookDeliveries(ctx, time.Now().Add(-webhookSweepDelay))
+	if err != nil {
+		return err
+	}
+	if enqueued > 0 {
+		log.Printf("Enqueued %d overdue webhook deliveries", enqueued)
+	}
+	return nil
+}
+
+// SweepWebhookDeliveries enqueues the due attempt of pending deliveries whose next attempt was due before dueBefore,
+// such as a redelivery whose task could not be enqueued. An attempt that is still queued keeps its task,
+// because the task of an attempt has a unique key.
+func (us *UserService) SweepWebhookDeliveries(ctx context.Context, dueBefore time.Time) (int, error) {
+	deliveries, err := us.store.ListOverdueWebhookDeliveries(ctx, db.ListOverdueWebhookDeliveriesParams{
+		DueBefore: dueBefore,
+		Limit:     webhookSweepBatchSize,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list overdue webhook deliveries: %v", err)
+	}
+
+	enqueued := 0
+	for _, delivery := range deliveries {
+		err := us.taskManager.EnqueueWebhookDeliveryTask(delivery.ID, int(delivery.Attempts), 0)
+		if errors.Is(err, tasks.ErrDuplicateTask) {
+			continue
+		}
+		if err != nil {
+			return enqueued, fmt.Errorf("failed to enqueue webhook delivery %d: %v", delivery.ID, err)
+		}
+		enqueued++
+	}
+	return enqueued, nil
+}
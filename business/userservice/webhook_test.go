@@ -0,0 +1,186 @@
+package userservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	"github.com/fadedreams/gofinanceflow/business/tasks"
+	"github.com/fadedreams/gofinanceflow/business/tasks/taskstest"
+	"github.com/fadedreams/gofinanceflow/business/webhook"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventOwners(t *testing.T) {
+	payload, err := json.Marshal(domain.TransferCompletedEvent{FromOwner: "alice", ToOwner: "bob"})
+	require.NoError(t, err)
+	owners, err := eventOwners(domain.EventTransferCompleted, payload)
+	require.NoError(t, err)
+	require.Equal(t, []string{"alice", "bob"}, owners)
+
+	payload, err = json.Marshal(domain.TransferCompletedEvent{FromOwner: "alice", ToOwner: "alice"})
+	require.NoError(t, err)
+	owners, err = eventOwners(domain.EventTransferCompleted, payload)
+	require.NoError(t, err)
+	require.Equal(t, []string{"alice"}, owners)
+
+	_, err = eventOwners("unknown.event", payload)
+	require.Error(t, err)
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	ctx := context.Background()
+	tm := taskstest.New(t)
+	service := NewUserService(testService.connPool, testQueries, tm)
+	service.webhookClient = webhook.NewClient(webhookTimeout, webhook.AllowPrivateAddresses())
+	tasks.Register(tm, tasks.TypeWebhookDelivery, service.HandleWebhookDeliveryTask)
+	account := createTestAccount(t, 1000)
+
+	var failing atomic.Bool
+	received := make(chan webhook.Envelope, 10)
+	var secret string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if failing.Load() || webhook.Verify(secret, r.Header, body, time.Minute, time.Now()) != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var envelope webhook.Envelope
+		if err := json.Unmarshal(body, &envelope); err == nil {
+			received <- envelope
+		}
+	}))
+	defer receiver.Close()
+
+	sub, err := service.CreateWebhookSubscription(ctx, account.Owner, domain.CreateWebhookSubscriptionParams{
+		URL:        receiver.URL,
+		EventTypes: []string{"account.*"},
+	})
+	require.NoError(t, err)
+	secret = sub.Secret
+
+	_, err = service.CreateWebhookSubscription(ctx, account.Owner, domain.CreateWebhookSubscriptionParams{
+		URL:        receiver.URL,
+		EventTypes: []string{"loan.*"},
+	})
+	require.Error(t, err)
+
+	// Fanning out the same event twice delivers it once
+	payload, err := json.Marshal(domain.AccountCreatedEvent{AccountID: account.ID, Owner: account.Owner})
+	require.NoError(t, err)
+	handler := service.WebhookEventHandler(domain.EventAccountCreated)
+	require.NoError(t, handler(ctx, payload))
+	require.NoError(t, handler(ctx, payload))
+	taskstest.RequireDrain(t, tm)
+
+	envelope := <-received
+	require.Equal(t, domain.EventAccountCreated, envelope.Type)
+	require.Len(t, received, 0)
+
+	deliveries, err := service.ListWebhookDeliveries(ctx, sub.ID, account.Owner, false, domain.ListWebhookDeliveriesParams{})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, domain.WebhookDeliveryStatusSucceeded, deliveries[0].Status)
+	require.Equal(t, int32(http.StatusOK), deliveries[0].ResponseCode.Int32)
+
+	// A failing receiver is retried with backoff until the delivery fails for good
+	failing.Store(true)
+	redelivery, err := service.RedeliverWebhook(ctx, sub.ID, deliveries[0].ID, account.Owner, false)
+	require.NoError(t, err)
+	taskstest.RequireDrain(t, tm)
+
+	delivery, err := testQueries.GetWebhookDelivery(ctx, redelivery.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.WebhookDeliveryStatusFailed, delivery.Status)
+	require.Equal(t, int32(webhookMaxAttempts), delivery.Attempts)
+	require.Equal(t, int32(http.StatusServiceUnavailable), delivery.ResponseCode.Int32)
+
+	got, err := service.GetWebhookSubscription(ctx, sub.ID, account.Owner, false)
+	require.NoError(t, err)
+	require.Equal(t, int32(webhookMaxAttempts), got.ConsecutiveFailures)
+
+	// Deliveries are paged newest first
+	page, err := service.ListWebhookDeliveriesPage(ctx, sub.ID, account.Owner, false, domain.ListWebhookDeliveriesParams{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, page.Deliveries, 1)
+	require.Equal(t, redelivery.ID, page.Deliveries[0].ID)
+	page, err = service.ListWebhookDeliveriesPage(ctx, sub.ID, account.Owner, false, domain.ListWebhookDeliveriesParams{Limit: 1, PageToken: page.NextPageToken})
+	require.NoError(t, err)
+	require.Len(t, page.Deliveries, 1)
+	require.Equal(t, deliveries[0].ID, page.Deliveries[0].ID)
+	require.Empty(t, page.NextPageToken)
+}
+
+func TestWebhookDeliveryAttemptsAreRecordedOnce(t *testing.T) {
+	ctx := context.Background()
+	tm := taskstest.New(t)
+	service := NewUserService(testService.connPool, testQueries, tm)
+	service.webhookClient = webhook.NewClient(webhookTimeout, webhook.AllowPrivateAddresses())
+	tasks.Register(tm, tasks.TypeWebhookDelivery, service.HandleWebhookDeliveryTask)
+	account := createTestAccount(t, 1000)
+
+	var requests atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer receiver.Close()
+
+	sub, err := service.CreateWebhookSubscription(ctx, account.Owner, domain.CreateWebhookSubscriptionParams{
+		URL:        receiver.URL,
+		EventTypes: []string{"account.*"},
+	})
+	require.NoError(t, err)
+	payload, err := json.Marshal(domain.AccountCreatedEvent{AccountID: account.ID, Owner: account.Owner})
+	require.NoError(t, err)
+	eventID, body, err := webhook.NewEnvelope(domain.EventAccountCreated, payload, time.Now())
+	require.NoError(t, err)
+	delivery, err := testQueries.CreateWebhookDelivery(ctx, db.CreateWebhookDeliveryParams{
+		SubscriptionID: sub.ID,
+		EventID:        eventID,
+		EventType:      domain.EventAccountCreated,
+		Payload:        body,
+	})
+	require.NoError(t, err)
+
+	// A failed attempt recorded twice, e.g. by a retried task, counts once towards disabling the subscription
+	stored, err := testQueries.GetWebhookSubscription(ctx, sub.ID)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err := service.recordWebhookAttempt(ctx, delivery, stored, http.StatusServiceUnavailable, errors.New("unavailable"))
+		require.NoError(t, err)
+	}
+	recorded, err := testQueries.GetWebhookDelivery(ctx, delivery.ID)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), recorded.Attempts)
+	got, err := service.GetWebhookSubscription(ctx, sub.ID, account.Owner, false)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), got.ConsecutiveFailures)
+
+	// The successor of an attempt that is not recorded yet waits for it
+	err = service.HandleWebhookDeliveryTask(ctx, tasks.WebhookDeliveryPayload{DeliveryID: delivery.ID, Attempt: 2})
+	require.Error(t, err)
+	require.Zero(t, requests.Load())
+
+	// The sweeper enqueues the attempt of an overdue delivery whose task was lost, once
+	enqueued, err := service.SweepWebhookDeliveries(ctx, recorded.NextAttemptAt.Add(time.Second))
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, enqueued, 1)
+	_, err = service.SweepWebhookDeliveries(ctx, recorded.NextAttemptAt.Add(time.Second))
+	require.NoError(t, err)
+	require.Len(t, tm.Enqueued(tasks.TypeWebhookDelivery), enqueued)
+
+	taskstest.RequireDrain(t, tm)
+	delivered, err := testQueries.GetWebhookDelivery(ctx, delivery.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.WebhookDeliveryStatusSucceeded, delivered.Status)
+	require.Equal(t, int32(2), delivered.Attempts)
+	require.Equal(t, int32(1), requests.Load())
+}
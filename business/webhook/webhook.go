@@ -0,0 +1,217 @@
+// Package webhook signs and sends the HTTP requests that notify webhook subscribers of events.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Headers of a webhook request.
+const (
+	HeaderID        = "X-Webhook-Id"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// ErrInvalidSignature is returned by Verify for requests that were not signed with the secret.
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// ErrForbiddenAddress is returned for subscriber URLs that point into a private network.
+var ErrForbiddenAddress = errors.New("address is not allowed")
+
+// Envelope is the JSON body of a webhook request.
+type Envelope struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// NewEnvelope wraps the payload of an event and returns its body along with the event id,
+// which is derived from the event so the same event always gets the same id.
+func NewEnvelope(eventType string, data []byte, createdAt time.Time) (string, []byte, error) {
+	sum := sha256.Sum256(append([]byte(eventType+"\n"), data...))
+	id := "evt_" + hex.EncodeToString(sum[:16])
+	body, err := json.Marshal(Envelope{
+		ID:        id,
+		Type:      eventType,
+		CreatedAt: createdAt.UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return id, body, nil
+}
+
+// Sign returns the signature header value of a body sent at timestamp: the hex encoded
+// HMAC-SHA256 of "<timestamp>.<body>" keyed with the secret.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature headers of a received webhook request and rejects
+// requests whose timestamp is further than tolerance from now, to prevent replays.
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration, now time.Time) error {
+	timestamp, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: missing timestamp", ErrInvalidSignature)
+	}
+	if d := now.Sub(time.Unix(timestamp, 0)); d > tolerance || d < -tolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidSignature)
+	}
+	if !hmac.Equal([]byte(header.Get(HeaderSignature)), []byte(Sign(secret, timestamp, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// GenerateSecret returns a random signing secret.
+func GenerateSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// Backoff returns how long to wait before the next attempt after the given number of failed attempts:
+// 30 seconds doubling with every attempt, at most six hours.
+func Backoff(attempts int) time.Duration {
+	const base, max = 30 * time.Second, 6 * time.Hour
+	d := base
+	for i := 1; i < attempts && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		return max
+	}
+	return d
+}
+
+// Client sends webhook requests.
+type Client struct {
+	http         *http.Client
+	allowPrivate bool
+}
+
+// ClientOption configures a Client.
+type ClientOption func(*Client)
+
+// AllowPrivateAddresses lets the client reach loopback, private and link-local addresses,
+// which it refuses by default so subscribers cannot probe the internal network.
+// It is meant for tests and local development only.
+func AllowPrivateAddresses() ClientOption {
+	return func(c *Client) { c.allowPrivate = true }
+}
+
+// NewClient creates a client whose requests time out after timeout.
+// Redirects are not followed, so a subscriber cannot send the request on to another host.
+func NewClient(timeout time.Duration, opts ...ClientOption) *Client {
+	c := &Client{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	// The address is checked once it has been resolved, so DNS cannot be used to get around it.
+	// Proxies are not used as the client would only see the address of the proxy.
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: c.checkAddress}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	c.http = &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return c
+}
+
+// ValidateURL checks that a subscription URL is an absolute http or https URL
+// whose host is not an address the client refuses to connect to.
+func (c *Client) ValidateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+	if c.allowPrivate {
+		return nil
+	}
+	if u.Hostname() == "localhost" {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, u.Hostname())
+	}
+	if ip, err := netip.ParseAddr(u.Hostname()); err == nil && !isPublic(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, ip)
+	}
+	return nil
+}
+
+// checkAddress is the dialer control function that refuses connections to non-public addresses.
+func (c *Client) checkAddress(network, address string, _ syscall.RawConn) error {
+	if c.allowPrivate {
+		return nil
+	}
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+	}
+	if !isPublic(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// isPublic reports whether ip is a globally routable unicast address.
+func isPublic(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !ip.IsLoopback() && !ip.IsLinkLocalUnicast()
+}
+
+// Deliver POSTs a signed body to url. It returns the HTTP status of the response, 0 if there was none,
+// and an error unless the subscriber answered with a 2xx status.
+func (c *Client) Deliver(ctx context.Context, url, secret, deliveryID, eventType string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "GoFinanceFlow-Webhooks/1.0")
+	req.Header.Set(HeaderID, deliveryID)
+	req.Header.Set(HeaderEvent, eventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(secret, timestamp, body))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("subscriber responded with %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
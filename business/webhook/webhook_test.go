@@ -0,0 +1,136 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeliver(t *testing.T) {
+	const secret = "whsec_test"
+	received := make(chan Envelope, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		if err := Verify(secret, r.Header, body, 5*time.Minute, time.Now()); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		require.Equal(t, "42", r.Header.Get(HeaderID))
+		require.Equal(t, "transfer.completed", r.Header.Get(HeaderEvent))
+
+		var envelope Envelope
+		require.NoError(t, json.Unmarshal(body, &envelope))
+		received <- envelope
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	id, body, err := NewEnvelope("transfer.completed", []byte(`{"transfer_id":7}`), time.Now())
+	require.NoError(t, err)
+
+	client := NewClient(time.Second, AllowPrivateAddresses())
+	code, err := client.Deliver(context.Background(), receiver.URL, secret, "42", "transfer.completed", body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, code)
+
+	envelope := <-received
+	require.Equal(t, id, envelope.ID)
+	require.JSONEq(t, `{"transfer_id":7}`, string(envelope.Data))
+
+	// A subscriber using another secret rejects the request
+	code, err = client.Deliver(context.Background(), receiver.URL, "whsec_other", "42", "transfer.completed", body)
+	require.Error(t, err)
+	require.Equal(t, http.StatusUnauthorized, code)
+
+	// An unreachable subscriber has no status
+	receiver.Close()
+	code, err = client.Deliver(context.Background(), receiver.URL, secret, "42", "transfer.completed", body)
+	require.Error(t, err)
+	require.Zero(t, code)
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":"evt_1"}`)
+	now := time.Unix(1700000000, 0)
+	header := http.Header{}
+	header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	header.Set(HeaderSignature, Sign("secret", now.Unix(), body))
+
+	require.NoError(t, Verify("secret", header, body, time.Minute, now))
+	require.ErrorIs(t, Verify("secret", header, []byte(`{"id":"evt_2"}`), time.Minute, now), ErrInvalidSignature)
+	require.ErrorIs(t, Verify("other", header, body, time.Minute, now), ErrInvalidSignature)
+	require.ErrorIs(t, Verify("secret", header, body, time.Minute, now.Add(2*time.Minute)), ErrInvalidSignature)
+}
+
+func TestNewEnvelopeIsStable(t *testing.T) {
+	id1, _, err := NewEnvelope("user.created", []byte(`{"username":"alice"}`), time.Now())
+	require.NoError(t, err)
+	id2, _, err := NewEnvelope("user.created", []byte(`{"username":"alice"}`), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	id3, _, err := NewEnvelope("user.created", []byte(`{"username":"bob"}`), time.Now())
+	require.NoError(t, err)
+	require.Equal(t, id1, id2)
+	require.NotEqual(t, id1, id3)
+}
+
+func TestBackoff(t *testing.T) {
+	require.Equal(t, 30*time.Second, Backoff(1))
+	require.Equal(t, time.Minute, Backoff(2))
+	require.Equal(t, 4*time.Minute, Backoff(4))
+	require.Equal(t, 6*time.Hour, Backoff(20))
+}
+
+func TestDeliverRefusesPrivateAddresses(t *testing.T) {
+	var hits atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer receiver.Close()
+
+	code, err := NewClient(time.Second).Deliver(context.Background(), receiver.URL, "whsec_test", "42", "transfer.completed", []byte(`{}`))
+	require.ErrorIs(t, err, ErrForbiddenAddress)
+	require.Zero(t, code)
+	require.Zero(t, hits.Load())
+}
+
+func TestDeliverDoesNotFollowRedirects(t *testing.T) {
+	var hits atomic.Int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer target.Close()
+	receiver := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+	defer receiver.Close()
+
+	client := NewClient(time.Second, AllowPrivateAddresses())
+	code, err := client.Deliver(context.Background(), receiver.URL, "whsec_test", "42", "transfer.completed", []byte(`{}`))
+	require.Error(t, err)
+	require.Equal(t, http.StatusTemporaryRedirect, code)
+	require.Zero(t, hits.Load())
+}
+
+func TestValidateURL(t *testing.T) {
+	client := NewClient(time.Second)
+	require.NoError(t, client.ValidateURL("https://example.com/hooks"))
+	require.NoError(t, client.ValidateURL("http://93.184.216.34:9000"))
+	require.Error(t, client.ValidateURL("ftp://example.com"))
+	require.Error(t, client.ValidateURL("/hooks"))
+	require.Error(t, client.ValidateURL("::"))
+	require.ErrorIs(t, client.ValidateURL("http://localhost:9000"), ErrForbiddenAddress)
+	require.ErrorIs(t, client.ValidateURL("http://127.0.0.1:9000"), ErrForbiddenAddress)
+	require.ErrorIs(t, client.ValidateURL("http://10.0.0.8/hooks"), ErrForbiddenAddress)
+	require.ErrorIs(t, client.ValidateURL("http://169.254.169.254/latest/meta-data"), ErrForbiddenAddress)
+	require.ErrorIs(t, client.ValidateURL("http://[::1]:9000"), ErrForbiddenAddress)
+	require.ErrorIs(t, client.ValidateURL("http://[::ffff:192.168.1.1]/"), ErrForbiddenAddress)
+
+	require.NoError(t, NewClient(time.Second, AllowPrivateAddresses()).ValidateURL("http://localhost:9000"))
+}
@@ -1,15 +1,23 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/fadedreams/gofinanceflow/business/batch"
 	"github.com/fadedreams/gofinanceflow/business/domain"
+	"github.com/fadedreams/gofinanceflow/business/export"
+	"github.com/fadedreams/gofinanceflow/business/statement"
 	"github.com/fadedreams/gofinanceflow/business/tasks"
 	"github.com/fadedreams/gofinanceflow/business/userservice" // Import UserService package
+	"github.com/fadedreams/gofinanceflow/foundation/health"
+	"github.com/fadedreams/gofinanceflow/foundation/metrics"
 	"github.com/fadedreams/gofinanceflow/foundation/sdk"
 	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
 
@@ -23,20 +31,25 @@ type Server struct {
 	userService *userservice.UserService
 	router      *echo.Echo
 	taskManager tasks.TaskManager
+	inspector   tasks.Inspector
+	health      *health.Checker
 }
 
 // NewServer creates a new HTTP server and sets up routing.
-func NewServer(store *db.Queries, dbPool *pgxpool.Pool, taskManager tasks.TaskManager) *Server {
-	userService := userservice.NewUserService(dbPool, store) // Create UserService instance
+func NewServer(store *db.Queries, dbPool *pgxpool.Pool, taskManager tasks.TaskManager, checker *health.Checker) *Server {
+	userService := userservice.NewUserService(dbPool, store, taskManager) // Create UserService instance
 
 	server := &Server{
 		userService: userService,
 		router:      echo.New(),
 		taskManager: taskManager,
+		health:      checker,
 	}
+	server.inspector, _ = taskManager.(tasks.Inspector)
 
 	server.router.Use(middleware.Logger())
 	server.router.Use(middleware.Recover())
+	server.router.Use(MetricsMiddleware)
 
 	server.setupRoutes()
 
@@ -44,21 +57,125 @@ func NewServer(store *db.Queries, dbPool *pgxpool.Pool, taskManager tasks.TaskMa
 }
 
 func (s *Server) setupRoutes() {
+	s.router.GET("/healthz", s.healthz)
+	s.router.GET("/readyz", s.readyz)
+	s.router.GET("/metrics", echo.WrapHandler(metrics.Handler()))
 	s.router.POST("/users/login", s.loginUser)
 	s.router.POST("/users", s.createUser)
 	s.router.POST("/users/refresh", s.refreshToken)
-	s.router.POST("/transfers", s.handleFundsTransfer)
+	s.router.POST("/transfers", s.handleFundsTransfer, JWTAuthMiddleware)
+	s.router.GET("/transfers", s.listTransfers, JWTAuthMiddleware)
+	s.router.GET("/transfers/quote", s.quoteTransfer, JWTAuthMiddleware)
+	s.router.GET("/transfers/:id", s.getTransfer, JWTAuthMiddleware)
+	s.router.POST("/transfers/:id/reverse", s.reverseTransfer, JWTAuthMiddleware, AdminRoleCheckMiddleware)
+	s.router.GET("/transactions/search", s.searchTransactions, JWTAuthMiddleware)
 
 	protected := s.router.Group("/users")
 	protected.Use(JWTAuthMiddleware)
 	protected.GET("/:username", s.getUser)
 	protected.PUT("/:username", s.updateUser)
+
+	scheduled := s.router.Group("/scheduled-transfers")
+	scheduled.Use(JWTAuthMiddleware)
+	scheduled.POST("", s.createScheduledTransfer)
+	scheduled.GET("", s.listScheduledTransfers)
+	scheduled.DELETE("/:id", s.cancelScheduledTransfer)
+
+	recurring := s.router.Group("/recurring-transfers")
+	recurring.Use(JWTAuthMiddleware)
+	recurring.POST("", s.createRecurringTransfer)
+	recurring.GET("", s.listRecurringTransfers)
+	recurring.POST("/:id/pause", s.pauseRecurringTransfer)
+	recurring.POST("/:id/resume", s.resumeRecurringTransfer)
+	recurring.DELETE("/:id", s.deleteRecurringTransfer)
+
+	holds := s.router.Group("/holds")
+	holds.Use(JWTAuthMiddleware)
+	holds.POST("", s.createHold)
+	holds.GET("/:id", s.getHold)
+	holds.POST("/:id/capture", s.captureHold)
+	holds.POST("/:id/release", s.releaseHold)
+
+	accounts := s.router.Group("/accounts")
+	accounts.Use(JWTAuthMiddleware)
+	accounts.POST("", s.createAccount)
+	accounts.GET("", s.listAccounts)
+	accounts.POST("/:id/default", s.setDefaultAccount)
+	accounts.POST("/:id/freeze", s.freezeAccount)
+	accounts.POST("/:id/unfreeze", s.unfreezeAccount)
+	accounts.POST("/:id/close", s.closeAccount)
+	accounts.POST("/:id/reopen", s.reopenAccount)
+	accounts.GET("/:id/transactions", s.listAccountTransactions)
+	accounts.GET("/:id/audit-log", s.listAccountAuditLogs)
+	accounts.GET("/:id/statement", s.getAccountStatement)
+	accounts.GET("/:id/export", s.exportTransactions)
+
+	batches := s.router.Group("/transfer-batches")
+	batches.Use(JWTAuthMiddleware)
+	batches.POST("", s.createTransferBatch)
+	batches.GET("", s.listTransferBatches)
+	batches.GET("/:id", s.getTransferBatch)
+
+	webhooks := s.router.Group("/webhooks")
+	webhooks.Use(JWTAuthMiddleware)
+	webhooks.POST("", s.createWebhookSubscription)
+	webhooks.GET("", s.listWebhookSubscriptions)
+	webhooks.GET("/:id", s.getWebhookSubscription)
+	webhooks.DELETE("/:id", s.deleteWebhookSubscription)
+	webhooks.POST("/:id/enable", s.enableWebhookSubscription)
+	webhooks.GET("/:id/deliveries", s.listWebhookDeliveries)
+	webhooks.POST("/:id/deliveries/:delivery_id/redeliver", s.redeliverWebhook)
+
+	exports := s.router.Group("/exports")
+	exports.Use(JWTAuthMiddleware)
+	exports.GET("/:id", s.getTransactionExport)
+	exports.GET("/:id/download", s.downloadTransactionExport)
+
+	admin := s.router.Group("/admin")
+	admin.Use(JWTAuthMiddleware, AdminRoleCheckMiddleware)
+	admin.GET("/accounts/:id/policy", s.getAccountPolicy)
+	admin.PUT("/accounts/:id/policy", s.updateAccountPolicy)
+	admin.GET("/transfer-limits/:scope/:subject", s.getTransferLimit)
+	admin.PUT("/transfer-limits/:scope/:subject", s.updateTransferLimit)
+	admin.DELETE("/transfer-limits/:scope/:subject", s.deleteTransferLimit)
+	admin.POST("/fee-schedules", s.createFeeSchedule)
+	admin.GET("/fee-schedules", s.listFeeSchedules)
+	admin.DELETE("/fee-schedules/:id", s.deactivateFeeSchedule)
+	admin.GET("/interest-rates", s.listInterestRates)
+	admin.PUT("/interest-rates/:product_type", s.updateInterestRate)
+	admin.GET("/queues", s.listQueues)
+	admin.POST("/queues/:queue/pause", s.pauseQueue)
+	admin.POST("/queues/:queue/resume", s.resumeQueue)
+	admin.GET("/queues/:queue/archived", s.listArchivedTasks)
+	admin.GET("/queues/:queue/tasks/:id", s.getTask)
+	admin.POST("/queues/:queue/tasks/:id/retry", s.retryArchivedTask)
+	admin.DELETE("/queues/:queue/tasks/:id", s.deleteArchivedTask)
 }
 
 func (s *Server) Start(address string) error {
 	return s.router.Start(address)
 }
 
+// Shutdown stops accepting connections and waits for the requests in flight until ctx expires.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.router.Shutdown(ctx)
+}
+
+// healthz reports that the process is up; it stays healthy during shutdown.
+func (s *Server) healthz(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]string{"status": health.StatusOK})
+}
+
+// readyz reports whether the dependencies of the service are usable, with the outcome of every check.
+// It fails once shutdown has started so no new traffic is routed to the process.
+func (s *Server) readyz(c echo.Context) error {
+	report := s.health.Check(c.Request().Context())
+	if !report.Ready() {
+		return c.JSON(http.StatusServiceUnavailable, report)
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
 func (s *Server) getUser(c echo.Context) error {
 	username := c.Param("username")
 	user, err := s.userService.GetUser(c.Request().Context(), username)
@@ -88,14 +205,6 @@ func (s *Server) createUser(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to create user: %s", err.Error()))
 	}
 
-	// Ensure the TaskManager is not nil and enqueue the task
-	if s.taskManager != nil {
-		err = s.taskManager.EnqueueEmailDeliveryTask(123, "welcome-template")
-		if err != nil {
-			log.Printf("could not enqueue task: %v", err)
-		}
-	}
-
 	// Create response without hashed password
 	response := domain.CreateUserResponse{
 		Username:          user.Username,
@@ -222,7 +331,37 @@ func AdminRoleCheckMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	}
 }
 
+// MetricsMiddleware records the count and latency of requests by route pattern.
+// Requests that match no route are recorded under an empty route.
+func MetricsMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		err := next(c)
+
+		// Errors are only written to the response by the error handler after the middleware returns
+		code := c.Response().Status
+		if err != nil {
+			var he *echo.HTTPError
+			if errors.As(err, &he) {
+				code = he.Code
+			} else if !c.Response().Committed {
+				code = http.StatusInternalServerError
+			}
+		}
+
+		route, method := c.Path(), c.Request().Method
+		metrics.HTTPRequests.WithLabelValues(method, route, strconv.Itoa(code)).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
 func (s *Server) handleFundsTransfer(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
 	var transferParams domain.HandleFundsTransferParams
 	if err := c.Bind(&transferParams); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request payload")
@@ -232,13 +371,1199 @@ func (s *Server) handleFundsTransfer(c echo.Context) error {
 	if transferParams.FromAccountID == transferParams.ToAccountID {
 		return echo.NewHTTPError(http.StatusBadRequest, "from and to accounts must be different")
 	}
+	if transferParams.Amount <= 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "amount must be positive")
+	}
+	// Money can only be moved out of the caller's own accounts
+	if err := s.userService.CheckAccountOwner(c.Request().Context(), transferParams.FromAccountID, username); err != nil {
+		if errors.Is(err, userservice.ErrAccountNotOwned) {
+			return echo.NewHTTPError(http.StatusForbidden, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
 
 	// Execute the funds transfer within a transaction
-	_, err := s.userService.HandleFundsTransfer(c.Request().Context(), transferParams)
+	_, err = s.userService.HandleFundsTransfer(c.Request().Context(), transferParams)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("failed to process transfer: %v", err))
+		return transferError(err, http.StatusInternalServerError, "failed to process transfer")
 	}
 
 	// Return success response
 	return c.NoContent(http.StatusOK)
 }
+
+// transferError converts a failed transfer into an HTTP error.
+// Broken transfer limits are reported with the limit_exceeded error code.
+func transferError(err error, code int, message string) error {
+	var limitErr *domain.LimitExceededError
+	if errors.As(err, &limitErr) {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   domain.ErrorCodeLimitExceeded,
+			"message": limitErr.Error(),
+			"limit":   limitErr,
+		})
+	}
+	return echo.NewHTTPError(code, fmt.Sprintf("%s: %v", message, err))
+}
+
+// createTransferBatch accepts a CSV or JSON file of transfers, either uploaded as the multipart
+// field "file" or sent as the request body, and executes it in the background.
+// The mode query or form parameter is all_or_nothing (the default) or best_effort.
+func (s *Server) createTransferBatch(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	mode := c.FormValue("mode")
+	if mode == "" {
+		mode = domain.TransferBatchModeAllOrNothing
+	}
+
+	var body io.Reader = c.Request().Body
+	format := batch.FormatOf("", c.Request().Header.Get(echo.HeaderContentType))
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		file, err := fileHeader.Open()
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "failed to read uploaded file")
+		}
+		defer file.Close()
+		body = file
+		format = batch.FormatOf(fileHeader.Filename, fileHeader.Header.Get(echo.HeaderContentType))
+	}
+	if format == "" {
+		return echo.NewHTTPError(http.StatusUnsupportedMediaType, "transfers must be uploaded as csv or json")
+	}
+
+	lines, err := batch.Parse(format, body)
+	if err != nil {
+		return transferBatchError(err)
+	}
+
+	result, err := s.userService.CreateTransferBatch(c.Request().Context(), username, mode, lines)
+	if err != nil {
+		return transferBatchError(err)
+	}
+	return c.JSON(http.StatusAccepted, result)
+}
+
+// transferBatchError converts a rejected transfer batch into an HTTP error.
+// Invalid lines are all reported with the invalid_batch error code.
+func transferBatchError(err error) error {
+	var validationErr *domain.BatchValidationError
+	if errors.As(err, &validationErr) {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":   domain.ErrorCodeInvalidBatch,
+			"message": validationErr.Error(),
+			"lines":   validationErr.Lines,
+		})
+	}
+	return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("failed to create transfer batch: %v", err))
+}
+
+func (s *Server) listTransferBatches(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	params := domain.ListTransferBatchesParams{Limit: 10}
+	if err := c.Bind(&params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request parameters")
+	}
+
+	if offsetPaging(c) {
+		batches, err := s.userService.ListTransferBatches(c.Request().Context(), username, params)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("failed to list transfer batches: %v", err))
+		}
+		return c.JSON(http.StatusOK, batches)
+	}
+
+	page, err := s.userService.ListTransferBatchesPage(c.Request().Context(), username, params)
+	if err != nil {
+		return pageError(err, "failed to list transfer batches")
+	}
+	return c.JSON(http.StatusOK, page)
+}
+
+func (s *Server) getTransferBatch(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transfer batch id")
+	}
+
+	result, err := s.userService.GetTransferBatch(c.Request().Context(), id, username, isAdmin(c))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+func (s *Server) createWebhookSubscription(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	var params domain.CreateWebhookSubscriptionParams
+	if err := c.Bind(&params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	sub, err := s.userService.CreateWebhookSubscription(c.Request().Context(), username, params)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("failed to create webhook subscription: %v", err))
+	}
+	return c.JSON(http.StatusCreated, sub)
+}
+
+func (s *Server) listWebhookSubscriptions(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	subs, err := s.userService.ListWebhookSubscriptions(c.Request().Context(), username)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, subs)
+}
+
+func (s *Server) getWebhookSubscription(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid webhook subscription id")
+	}
+
+	sub, err := s.userService.GetWebhookSubscription(c.Request().Context(), id, username, isAdmin(c))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	return c.JSON(http.StatusOK, sub)
+}
+
+func (s *Server) deleteWebhookSubscription(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid webhook subscription id")
+	}
+
+	if err := s.userService.DeleteWebhookSubscription(c.Request().Context(), id, username, isAdmin(c)); err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (s *Server) enableWebhookSubscription(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid webhook subscription id")
+	}
+
+	sub, err := s.userService.EnableWebhookSubscription(c.Request().Context(), id, username, isAdmin(c))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	return c.JSON(http.StatusOK, sub)
+}
+
+func (s *Server) listWebhookDeliveries(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid webhook subscription id")
+	}
+
+	params := domain.ListWebhookDeliveriesParams{Limit: 20}
+	if err := c.Bind(&params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request parameters")
+	}
+
+	if offsetPaging(c) {
+		deliveries, err := s.userService.ListWebhookDeliveries(c.Request().Context(), id, username, isAdmin(c), params)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return c.JSON(http.StatusOK, deliveries)
+	}
+
+	page, err := s.userService.ListWebhookDeliveriesPage(c.Request().Context(), id, username, isAdmin(c), params)
+	if errors.Is(err, userservice.ErrInvalidPage) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	return c.JSON(http.StatusOK, page)
+}
+
+func (s *Server) redeliverWebhook(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid webhook subscription id")
+	}
+	deliveryID, err := strconv.ParseInt(c.Param("delivery_id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid webhook delivery id")
+	}
+
+	delivery, err := s.userService.RedeliverWebhook(c.Request().Context(), id, deliveryID, username, isAdmin(c))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("failed to redeliver webhook: %v", err))
+	}
+	return c.JSON(http.StatusAccepted, delivery)
+}
+
+func (s *Server) quoteTransfer(c echo.Context) error {
+	var params domain.QuoteTransferParams
+	if err := c.Bind(&params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request parameters")
+	}
+
+	quote, err := s.userService.QuoteTransfer(c.Request().Context(), params)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("failed to quote transfer: %v", err))
+	}
+	return c.JSON(http.StatusOK, quote)
+}
+
+func (s *Server) getTransfer(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transfer id")
+	}
+
+	transfer, err := s.userService.GetTransfer(c.Request().Context(), id, username, isAdmin(c))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Transfer %d not found", id))
+	}
+	return c.JSON(http.StatusOK, transfer)
+}
+
+func (s *Server) listTransfers(c echo.Context) error {
+	params := domain.ListTransfersParams{Limit: 10}
+	if err := c.Bind(&params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request parameters")
+	}
+
+	// Validate list parameters
+	if params.AccountID <= 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "account_id is required")
+	}
+	if params.Status != "" && !domain.IsValidTransferStatus(params.Status) {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("unknown transfer status %q", params.Status))
+	}
+
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	// Offset paging is kept for existing clients, everyone else pages with next_page_token
+	if offsetPaging(c) {
+		transfers, err := s.userService.ListTransfers(c.Request().Context(), username, isAdmin(c), params)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return c.JSON(http.StatusOK, transfers)
+	}
+
+	page, err := s.userService.ListTransfersPage(c.Request().Context(), username, isAdmin(c), params)
+	if errors.Is(err, userservice.ErrInvalidPage) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	return c.JSON(http.StatusOK, page)
+}
+
+// offsetPaging reports whether a list request pages with offset instead of page_token.
+func offsetPaging(c echo.Context) bool {
+	return c.QueryParam("offset") != "" && c.QueryParam("page_token") == ""
+}
+
+// pageError converts a failed page request into an HTTP error.
+func pageError(err error, message string) error {
+	if errors.Is(err, userservice.ErrInvalidPage) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("%s: %v", message, err))
+}
+
+// searchTransactions searches transfers and account transactions. The optional from and to
+// query parameters are inclusive dates like 2006-01-02. Auditors and admins search every
+// account, everyone else only their own accounts.
+func (s *Server) searchTransactions(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	params := domain.SearchTransactionsParams{Limit: 20}
+	if err := c.Bind(&params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request parameters")
+	}
+	if from := c.QueryParam("from"); from != "" {
+		if params.From, err = time.Parse(time.DateOnly, from); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "from must be a date like 2006-01-02")
+		}
+	}
+	if to := c.QueryParam("to"); to != "" {
+		if params.To, err = time.Parse(time.DateOnly, to); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "to must be a date like 2006-01-02")
+		}
+		params.To = params.To.AddDate(0, 0, 1)
+	}
+
+	role, _ := c.Get("role").(string)
+	page, err := s.userService.SearchTransactions(c.Request().Context(), username, role, params)
+	if err != nil {
+		if errors.Is(err, userservice.ErrInvalidPage) || errors.Is(err, userservice.ErrInvalidSearch) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, page)
+}
+
+func (s *Server) reverseTransfer(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transfer id")
+	}
+
+	result, err := s.userService.ReverseTransfer(c.Request().Context(), id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("failed to reverse transfer: %v", err))
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// currentUsername returns the username stored in the context by JWTAuthMiddleware.
+func currentUsername(c echo.Context) (string, error) {
+	username, ok := c.Get("username").(string)
+	if !ok || username == "" {
+		return "", echo.NewHTTPError(http.StatusUnauthorized, "missing or invalid token")
+	}
+	return username, nil
+}
+
+func (s *Server) createScheduledTransfer(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	var params domain.CreateScheduledTransferParams
+	if err := c.Bind(&params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	scheduled, err := s.userService.CreateScheduledTransfer(c.Request().Context(), username, params)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("failed to schedule transfer: %v", err))
+	}
+	return c.JSON(http.StatusCreated, scheduled)
+}
+
+func (s *Server) listScheduledTransfers(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	params := domain.ListScheduledTransfersParams{Limit: 10}
+	if err := c.Bind(&params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request parameters")
+	}
+
+	if offsetPaging(c) {
+		scheduled, err := s.userService.ListScheduledTransfers(c.Request().Context(), username, params)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("failed to list scheduled transfers: %v", err))
+		}
+		return c.JSON(http.StatusOK, scheduled)
+	}
+
+	page, err := s.userService.ListScheduledTransfersPage(c.Request().Context(), username, params)
+	if err != nil {
+		return pageError(err, "failed to list scheduled transfers")
+	}
+	return c.JSON(http.StatusOK, page)
+}
+
+func (s *Server) cancelScheduledTransfer(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid scheduled transfer id")
+	}
+
+	scheduled, err := s.userService.CancelScheduledTransfer(c.Request().Context(), username, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	return c.JSON(http.StatusOK, scheduled)
+}
+
+func (s *Server) createRecurringTransfer(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	var params domain.CreateRecurringTransferParams
+	if err := c.Bind(&params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	recurring, err := s.userService.CreateRecurringTransfer(c.Request().Context(), username, params)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("failed to create recurring transfer: %v", err))
+	}
+	return c.JSON(http.StatusCreated, recurring)
+}
+
+func (s *Server) listRecurringTransfers(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	params := domain.ListRecurringTransfersParams{Limit: 10}
+	if err := c.Bind(&params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request parameters")
+	}
+
+	if offsetPaging(c) {
+		recurring, err := s.userService.ListRecurringTransfers(c.Request().Context(), username, params)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("failed to list recurring transfers: %v", err))
+		}
+		return c.JSON(http.StatusOK, recurring)
+	}
+
+	page, err := s.userService.ListRecurringTransfersPage(c.Request().Context(), username, params)
+	if err != nil {
+		return pageError(err, "failed to list recurring transfers")
+	}
+	return c.JSON(http.StatusOK, page)
+}
+
+func (s *Server) pauseRecurringTransfer(c echo.Context) error {
+	return s.changeRecurringTransfer(c, s.userService.PauseRecurringTransfer)
+}
+
+func (s *Server) resumeRecurringTransfer(c echo.Context) error {
+	return s.changeRecurringTransfer(c, s.userService.ResumeRecurringTransfer)
+}
+
+func (s *Server) deleteRecurringTransfer(c echo.Context) error {
+	return s.changeRecurringTransfer(c, s.userService.DeleteRecurringTransfer)
+}
+
+func (s *Server) changeRecurringTransfer(c echo.Context, change func(ctx context.Context, owner string, id int64) (*db.RecurringTransfer, error)) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid recurring transfer id")
+	}
+
+	recurring, err := change(c.Request().Context(), username, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	return c.JSON(http.StatusOK, recurring)
+}
+
+func (s *Server) createHold(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	var params domain.CreateHoldParams
+	if err := c.Bind(&params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	hold, err := s.userService.CreateHold(c.Request().Context(), username, params)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("failed to create hold: %v", err))
+	}
+	return c.JSON(http.StatusCreated, hold)
+}
+
+func (s *Server) getHold(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid hold id")
+	}
+
+	hold, err := s.userService.GetHold(c.Request().Context(), username, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	return c.JSON(http.StatusOK, hold)
+}
+
+func (s *Server) captureHold(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid hold id")
+	}
+
+	var params domain.CaptureHoldParams
+	if err := c.Bind(&params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	result, err := s.userService.CaptureHold(c.Request().Context(), username, id, params)
+	if err != nil {
+		return transferError(err, http.StatusConflict, "failed to capture hold")
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+func (s *Server) releaseHold(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid hold id")
+	}
+
+	hold, err := s.userService.ReleaseHold(c.Request().Context(), username, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("failed to release hold: %v", err))
+	}
+	return c.JSON(http.StatusOK, hold)
+}
+
+func (s *Server) getAccountPolicy(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id")
+	}
+
+	policy, err := s.userService.GetAccountPolicy(c.Request().Context(), id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	return c.JSON(http.StatusOK, policy)
+}
+
+func (s *Server) updateAccountPolicy(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id")
+	}
+
+	var params domain.UpdateAccountPolicyParams
+	if err := c.Bind(&params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	policy, err := s.userService.UpdateAccountPolicy(c.Request().Context(), id, username, params)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("failed to update account policy: %v", err))
+	}
+	return c.JSON(http.StatusOK, policy)
+}
+
+func (s *Server) getTransferLimit(c echo.Context) error {
+	limit, err := s.userService.GetTransferLimit(c.Request().Context(), c.Param("scope"), c.Param("subject"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("no transfer limit for %s %s", c.Param("scope"), c.Param("subject")))
+	}
+	return c.JSON(http.StatusOK, limit)
+}
+
+func (s *Server) updateTransferLimit(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	var params domain.UpdateTransferLimitParams
+	if err := c.Bind(&params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	limit, err := s.userService.UpdateTransferLimit(c.Request().Context(), c.Param("scope"), c.Param("subject"), username, params)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("failed to update transfer limit: %v", err))
+	}
+	return c.JSON(http.StatusOK, limit)
+}
+
+func (s *Server) deleteTransferLimit(c echo.Context) error {
+	if err := s.userService.DeleteTransferLimit(c.Request().Context(), c.Param("scope"), c.Param("subject")); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("failed to delete transfer limit: %v", err))
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (s *Server) createAccount(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	var params domain.CreateAccountParams
+	if err := c.Bind(&params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	account, err := s.userService.CreateAccount(c.Request().Context(), username, params)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("failed to create account: %v", err))
+	}
+	return c.JSON(http.StatusCreated, account)
+}
+
+func (s *Server) listAccounts(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	params := domain.ListAccountsParams{Limit: 10}
+	if err := c.Bind(&params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request parameters")
+	}
+
+	if offsetPaging(c) {
+		accounts, err := s.userService.ListAccounts(c.Request().Context(), username, params)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("failed to list accounts: %v", err))
+		}
+		return c.JSON(http.StatusOK, accounts)
+	}
+
+	page, err := s.userService.ListAccountsPage(c.Request().Context(), username, params)
+	if err != nil {
+		return pageError(err, "failed to list accounts")
+	}
+	return c.JSON(http.StatusOK, page)
+}
+
+func (s *Server) listAccountTransactions(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id")
+	}
+
+	params := domain.ListAccountTransactionsParams{Limit: 10}
+	if err := c.Bind(&params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request parameters")
+	}
+
+	page, err := s.userService.ListAccountTransactionsPage(c.Request().Context(), id, username, isAdmin(c), params)
+	if errors.Is(err, userservice.ErrInvalidPage) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	return c.JSON(http.StatusOK, page)
+}
+
+func (s *Server) setDefaultAccount(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id")
+	}
+
+	account, err := s.userService.SetDefaultAccount(c.Request().Context(), username, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("failed to set default account: %v", err))
+	}
+	return c.JSON(http.StatusOK, account)
+}
+
+// isAdmin reports whether the authenticated user has the admin role.
+func isAdmin(c echo.Context) bool {
+	role, _ := c.Get("role").(string)
+	return role == "admin"
+}
+
+func (s *Server) freezeAccount(c echo.Context) error {
+	return s.changeAccountStatus(c, domain.AccountActionFreeze)
+}
+
+func (s *Server) unfreezeAccount(c echo.Context) error {
+	return s.changeAccountStatus(c, domain.AccountActionUnfreeze)
+}
+
+func (s *Server) closeAccount(c echo.Context) error {
+	return s.changeAccountStatus(c, domain.AccountActionClose)
+}
+
+func (s *Server) reopenAccount(c echo.Context) error {
+	return s.changeAccountStatus(c, domain.AccountActionReopen)
+}
+
+func (s *Server) changeAccountStatus(c echo.Context, action string) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id")
+	}
+
+	var params domain.ChangeAccountStatusParams
+	if err := c.Bind(&params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	account, err := s.userService.ChangeAccountStatus(c.Request().Context(), id, username, isAdmin(c), action, params)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("failed to %s account: %v", action, err))
+	}
+	return c.JSON(http.StatusOK, account)
+}
+
+func (s *Server) listAccountAuditLogs(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id")
+	}
+
+	params := domain.ListAccountAuditLogsParams{Limit: 10}
+	if err := c.Bind(&params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request parameters")
+	}
+
+	if offsetPaging(c) {
+		logs, err := s.userService.ListAccountAuditLogs(c.Request().Context(), id, username, isAdmin(c), params)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return c.JSON(http.StatusOK, logs)
+	}
+
+	page, err := s.userService.ListAccountAuditLogsPage(c.Request().Context(), id, username, isAdmin(c), params)
+	if errors.Is(err, userservice.ErrInvalidPage) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	return c.JSON(http.StatusOK, page)
+}
+
+func (s *Server) createFeeSchedule(c echo.Context) error {
+	var params domain.CreateFeeScheduleParams
+	if err := c.Bind(&params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	schedule, err := s.userService.CreateFeeSchedule(c.Request().Context(), params)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("failed to create fee schedule: %v", err))
+	}
+	return c.JSON(http.StatusCreated, schedule)
+}
+
+func (s *Server) listFeeSchedules(c echo.Context) error {
+	params := domain.ListFeeSchedulesParams{Limit: 10}
+	if err := c.Bind(&params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request parameters")
+	}
+
+	if offsetPaging(c) {
+		schedules, err := s.userService.ListFeeSchedules(c.Request().Context(), params)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("failed to list fee schedules: %v", err))
+		}
+		return c.JSON(http.StatusOK, schedules)
+	}
+
+	page, err := s.userService.ListFeeSchedulesPage(c.Request().Context(), params)
+	if err != nil {
+		return pageError(err, "failed to list fee schedules")
+	}
+	return c.JSON(http.StatusOK, page)
+}
+
+func (s *Server) deactivateFeeSchedule(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid fee schedule id")
+	}
+
+	schedule, err := s.userService.DeactivateFeeSchedule(c.Request().Context(), id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Fee schedule %d not found", id))
+	}
+	return c.JSON(http.StatusOK, schedule)
+}
+
+func (s *Server) listInterestRates(c echo.Context) error {
+	rates, err := s.userService.ListInterestRates(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("failed to list interest rates: %v", err))
+	}
+	return c.JSON(http.StatusOK, rates)
+}
+
+func (s *Server) updateInterestRate(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	var params domain.UpdateInterestRateParams
+	if err := c.Bind(&params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request payload")
+	}
+
+	rate, err := s.userService.UpdateInterestRate(c.Request().Context(), c.Param("product_type"), username, params)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("failed to update interest rate: %v", err))
+	}
+	return c.JSON(http.StatusOK, rate)
+}
+
+// taskInspector returns the inspector of the task manager, or an error if its backend has none.
+func (s *Server) taskInspector() (tasks.Inspector, error) {
+	if s.inspector == nil {
+		return nil, echo.NewHTTPError(http.StatusServiceUnavailable, "task queues cannot be inspected")
+	}
+	return s.inspector, nil
+}
+
+// taskInspectorError maps the errors of a task inspector to HTTP errors.
+func taskInspectorError(err error) error {
+	if errors.Is(err, tasks.ErrQueueNotFound) || errors.Is(err, tasks.ErrTaskNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("failed to inspect task queues: %v", err))
+}
+
+func (s *Server) listQueues(c echo.Context) error {
+	inspector, err := s.taskInspector()
+	if err != nil {
+		return err
+	}
+	queues, err := inspector.Queues()
+	if err != nil {
+		return taskInspectorError(err)
+	}
+	return c.JSON(http.StatusOK, queues)
+}
+
+func (s *Server) pauseQueue(c echo.Context) error {
+	return s.changeQueue(c, tasks.Inspector.PauseQueue)
+}
+
+func (s *Server) resumeQueue(c echo.Context) error {
+	return s.changeQueue(c, tasks.Inspector.ResumeQueue)
+}
+
+func (s *Server) changeQueue(c echo.Context, change func(i tasks.Inspector, queue string) error) error {
+	inspector, err := s.taskInspector()
+	if err != nil {
+		return err
+	}
+	if err := change(inspector, c.Param("queue")); err != nil {
+		return taskInspectorError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// listArchivedTasks lists the tasks of a queue that failed for good, ?page=1&page_size=20.
+func (s *Server) listArchivedTasks(c echo.Context) error {
+	inspector, err := s.taskInspector()
+	if err != nil {
+		return err
+	}
+	params := domain.ListArchivedTasksParams{Page: 1, PageSize: 20}
+	if err := c.Bind(&params); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request parameters")
+	}
+	if params.Page < 1 || params.PageSize < 1 || params.PageSize > 100 {
+		return echo.NewHTTPError(http.StatusBadRequest, "page must be positive and page_size between 1 and 100")
+	}
+
+	archived, err := inspector.ListArchivedTasks(c.Param("queue"), params.Page, params.PageSize)
+	if err != nil {
+		return taskInspectorError(err)
+	}
+	return c.JSON(http.StatusOK, archived)
+}
+
+func (s *Server) getTask(c echo.Context) error {
+	inspector, err := s.taskInspector()
+	if err != nil {
+		return err
+	}
+	task, err := inspector.GetTaskInfo(c.Param("queue"), c.Param("id"))
+	if err != nil {
+		return taskInspectorError(err)
+	}
+	return c.JSON(http.StatusOK, task)
+}
+
+func (s *Server) retryArchivedTask(c echo.Context) error {
+	inspector, err := s.taskInspector()
+	if err != nil {
+		return err
+	}
+	if err := inspector.RetryArchivedTask(c.Param("queue"), c.Param("id")); err != nil {
+		return taskInspectorError(err)
+	}
+	return c.NoContent(http.StatusAccepted)
+}
+
+func (s *Server) deleteArchivedTask(c echo.Context) error {
+	inspector, err := s.taskInspector()
+	if err != nil {
+		return err
+	}
+	if err := inspector.DeleteArchivedTask(c.Param("queue"), c.Param("id")); err != nil {
+		return taskInspectorError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// getAccountStatement returns the statement of an account for the inclusive date range
+// ?from=YYYY-MM-DD&to=YYYY-MM-DD as JSON, or as CSV or PDF with ?format=csv|pdf.
+func (s *Server) getAccountStatement(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id")
+	}
+	from, to, err := dateRange(c)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := s.userService.GetAccountStatement(c.Request().Context(), id, username, isAdmin(c), from, to)
+	switch {
+	case errors.Is(err, userservice.ErrInvalidStatement):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, userservice.ErrAccountNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case err != nil:
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("failed to build statement: %v", err))
+	}
+
+	filename := fmt.Sprintf("statement-%d-%s-%s", id, c.QueryParam("from"), c.QueryParam("to"))
+	switch c.QueryParam("format") {
+	case "", "json":
+		return c.JSON(http.StatusOK, stmt)
+	case "csv":
+		c.Response().Header().Set(echo.HeaderContentType, "text/csv")
+		c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename+".csv"))
+		c.Response().WriteHeader(http.StatusOK)
+		return statement.WriteCSV(c.Response(), stmt)
+	case "pdf":
+		c.Response().Header().Set(echo.HeaderContentType, "application/pdf")
+		c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename+".pdf"))
+		c.Response().WriteHeader(http.StatusOK)
+		return statement.WritePDF(c.Response(), stmt)
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("unknown statement format %q", c.QueryParam("format")))
+	}
+}
+
+// dateRange parses the inclusive from and to dates of the query string into the half-open range [from, to).
+func dateRange(c echo.Context) (time.Time, time.Time, error) {
+	from, err := time.Parse(time.DateOnly, c.QueryParam("from"))
+	if err != nil {
+		return time.Time{}, time.Time{}, echo.NewHTTPError(http.StatusBadRequest, "from must be a date like 2006-01-02")
+	}
+	to, err := time.Parse(time.DateOnly, c.QueryParam("to"))
+	if err != nil {
+		return time.Time{}, time.Time{}, echo.NewHTTPError(http.StatusBadRequest, "to must be a date like 2006-01-02")
+	}
+	return from, to.AddDate(0, 0, 1), nil
+}
+
+// exportTransactions streams the account transactions of a date range as csv, ofx or camt053.
+// Large exports, or exports requested with async=true, are generated in the background
+// and answered with 202 and the link the file can be downloaded from.
+func (s *Server) exportTransactions(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid account id")
+	}
+	from, to, err := dateRange(c)
+	if err != nil {
+		return err
+	}
+	format := c.QueryParam("format")
+	if format == "" {
+		format = export.FormatCSV
+	}
+	arg := domain.ExportTransactionsParams{AccountID: id, Format: format, From: from, To: to}
+
+	ctx := c.Request().Context()
+	async := c.QueryParam("async") == "true"
+	if !async {
+		async, err = s.userService.IsLargeTransactionExport(ctx, username, isAdmin(c), arg)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("failed to export transactions: %v", err))
+		}
+	}
+
+	if async {
+		exp, err := s.userService.StartTransactionExport(ctx, username, isAdmin(c), arg)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("failed to export transactions: %v", err))
+		}
+		return c.JSON(http.StatusAccepted, transactionExportResponse(exp))
+	}
+
+	filename := fmt.Sprintf("transactions-%d-%s-%s%s", id, c.QueryParam("from"), c.QueryParam("to"), export.FileExtension(format))
+	c.Response().Header().Set(echo.HeaderContentType, export.ContentType(format))
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	c.Response().WriteHeader(http.StatusOK)
+	return s.userService.ExportTransactions(ctx, username, isAdmin(c), arg, c.Response())
+}
+
+func (s *Server) getTransactionExport(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid export id")
+	}
+
+	exp, err := s.userService.GetTransactionExport(c.Request().Context(), id, username, isAdmin(c))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	return c.JSON(http.StatusOK, transactionExportResponse(exp))
+}
+
+func (s *Server) downloadTransactionExport(c echo.Context) error {
+	username, err := currentUsername(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid export id")
+	}
+
+	ctx := c.Request().Context()
+	exp, err := s.userService.GetTransactionExport(ctx, id, username, isAdmin(c))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	if exp.Status != domain.TransactionExportStatusCompleted {
+		return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("transaction export %d is %s", exp.ID, exp.Status))
+	}
+	if !exp.FileOid.Valid {
+		return echo.NewHTTPError(http.StatusGone, fmt.Sprintf("the file of transaction export %d has expired", exp.ID))
+	}
+
+	filename := fmt.Sprintf("transactions-%d-%d%s", exp.AccountID, exp.ID, export.FileExtension(exp.Format))
+	c.Response().Header().Set(echo.HeaderContentType, export.ContentType(exp.Format))
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	if exp.FileSize.Valid {
+		c.Response().Header().Set(echo.HeaderContentLength, strconv.FormatInt(exp.FileSize.Int64, 10))
+	}
+	c.Response().WriteHeader(http.StatusOK)
+	return s.userService.DownloadTransactionExport(ctx, exp, c.Response())
+}
+
+// transactionExportResponse adds the link the file of an export can be downloaded from once completed.
+func transactionExportResponse(exp db.TransactionExport) domain.TransactionExportResponse {
+	return domain.TransactionExportResponse{
+		TransactionExport: exp,
+		DownloadURL:       fmt.Sprintf("/exports/%d/download", exp.ID),
+	}
+}
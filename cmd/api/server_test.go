@@ -0,0 +1,173 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	"github.com/fadedreams/gofinanceflow/business/tasks/taskstest"
+	"github.com/fadedreams/gofinanceflow/foundation/sdk"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+// relayOutbox publishes every committed outbox event to the server's task manager.
+func relayOutbox(t *testing.T, server *Server) {
+	for {
+		n, err := server.userService.RelayOutbox(context.Background())
+		require.NoError(t, err)
+		if n == 0 {
+			return
+		}
+	}
+}
+
+func TestCreateUser(t *testing.T) {
+	tm := taskstest.New(t)
+	server := NewServer(testQueries, testPool, tm, nil)
+
+	// Publish the events of earlier tests first, so only the new user's event is counted below
+	relayOutbox(t, server)
+	published := len(tm.Enqueued(domain.EventUserCreated))
+
+	username := sdk.RandomOwner()
+	body, err := json.Marshal(map[string]string{
+		"username":        username,
+		"hashed_password": "secret",
+		"full_name":       sdk.RandomOwner(),
+		"email":           sdk.RandomEmail(),
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var response domain.CreateUserResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.Equal(t, username, response.Username)
+	require.NotContains(t, rec.Body.String(), "hashed_password")
+
+	// The user.created event is published once the outbox is relayed
+	relayOutbox(t, server)
+	enqueued := taskstest.RequireEnqueued(t, tm, domain.EventUserCreated, published+1)
+	event := taskstest.RequirePayload[domain.UserCreatedEvent](t, enqueued[len(enqueued)-1])
+	require.Equal(t, username, event.Username)
+}
+
+func TestCreateUserRejectsInvalidPayload(t *testing.T) {
+	tm := taskstest.New(t)
+	server := NewServer(testQueries, testPool, tm, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader("{"))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Empty(t, tm.Enqueued())
+}
+
+func createTestAccount(t *testing.T, balance int64) db.Account {
+	user, err := testQueries.CreateUser(context.Background(), db.CreateUserParams{
+		Username:       sdk.RandomOwner(),
+		HashedPassword: "secret",
+		FullName:       sdk.RandomOwner(),
+		Email:          sdk.RandomEmail(),
+	})
+	require.NoError(t, err)
+
+	account, err := testQueries.CreateAccount(context.Background(), db.CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  balance,
+		Currency: sdk.USD,
+	})
+	require.NoError(t, err)
+	return account
+}
+
+// postTransfer posts a transfer, authenticated as username unless it is empty.
+func postTransfer(t *testing.T, server *Server, username string, params domain.HandleFundsTransferParams) *httptest.ResponseRecorder {
+	body, err := json.Marshal(params)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/transfers", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	if username != "" {
+		token, err := sdk.GenerateJWTToken(username, "user")
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCreateTransfer(t *testing.T) {
+	server := NewServer(testQueries, testPool, taskstest.New(t), nil)
+	owner := createTestAccount(t, 1000)
+	other := createTestAccount(t, 1000)
+
+	tests := []struct {
+		name     string
+		username string
+		params   domain.HandleFundsTransferParams
+		code     int
+	}{
+		{"unauthenticated", "", domain.HandleFundsTransferParams{FromAccountID: owner.ID, ToAccountID: other.ID, Amount: 100}, http.StatusUnauthorized},
+		{"other user's account", other.Owner, domain.HandleFundsTransferParams{FromAccountID: owner.ID, ToAccountID: other.ID, Amount: 100}, http.StatusForbidden},
+		{"negative amount", owner.Owner, domain.HandleFundsTransferParams{FromAccountID: owner.ID, ToAccountID: other.ID, Amount: -100}, http.StatusBadRequest},
+		{"own account", owner.Owner, domain.HandleFundsTransferParams{FromAccountID: owner.ID, ToAccountID: other.ID, Amount: 100}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := postTransfer(t, server, tt.username, tt.params)
+			require.Equal(t, tt.code, rec.Code, rec.Body.String())
+		})
+	}
+
+	// Only the owner's transfer moved money
+	account, err := testQueries.GetAccount(context.Background(), owner.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(900), account.Balance)
+	account, err = testQueries.GetAccount(context.Background(), other.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1100), account.Balance)
+}
+
+func TestGetAccountStatement(t *testing.T) {
+	server := NewServer(testQueries, testPool, taskstest.New(t), nil)
+	owner := createTestAccount(t, 1000)
+	other := createTestAccount(t, 1000)
+
+	tests := []struct {
+		name      string
+		username  string
+		accountID int64
+		query     string
+		code      int
+	}{
+		{"own account", owner.Owner, owner.ID, "from=2024-01-01&to=2024-01-31", http.StatusOK},
+		{"other user's account", other.Owner, owner.ID, "from=2024-01-01&to=2024-01-31", http.StatusNotFound},
+		{"missing account", owner.Owner, -1, "from=2024-01-01&to=2024-01-31", http.StatusNotFound},
+		{"period too long", owner.Owner, owner.ID, "from=2022-01-01&to=2024-01-31", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/accounts/%d/statement?%s", tt.accountID, tt.query), nil)
+			token, err := sdk.GenerateJWTToken(tt.username, "user")
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			server.router.ServeHTTP(rec, req)
+			require.Equal(t, tt.code, rec.Code, rec.Body.String())
+		})
+	}
+}
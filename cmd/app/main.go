@@ -2,23 +2,34 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	"github.com/fadedreams/gofinanceflow/business/email"
 	"github.com/fadedreams/gofinanceflow/business/tasks"
+	"github.com/fadedreams/gofinanceflow/business/userservice"
 	"github.com/fadedreams/gofinanceflow/cmd/api"
 	"github.com/fadedreams/gofinanceflow/cmd/grpc_api"
+	"github.com/fadedreams/gofinanceflow/foundation/health"
+	"github.com/fadedreams/gofinanceflow/foundation/lifecycle"
+	"github.com/fadedreams/gofinanceflow/foundation/metrics"
 	sdk "github.com/fadedreams/gofinanceflow/foundation/sdk"
+	"github.com/fadedreams/gofinanceflow/infrastructure/db/migration"
 	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
 	"github.com/fadedreams/gofinanceflow/infrastructure/pb"
 	"github.com/fadedreams/gosafecircuit"
 	"github.com/hibiken/asynq"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
-	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 	"log"
 	"net"
+	"net/http"
 	"os/signal"
 	"strings"
 	"syscall"
@@ -65,18 +76,15 @@ func main() {
 		log.Fatalf("cannot load config: %v\n", err)
 	}
 
-	// redis
-	redis := asynq.RedisClientOpt{
-		Addr: config.RedisAddress,
+	taskManager, err := newTaskManager(&config)
+	if err != nil {
+		log.Fatalf("cannot create task manager: %v\n", err)
 	}
 
-	taskManager := tasks.NewTaskManager(redis)
-
-	// Enqueue an email delivery task
-	// err = taskManager.EnqueueEmailDeliveryTask(123, "welcome-template")
-	// if err != nil {
-	// 	log.Fatalf("could not enqueue task: %v", err)
-	// }
+	mailer, err := newMailer(&config)
+	if err != nil {
+		log.Fatalf("cannot create mailer: %v\n", err)
+	}
 
 	// Run the task manager to process tasks
 	// if err := taskManager.Run(); err != nil {
@@ -99,7 +107,6 @@ func main() {
 	if err != nil {
 		log.Fatalf("Unable to connect to database: %v\n", err)
 	}
-	defer pool.Close()
 
 	// Create a new Queries instance
 	queries := db.New(pool)
@@ -119,97 +126,261 @@ func main() {
 		opt(&options)
 	}
 
-	// Use an errgroup to manage multiple concurrent tasks
-	var g errgroup.Group
-	// Start task manager to process tasks
-	g.Go(func() error {
-		return runTaskManager(ctx, options.logger, options.taskManager)
-	})
+	// Deliver emails and execute scheduled transfers on the task worker
+	deliverer := email.NewDeliverer(queries, mailer)
+	tasks.Register(taskManager, tasks.TypeEmailDelivery, deliverer.HandleEmailDeliveryTask)
+	worker := userservice.NewUserService(pool, queries, taskManager)
+	tasks.Register(taskManager, tasks.TypeScheduledTransfer, worker.HandleScheduledTransferTask)
+	taskManager.On(tasks.TypeRecurringTransferDispatch, worker.HandleRecurringTransferDispatchTask)
+	tasks.Register(taskManager, tasks.TypeRecurringTransferOccurrence, worker.HandleRecurringTransferOccurrenceTask)
+	taskManager.On(tasks.TypeHoldExpiry, worker.HandleExpireHoldsTask)
+	taskManager.On(tasks.TypeInterestAccrual, worker.HandleInterestAccrualTask)
+	taskManager.On(tasks.TypeInterestPosting, worker.HandleInterestPostingTask)
+	tasks.Register(taskManager, tasks.TypeTransactionExport, worker.HandleTransactionExportTask)
+	tasks.Register(taskManager, tasks.TypeTransferBatch, worker.HandleTransferBatchTask)
+	tasks.Register(taskManager, domain.EventUserCreated, worker.HandleUserCreatedEvent)
+	tasks.Register(taskManager, domain.EventTransferCompleted, worker.HandleTransferCompletedEvent)
+	taskManager.On(tasks.TypeOutboxCleanup, worker.HandleOutboxCleanupTask)
+	taskManager.On(tasks.TypeTransactionExportCleanup, worker.HandleTransactionExportCleanupTask)
+	for _, eventType := range domain.EventTypes {
+		taskManager.On(eventType, worker.WebhookEventHandler(eventType))
+	}
+	tasks.Register(taskManager, tasks.TypeWebhookDelivery, worker.HandleWebhookDeliveryTask)
+	taskManager.On(tasks.TypeWebhookSweep, worker.HandleWebhookSweepTask)
+	taskManager.RegisterPeriodicTask("@every 1m", tasks.TypeRecurringTransferDispatch)
+	taskManager.RegisterPeriodicTask("@every 1m", tasks.TypeHoldExpiry)
+	taskManager.RegisterPeriodicTask("5 0 * * *", tasks.TypeInterestAccrual)
+	taskManager.RegisterPeriodicTask("0 1 1 * *", tasks.TypeInterestPosting)
+	taskManager.RegisterPeriodicTask("30 0 * * *", tasks.TypeOutboxCleanup)
+	taskManager.RegisterPeriodicTask("45 0 * * *", tasks.TypeTransactionExportCleanup)
+	taskManager.RegisterPeriodicTask("@every 5m", tasks.TypeWebhookSweep)
+
+	// Components are started in order and stopped in reverse: the servers stop taking requests
+	// before the workers, and the task client and database pool are released last.
+	lc := lifecycle.New(options.logger, shutdownTimeout)
+	lc.Add(databaseComponent(options.pool))
+	lc.Add(taskClientComponent(options.taskManager))
+	lc.Add(taskWorkerComponent(options.taskManager))
+	lc.Add(periodicTaskComponent(options.taskManager))
+	lc.Add(outboxRelayComponent(worker))
+
+	// Expose the connection pool and the queue depths on /metrics
+	metrics.Registry.MustRegister(metrics.NewPoolCollector(options.pool))
+	if inspector, ok := options.taskManager.(tasks.Inspector); ok {
+		metrics.Registry.MustRegister(tasks.NewQueueCollector(inspector))
+	}
 
-	// Start gRPC server with interceptor
-	g.Go(func() error {
-		return runGrpcServer(ctx, options.queries, options.pool, options.logger)
-	})
+	checker := newHealthChecker(options.pool, options.taskManager)
+	grpcHealth := grpchealth.NewServer()
+	grpcServer, err := newGrpcServer(options.queries, options.pool, options.taskManager, grpcHealth, options.logger)
+	if err != nil {
+		log.Fatalf("cannot create gRPC server: %v\n", err)
+	}
+	lc.Add(grpcServer)
+	lc.Add(newHTTPServer(options.queries, options.pool, options.taskManager, checker, options.logger))
+	lc.Add(readinessComponent(checker, grpcHealth, config.ShutdownDrainDelay))
 
-	// Optionally, start an HTTP server concurrently
-	g.Go(func() error {
-		return runHTTPServer(ctx, options.queries, options.pool, options.taskManager, options.logger)
-	})
+	// A second signal during shutdown kills the process
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
 
-	// Wait for all servers to exit
-	if err := g.Wait(); err != nil {
+	if err := lc.Run(ctx); err != nil {
 		log.Fatalf("Error occurred: %v", err)
 	}
 }
 
-func runHTTPServer(ctx context.Context, queries *db.Queries, pool *pgxpool.Pool, taskManager tasks.TaskManager, logger *zap.Logger) error {
-	// Since api.Server doesn't have a Handler method, assume api.NewServer returns an http.Handler directly
-	server := api.NewServer(queries, pool, taskManager)
+// shutdownTimeout is how long each component gets to stop once a shutdown starts.
+const shutdownTimeout = 30 * time.Second
+
+// grpcGracefulStopTimeout is how long the gRPC server waits for calls in flight before closing their connections.
+const grpcGracefulStopTimeout = 10 * time.Second
+
+// healthCheckTimeout is how long each readiness check may take.
+const healthCheckTimeout = 2 * time.Second
+
+// newHealthChecker checks the database, the migrations and, with the Redis task backend, Redis.
+func newHealthChecker(pool *pgxpool.Pool, taskManager tasks.TaskManager) *health.Checker {
+	checker := health.New(healthCheckTimeout)
+	checker.Add("database", pool.Ping)
+	checker.Add("migrations", func(ctx context.Context) error {
+		return migration.CheckVersion(ctx, pool)
+	})
+	if pinger, ok := taskManager.(tasks.Pinger); ok {
+		checker.Add("redis", pinger.Ping)
+	}
+	return checker
+}
+
+// readinessComponent reports the service as not ready as soon as shutdown starts, then waits for
+// drainDelay so load balancers stop routing new traffic before the servers stop.
+func readinessComponent(checker *health.Checker, grpcHealth *grpchealth.Server, drainDelay time.Duration) lifecycle.Component {
+	return lifecycle.Component{
+		Name: "readiness",
+		Stop: func(ctx context.Context) error {
+			checker.Shutdown()
+			grpcHealth.Shutdown()
+			select {
+			case <-time.After(drainDelay):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+}
 
-	// Start the HTTP server
+func newHTTPServer(queries *db.Queries, pool *pgxpool.Pool, taskManager tasks.TaskManager, checker *health.Checker, logger *zap.Logger) lifecycle.Component {
+	server := api.NewServer(queries, pool, taskManager, checker)
 	address := ":8080"
-	logger.Info("Starting HTTP server on %s\n", zap.String("address", address))
 
-	if err := server.Start(address); err != nil {
-		logger.Error("Failed to start server: %v\n", zap.Error(err))
+	return lifecycle.Component{
+		Name: "http server",
+		Run: func() error {
+			logger.Info("Starting HTTP server", zap.String("address", address))
+			if err := server.Start(address); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+		Stop: server.Shutdown,
 	}
+}
 
-	go func() {
-		<-ctx.Done()
-		logger.Info("Shutting down HTTP server...")
-	}()
-	return nil
+// newTaskManager creates the task manager of the configured backend, Redis unless TASK_BACKEND says otherwise.
+func newTaskManager(config *sdk.Config) (tasks.TaskManager, error) {
+	emitter, err := tasks.NewEventEmitter(config.TaskEmitter)
+	if err != nil {
+		return nil, err
+	}
+	switch config.TaskBackend {
+	case "", tasks.BackendRedis:
+		return tasks.NewTaskManager(asynq.RedisClientOpt{
+			Addr: config.RedisAddress,
+		}, tasks.WithEventEmitter(emitter)), nil
+	case tasks.BackendMemory:
+		return tasks.NewMemoryTaskManager(10, tasks.WithEventEmitter(emitter)), nil
+	default:
+		return nil, fmt.Errorf("unknown task backend %q", config.TaskBackend)
+	}
+}
 
+// newMailer creates the mailer of the configured type: "smtp" sends emails, "file" writes them to the outbox directory.
+func newMailer(config *sdk.Config) (email.Mailer, error) {
+	switch config.MailerType {
+	case "smtp":
+		return email.NewSMTPMailer(config.SMTPHost, config.SMTPPort, config.SMTPUsername, config.SMTPPassword, config.MailFrom), nil
+	case "", "file":
+		return email.NewFileMailer(config.MailOutbox, config.MailFrom)
+	default:
+		return nil, fmt.Errorf("unknown mailer %q", config.MailerType)
+	}
 }
 
-func runTaskManager(ctx context.Context, logger *zap.Logger, taskManager tasks.TaskManager) error {
-	// Log start of task manager
-	logger.Info("Starting task manager...")
+// taskWorkerComponent processes tasks. Stopping it waits for the tasks being processed and also stops RunPeriodic.
+func taskWorkerComponent(taskManager tasks.TaskManager) lifecycle.Component {
+	return lifecycle.Component{
+		Name: "task worker",
+		Run:  taskManager.Run,
+		Stop: func(ctx context.Context) error {
+			taskManager.Shutdown()
+			return nil
+		},
+	}
+}
 
-	// Run the task manager to process tasks
-	err := taskManager.Run()
-	if err != nil {
-		logger.Error("Task manager failed to run", zap.Error(err))
+// periodicTaskComponent enqueues the periodic tasks; it stops together with the task worker.
+func periodicTaskComponent(taskManager tasks.TaskManager) lifecycle.Component {
+	return lifecycle.Component{
+		Name: "periodic task manager",
+		Run:  taskManager.RunPeriodic,
 	}
+}
 
-	// Monitor context for shutdown signal
-	select {
-	case <-ctx.Done():
-		logger.Info("Shutting down task manager...")
+// taskClientComponent closes the connections used to enqueue tasks once nothing enqueues anymore.
+func taskClientComponent(taskManager tasks.TaskManager) lifecycle.Component {
+	return lifecycle.Component{
+		Name: "task client",
+		Stop: func(ctx context.Context) error {
+			return taskManager.Close()
+		},
 	}
+}
 
-	return err
+// outboxRelayComponent publishes the domain events recorded in the outbox.
+func outboxRelayComponent(worker *userservice.UserService) lifecycle.Component {
+	ctx, cancel := context.WithCancel(context.Background())
+	return lifecycle.Component{
+		Name: "outbox relay",
+		Run: func() error {
+			return worker.RunOutboxRelay(ctx, time.Second)
+		},
+		Stop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	}
 }
 
-func runGrpcServer(ctx context.Context, queries *db.Queries, pool *pgxpool.Pool, logger *zap.Logger) error {
+func databaseComponent(pool *pgxpool.Pool) lifecycle.Component {
+	return lifecycle.Component{
+		Name: "database pool",
+		Stop: func(ctx context.Context) error {
+			pool.Close()
+			return nil
+		},
+	}
+}
+
+func newGrpcServer(queries *db.Queries, pool *pgxpool.Pool, taskManager tasks.TaskManager, grpcHealth *grpchealth.Server, logger *zap.Logger) (lifecycle.Component, error) {
 	// Initialize the gRPC server with interceptor
 	grpcServer := grpc.NewServer(
 		// grpc.UnaryInterceptor(authInterceptor),
-		grpc.UnaryInterceptor(chainUnaryInterceptors(loggingInterceptor(logger), authInterceptor)),
+		grpc.UnaryInterceptor(chainUnaryInterceptors(loggingInterceptor(logger), metricsInterceptor, authInterceptor)),
 	)
-	server := grpc_api.NewServer(queries, pool)
+	server := grpc_api.NewServer(queries, pool, taskManager)
 
 	// Register your gRPC service
 	pb.RegisterFinanceFlowServer(grpcServer, server)
 
+	// Register the standard health service, serving until shutdown starts
+	healthpb.RegisterHealthServer(grpcServer, grpcHealth)
+	grpcHealth.SetServingStatus(pb.FinanceFlow_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+
 	// Register reflection service on gRPC server.
 	reflection.Register(grpcServer)
 
-	// Start the gRPC server
 	address := ":9090"
 	listener, err := net.Listen("tcp", address)
 	if err != nil {
-		return err
+		return lifecycle.Component{}, err
 	}
 
-	logger.Info("Starting gRPC server on %s\n", zap.String("address", address))
-	go func() {
-		<-ctx.Done()
-		logger.Error("Shutting down gRPC server...")
-		grpcServer.GracefulStop()
-	}()
-
-	return grpcServer.Serve(listener)
+	return lifecycle.Component{
+		Name: "grpc server",
+		Run: func() error {
+			logger.Info("Starting gRPC server", zap.String("address", address))
+			return grpcServer.Serve(listener)
+		},
+		// Wait for the calls in flight, then close the connections that are left
+		Stop: func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, grpcGracefulStopTimeout)
+			defer cancel()
+			stopped := make(chan struct{})
+			go func() {
+				grpcServer.GracefulStop()
+				close(stopped)
+			}()
+			select {
+			case <-stopped:
+				return nil
+			case <-ctx.Done():
+				grpcServer.Stop()
+				return fmt.Errorf("graceful stop timed out: %w", ctx.Err())
+			}
+		},
+	}, nil
 }
 
 func authInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
@@ -282,6 +453,20 @@ func loggingInterceptor(logger *zap.Logger) grpc.UnaryServerInterceptor {
 	}
 }
 
+// metricsInterceptor records the count and latency of gRPC calls by method and status code.
+func metricsInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	start := time.Now()
+	h, err := handler(ctx, req)
+	metrics.GRPCRequests.WithLabelValues(info.FullMethod, status.Code(err).String()).Inc()
+	metrics.GRPCRequestDuration.WithLabelValues(info.FullMethod).Observe(time.Since(start).Seconds())
+	return h, err
+}
+
 // chainUnaryInterceptors chains multiple unary interceptors into a single interceptor
 func chainUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
@@ -2,15 +2,21 @@ package grpc_api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/fadedreams/gofinanceflow/business/domain"
+	"github.com/fadedreams/gofinanceflow/business/statement"
+	"github.com/fadedreams/gofinanceflow/business/tasks"
 	"github.com/fadedreams/gofinanceflow/business/userservice"
 	"github.com/fadedreams/gofinanceflow/foundation/sdk"
 	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
 	"github.com/fadedreams/gofinanceflow/infrastructure/pb"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -19,8 +25,8 @@ type Server struct {
 	userService *userservice.UserService
 }
 
-func NewServer(store *db.Queries, dbPool *pgxpool.Pool) *Server {
-	userService := userservice.NewUserService(dbPool, store) // Create UserService instance
+func NewServer(store *db.Queries, dbPool *pgxpool.Pool, taskManager tasks.TaskManager) *Server {
+	userService := userservice.NewUserService(dbPool, store, taskManager) // Create UserService instance
 
 	return &Server{
 		userService: userService,
@@ -144,3 +150,409 @@ func (s *Server) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUs
 
 	return response, nil
 }
+
+// usernameFromContext verifies the bearer token in the incoming metadata and returns its username.
+func usernameFromContext(ctx context.Context) (string, error) {
+	claims, err := claimsFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	username, ok := claims["username"].(string)
+	if !ok || username == "" {
+		return "", fmt.Errorf("invalid token: missing username")
+	}
+	return username, nil
+}
+
+// isAdminContext reports whether the caller's token carries the admin role.
+func isAdminContext(ctx context.Context) bool {
+	claims, err := claimsFromContext(ctx)
+	if err != nil {
+		return false
+	}
+	role, _ := claims["role"].(string)
+	return role == "admin"
+}
+
+func claimsFromContext(ctx context.Context) (map[string]interface{}, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("missing metadata")
+	}
+
+	authHeader, ok := md["authorization"]
+	if !ok || len(authHeader) == 0 {
+		return nil, fmt.Errorf("missing authorization token")
+	}
+
+	token := strings.TrimPrefix(authHeader[0], "Bearer ")
+	if token == authHeader[0] { // If the token is not prefixed with "Bearer "
+		return nil, fmt.Errorf("invalid authorization token")
+	}
+
+	claims, err := sdk.VerifyToken(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %v", err)
+	}
+	return claims, nil
+}
+
+func convertScheduledTransfer(scheduled db.ScheduledTransfer) *pb.ScheduledTransfer {
+	return &pb.ScheduledTransfer{
+		Id:            scheduled.ID,
+		Owner:         scheduled.Owner,
+		FromAccountId: scheduled.FromAccountID,
+		ToAccountId:   scheduled.ToAccountID,
+		Amount:        scheduled.Amount,
+		ExecuteAt:     timestamppb.New(scheduled.ExecuteAt),
+		Status:        scheduled.Status,
+		TransferId:    scheduled.TransferID.Int64,
+		FailureReason: scheduled.FailureReason.String,
+		CreatedAt:     timestamppb.New(scheduled.CreatedAt),
+	}
+}
+
+func (s *Server) CreateScheduledTransfer(ctx context.Context, req *pb.CreateScheduledTransferRequest) (*pb.CreateScheduledTransferResponse, error) {
+	username, err := usernameFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled, err := s.userService.CreateScheduledTransfer(ctx, username, domain.CreateScheduledTransferParams{
+		FromAccountID: req.FromAccountId,
+		ToAccountID:   req.ToAccountId,
+		Amount:        req.Amount,
+		ExecuteAt:     req.ExecuteAt.AsTime(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to schedule transfer: %v", err)
+	}
+
+	return &pb.CreateScheduledTransferResponse{
+		ScheduledTransfer: convertScheduledTransfer(*scheduled),
+	}, nil
+}
+
+func (s *Server) ListScheduledTransfers(ctx context.Context, req *pb.ListScheduledTransfersRequest) (*pb.ListScheduledTransfersResponse, error) {
+	username, err := usernameFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	arg := domain.ListScheduledTransfersParams{
+		Limit:     limit,
+		Offset:    req.Offset,
+		PageToken: req.PageToken,
+	}
+
+	response := &pb.ListScheduledTransfersResponse{}
+	var scheduled []db.ScheduledTransfer
+	// Offset paging is kept for existing clients, everyone else pages with next_page_token
+	if req.Offset > 0 && req.PageToken == "" {
+		scheduled, err = s.userService.ListScheduledTransfers(ctx, username, arg)
+	} else {
+		var page domain.ScheduledTransferPage
+		page, err = s.userService.ListScheduledTransfersPage(ctx, username, arg)
+		scheduled, response.NextPageToken = page.ScheduledTransfers, page.NextPageToken
+	}
+	if err != nil {
+		return nil, pageError(err, "failed to list scheduled transfers")
+	}
+
+	for _, st := range scheduled {
+		response.ScheduledTransfers = append(response.ScheduledTransfers, convertScheduledTransfer(st))
+	}
+	return response, nil
+}
+
+func (s *Server) CancelScheduledTransfer(ctx context.Context, req *pb.CancelScheduledTransferRequest) (*pb.CancelScheduledTransferResponse, error) {
+	username, err := usernameFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled, err := s.userService.CancelScheduledTransfer(ctx, username, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CancelScheduledTransferResponse{
+		ScheduledTransfer: convertScheduledTransfer(*scheduled),
+	}, nil
+}
+
+func convertHold(hold db.Hold) *pb.Hold {
+	return &pb.Hold{
+		Id:             hold.ID,
+		AccountId:      hold.AccountID,
+		ToAccountId:    hold.ToAccountID,
+		Amount:         hold.Amount,
+		CapturedAmount: hold.CapturedAmount,
+		Status:         hold.Status,
+		ExpiresAt:      timestamppb.New(hold.ExpiresAt),
+		CreatedAt:      timestamppb.New(hold.CreatedAt),
+	}
+}
+
+func (s *Server) CreateHold(ctx context.Context, req *pb.CreateHoldRequest) (*pb.CreateHoldResponse, error) {
+	username, err := usernameFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hold, err := s.userService.CreateHold(ctx, username, domain.CreateHoldParams{
+		AccountID:   req.AccountId,
+		ToAccountID: req.ToAccountId,
+		Amount:      req.Amount,
+		ExpiresAt:   req.ExpiresAt.AsTime(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create hold: %v", err)
+	}
+
+	return &pb.CreateHoldResponse{
+		Hold: convertHold(*hold),
+	}, nil
+}
+
+func (s *Server) CaptureHold(ctx context.Context, req *pb.CaptureHoldRequest) (*pb.CaptureHoldResponse, error) {
+	username, err := usernameFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.userService.CaptureHold(ctx, username, req.Id, domain.CaptureHoldParams{
+		Amount: req.Amount,
+	})
+	if err != nil {
+		return nil, transferError(err, "failed to capture hold")
+	}
+
+	return &pb.CaptureHoldResponse{
+		Hold:       convertHold(result.Hold),
+		TransferId: result.Transfer.Transfer.ID,
+	}, nil
+}
+
+func (s *Server) ReleaseHold(ctx context.Context, req *pb.ReleaseHoldRequest) (*pb.ReleaseHoldResponse, error) {
+	username, err := usernameFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hold, err := s.userService.ReleaseHold(ctx, username, req.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release hold: %v", err)
+	}
+
+	return &pb.ReleaseHoldResponse{
+		Hold: convertHold(*hold),
+	}, nil
+}
+
+// transferError converts a failed transfer into a gRPC error.
+// Broken transfer limits are reported as ResourceExhausted with the limit_exceeded error code.
+func transferError(err error, message string) error {
+	var limitErr *domain.LimitExceededError
+	if errors.As(err, &limitErr) {
+		return status.Error(codes.ResourceExhausted, limitErr.Error())
+	}
+	return fmt.Errorf("%s: %v", message, err)
+}
+
+func convertTransfer(transfer db.Transfer) *pb.Transfer {
+	return &pb.Transfer{
+		Id:            transfer.ID,
+		FromAccountId: transfer.FromAccountID,
+		ToAccountId:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		Status:        transfer.Status,
+		FailureReason: transfer.FailureReason.String,
+		CreatedAt:     timestamppb.New(transfer.CreatedAt),
+		Description:   transfer.Description,
+	}
+}
+
+func (s *Server) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	username, err := usernameFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.FromAccountId == req.ToAccountId {
+		return nil, fmt.Errorf("from and to accounts must be different")
+	}
+	if req.Amount <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "amount must be positive")
+	}
+	// Money can only be moved out of the caller's own accounts
+	if err := s.userService.CheckAccountOwner(ctx, req.FromAccountId, username); err != nil {
+		if errors.Is(err, userservice.ErrAccountNotOwned) {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	result, err := s.userService.HandleFundsTransfer(ctx, domain.HandleFundsTransferParams{
+		FromAccountID: req.FromAccountId,
+		ToAccountID:   req.ToAccountId,
+		Amount:        req.Amount,
+		Description:   req.Description,
+	})
+	if err != nil {
+		return nil, transferError(err, "failed to process transfer")
+	}
+
+	return &pb.CreateTransferResponse{
+		Transfer: convertTransfer(result.Transfer),
+	}, nil
+}
+
+func convertStatement(stmt statement.Statement) *pb.Statement {
+	response := &pb.Statement{
+		AccountId:      stmt.AccountID,
+		Owner:          stmt.Owner,
+		Currency:       stmt.Currency,
+		From:           timestamppb.New(stmt.From),
+		To:             timestamppb.New(stmt.To),
+		OpeningBalance: stmt.OpeningBalance,
+		ClosingBalance: stmt.ClosingBalance,
+		TotalCredits:   stmt.TotalCredits,
+		TotalDebits:    stmt.TotalDebits,
+	}
+	for _, line := range stmt.Lines {
+		response.Lines = append(response.Lines, &pb.StatementLine{
+			Id:             line.ID,
+			Amount:         line.Amount,
+			RunningBalance: line.RunningBalance,
+			CreatedAt:      timestamppb.New(line.CreatedAt),
+		})
+	}
+	return response
+}
+
+func (s *Server) GetAccountStatement(ctx context.Context, req *pb.GetAccountStatementRequest) (*pb.GetAccountStatementResponse, error) {
+	username, err := usernameFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt, err := s.userService.GetAccountStatement(ctx, req.AccountId, username, isAdminContext(ctx), req.From.AsTime(), req.To.AsTime())
+	if err != nil {
+		return nil, fmt.Errorf("failed to build statement: %v", err)
+	}
+
+	return &pb.GetAccountStatementResponse{
+		Statement: convertStatement(stmt),
+	}, nil
+}
+
+// pageSize returns the requested page size, defaulting to 10.
+func pageSize(size int32) int32 {
+	if size <= 0 {
+		return 10
+	}
+	return size
+}
+
+// pageError converts a failed list request into a gRPC error.
+func pageError(err error, message string) error {
+	if errors.Is(err, userservice.ErrInvalidPage) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return fmt.Errorf("%s: %v", message, err)
+}
+
+func convertAccount(account db.Account) *pb.Account {
+	return &pb.Account{
+		Id:          account.ID,
+		Owner:       account.Owner,
+		Balance:     account.Balance,
+		Currency:    account.Currency,
+		Status:      account.Status,
+		Nickname:    account.Nickname,
+		ProductType: account.ProductType,
+		IsDefault:   account.IsDefault,
+		CreatedAt:   timestamppb.New(account.CreatedAt),
+	}
+}
+
+func (s *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	username, err := usernameFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.userService.ListAccountsPage(ctx, username, domain.ListAccountsParams{
+		Currency:    req.Currency,
+		ProductType: req.ProductType,
+		Status:      req.Status,
+		Limit:       pageSize(req.PageSize),
+		PageToken:   req.PageToken,
+	})
+	if err != nil {
+		return nil, pageError(err, "failed to list accounts")
+	}
+
+	response := &pb.ListAccountsResponse{NextPageToken: page.NextPageToken}
+	for _, account := range page.Accounts {
+		response.Accounts = append(response.Accounts, convertAccount(account))
+	}
+	return response, nil
+}
+
+func (s *Server) ListTransfers(ctx context.Context, req *pb.ListTransfersRequest) (*pb.ListTransfersResponse, error) {
+	username, err := usernameFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != "" && !domain.IsValidTransferStatus(req.Status) {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown transfer status %q", req.Status)
+	}
+
+	page, err := s.userService.ListTransfersPage(ctx, username, isAdminContext(ctx), domain.ListTransfersParams{
+		AccountID: req.AccountId,
+		Status:    req.Status,
+		Limit:     pageSize(req.PageSize),
+		PageToken: req.PageToken,
+	})
+	if err != nil {
+		return nil, pageError(err, "failed to list transfers")
+	}
+
+	response := &pb.ListTransfersResponse{NextPageToken: page.NextPageToken}
+	for _, transfer := range page.Transfers {
+		response.Transfers = append(response.Transfers, convertTransfer(transfer))
+	}
+	return response, nil
+}
+
+func (s *Server) ListAccountTransactions(ctx context.Context, req *pb.ListAccountTransactionsRequest) (*pb.ListAccountTransactionsResponse, error) {
+	username, err := usernameFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.userService.ListAccountTransactionsPage(ctx, req.AccountId, username, isAdminContext(ctx), domain.ListAccountTransactionsParams{
+		Limit:     pageSize(req.PageSize),
+		PageToken: req.PageToken,
+	})
+	if err != nil {
+		return nil, pageError(err, "failed to list account transactions")
+	}
+
+	response := &pb.ListAccountTransactionsResponse{NextPageToken: page.NextPageToken}
+	for _, entry := range page.Transactions {
+		response.Transactions = append(response.Transactions, &pb.AccountTransaction{
+			Id:        entry.ID,
+			AccountId: entry.AccountID,
+			Amount:    entry.Amount,
+			CreatedAt: timestamppb.New(entry.CreatedAt),
+		})
+	}
+	return response, nil
+}
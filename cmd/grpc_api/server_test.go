@@ -0,0 +1,89 @@
+package grpc_api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fadedreams/gofinanceflow/foundation/sdk"
+	db "github.com/fadedreams/gofinanceflow/infrastructure/db/sqlc"
+	"github.com/fadedreams/gofinanceflow/infrastructure/pb"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func createTestAccount(t *testing.T, balance int64) db.Account {
+	hashedPassword, err := sdk.HashPassword(sdk.RandomString(6))
+	require.NoError(t, err)
+
+	user, err := testQueries.CreateUser(context.Background(), db.CreateUserParams{
+		Username:       sdk.RandomOwner(),
+		HashedPassword: hashedPassword,
+		FullName:       sdk.RandomOwner(),
+		Email:          sdk.RandomEmail(),
+	})
+	require.NoError(t, err)
+
+	account, err := testQueries.CreateAccount(context.Background(), db.CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  balance,
+		Currency: sdk.USD,
+	})
+	require.NoError(t, err)
+	return account
+}
+
+// contextFor returns the context of a call authenticated as username.
+func contextFor(t *testing.T, username string) context.Context {
+	token, err := sdk.GenerateJWTToken(username, "user")
+	require.NoError(t, err)
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func TestCreateTransferFromOwnAccount(t *testing.T) {
+	from := createTestAccount(t, 1000)
+	to := createTestAccount(t, 0)
+
+	resp, err := testServer.CreateTransfer(contextFor(t, from.Owner), &pb.CreateTransferRequest{
+		FromAccountId: from.ID,
+		ToAccountId:   to.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(100), resp.Transfer.Amount)
+}
+
+func TestCreateTransferRejectsOtherUsersAccount(t *testing.T) {
+	victim := createTestAccount(t, 1000)
+	attacker := createTestAccount(t, 0)
+
+	_, err := testServer.CreateTransfer(contextFor(t, attacker.Owner), &pb.CreateTransferRequest{
+		FromAccountId: victim.ID,
+		ToAccountId:   attacker.ID,
+		Amount:        500,
+	})
+	require.Error(t, err)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	account, err := testQueries.GetAccount(context.Background(), victim.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), account.Balance)
+}
+
+func TestCreateTransferRejectsNegativeAmount(t *testing.T) {
+	attacker := createTestAccount(t, 0)
+	victim := createTestAccount(t, 1000)
+
+	_, err := testServer.CreateTransfer(contextFor(t, attacker.Owner), &pb.CreateTransferRequest{
+		FromAccountId: attacker.ID,
+		ToAccountId:   victim.ID,
+		Amount:        -500,
+	})
+	require.Error(t, err)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	account, err := testQueries.GetAccount(context.Background(), victim.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), account.Balance)
+}
@@ -0,0 +1,51 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckerReady(t *testing.T) {
+	c := New(time.Second)
+	c.Add("database", func(ctx context.Context) error { return nil })
+	c.Add("redis", func(ctx context.Context) error { return nil })
+
+	report := c.Check(context.Background())
+	require.True(t, report.Ready())
+	require.Len(t, report.Checks, 2)
+	require.Equal(t, StatusOK, report.Checks["redis"].Status)
+}
+
+func TestCheckerReportsFailedDependency(t *testing.T) {
+	c := New(20 * time.Millisecond)
+	c.Add("database", func(ctx context.Context) error { return nil })
+	c.Add("redis", func(ctx context.Context) error { return errors.New("connection refused") })
+	c.Add("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	report := c.Check(context.Background())
+	require.False(t, report.Ready())
+	require.Equal(t, StatusUnavailable, report.Status)
+	require.Equal(t, StatusOK, report.Checks["database"].Status)
+	require.Equal(t, "connection refused", report.Checks["redis"].Error)
+	require.Equal(t, context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+}
+
+func TestCheckerShutdown(t *testing.T) {
+	c := New(time.Second)
+	c.Add("database", func(ctx context.Context) error {
+		t.Fatal("checks must not run during shutdown")
+		return nil
+	})
+
+	c.Shutdown()
+	report := c.Check(context.Background())
+	require.False(t, report.Ready())
+	require.Equal(t, StatusShuttingDown, report.Status)
+}
@@ -0,0 +1,121 @@
+// Package lifecycle starts the components of a process and shuts them down in reverse order.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Component is a part of the process with a lifecycle, like a server or a connection pool.
+type Component struct {
+	// Name identifies the component in logs and errors.
+	Name string
+	// Run blocks while the component is running and returns once Stop was called.
+	// It is optional for components that only need to be released, like a client.
+	Run func() error
+	// Stop stops the component, giving up when ctx expires. It is optional.
+	Stop func(ctx context.Context) error
+}
+
+// Manager runs components until the context is cancelled or one of them fails,
+// then stops every component in the reverse order they were added. Each component gets its own
+// shutdown timeout, so one that hangs does not keep the ones after it from stopping.
+type Manager struct {
+	logger          *zap.Logger
+	shutdownTimeout time.Duration
+	components      []Component
+}
+
+// New creates a Manager that gives every component shutdownTimeout to stop.
+func New(logger *zap.Logger, shutdownTimeout time.Duration) *Manager {
+	return &Manager{
+		logger:          logger,
+		shutdownTimeout: shutdownTimeout,
+	}
+}
+
+// Add adds a component. Components are started in the order they are added,
+// so a component may depend on the ones added before it.
+func (m *Manager) Add(c Component) {
+	m.components = append(m.components, c)
+}
+
+// Run starts the components and blocks until ctx is cancelled or a component's Run returns,
+// then shuts down. A component that fails to stop does not keep the others from stopping.
+// Run returns the error that caused the shutdown, if any, joined with the errors of the stops.
+func (m *Manager) Run(ctx context.Context) error {
+	failed := make(chan error, len(m.components))
+	var wg sync.WaitGroup
+	for _, c := range m.components {
+		if c.Run == nil {
+			continue
+		}
+		m.logger.Info("Starting component", zap.String("component", c.Name))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := c.Run()
+			if err == nil {
+				err = errors.New("stopped unexpectedly")
+			}
+			failed <- fmt.Errorf("%s: %w", c.Name, err)
+		}()
+	}
+
+	var cause error
+	select {
+	case <-ctx.Done():
+		m.logger.Info("Shutting down")
+	case cause = <-failed:
+		m.logger.Error("Component failed, shutting down", zap.Error(cause))
+	}
+
+	errs := []error{cause}
+	for i := len(m.components) - 1; i >= 0; i-- {
+		if err := m.stop(m.components[i]); err != nil {
+			m.logger.Error("Failed to stop component", zap.String("component", m.components[i].Name), zap.Error(err))
+			errs = append(errs, err)
+		}
+	}
+
+	// Wait for the components to return from Run so nothing is left running when the process exits
+	stopped := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(m.shutdownTimeout):
+		errs = append(errs, fmt.Errorf("components still running after %s", m.shutdownTimeout))
+	}
+	return errors.Join(errs...)
+}
+
+// stop stops a component, giving up after the shutdown timeout even if its Stop ignores ctx.
+func (m *Manager) stop(c Component) error {
+	if c.Stop == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), m.shutdownTimeout)
+	defer cancel()
+	m.logger.Info("Stopping component", zap.String("component", c.Name))
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Stop(ctx)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("%s: %w", c.Name, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%s: %w", c.Name, ctx.Err())
+	}
+}
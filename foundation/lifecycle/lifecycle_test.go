@@ -0,0 +1,85 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// recorder records the order components are stopped in.
+type recorder struct {
+	mu      sync.Mutex
+	stopped []string
+}
+
+func (r *recorder) component(name string, stopErr error) Component {
+	done := make(chan struct{})
+	var once sync.Once
+	return Component{
+		Name: name,
+		Run: func() error {
+			<-done
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			r.mu.Lock()
+			r.stopped = append(r.stopped, name)
+			r.mu.Unlock()
+			once.Do(func() { close(done) })
+			return stopErr
+		},
+	}
+}
+
+func TestManagerStopsInReverseOrder(t *testing.T) {
+	var r recorder
+	m := New(zap.NewNop(), time.Second)
+	m.Add(r.component("db", nil))
+	m.Add(r.component("worker", nil))
+	m.Add(r.component("http", nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, m.Run(ctx))
+	require.Equal(t, []string{"http", "worker", "db"}, r.stopped)
+}
+
+func TestManagerStopsEverythingWhenAComponentFails(t *testing.T) {
+	var r recorder
+	m := New(zap.NewNop(), time.Second)
+	m.Add(r.component("db", nil))
+	m.Add(r.component("worker", errors.New("worker stuck")))
+	m.Add(Component{
+		Name: "grpc",
+		Run:  func() error { return errors.New("address in use") },
+	})
+	m.Add(r.component("http", nil))
+
+	err := m.Run(context.Background())
+	require.ErrorContains(t, err, "grpc: address in use")
+	require.ErrorContains(t, err, "worker: worker stuck")
+	require.Equal(t, []string{"http", "worker", "db"}, r.stopped)
+}
+
+func TestManagerGivesUpOnStopAfterTimeout(t *testing.T) {
+	var r recorder
+	m := New(zap.NewNop(), 50*time.Millisecond)
+	m.Add(r.component("db", nil))
+	m.Add(Component{
+		Name: "hung",
+		Stop: func(ctx context.Context) error {
+			select {}
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := m.Run(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, []string{"db"}, r.stopped)
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerExposesRegistry(t *testing.T) {
+	HTTPRequests.WithLabelValues(http.MethodGet, "/users/:username", "200").Inc()
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(body), `gofinanceflow_http_requests_total{code="200",method="GET",route="/users/:username"}`)
+	require.Contains(t, string(body), "go_goroutines")
+}
+
+func TestPoolCollector(t *testing.T) {
+	// The pool connects lazily, so its statistics can be read without a database
+	pool, err := pgxpool.New(context.Background(), "postgres://localhost:1/none?pool_max_conns=4")
+	require.NoError(t, err)
+	defer pool.Close()
+
+	collector := NewPoolCollector(pool)
+	require.Equal(t, 12, testutil.CollectAndCount(collector))
+	problems, err := testutil.CollectAndLint(collector)
+	require.NoError(t, err)
+	require.Empty(t, problems)
+	require.Equal(t, 1, testutil.CollectAndCount(collector, "gofinanceflow_db_pool_max_conns"))
+}
@@ -1,15 +1,27 @@
 package sdk
 
 import (
+	"time"
+
 	"github.com/spf13/viper"
 )
 
 // Config stores all configuration of the application.
 // The values are read by viper from a config file or environment variable.
 type Config struct {
-	Environment  string `mapstructure:"ENVIRONMENT"`
-	DBSource     string `mapstructure:"DB_SOURCE"`
-	RedisAddress string `mapstructure:"REDIS_ADDRESS"`
+	Environment        string        `mapstructure:"ENVIRONMENT"`
+	DBSource           string        `mapstructure:"DB_SOURCE"`
+	RedisAddress       string        `mapstructure:"REDIS_ADDRESS"`
+	TaskBackend        string        `mapstructure:"TASK_BACKEND"`
+	TaskEmitter        string        `mapstructure:"TASK_EMITTER"`
+	MailerType         string        `mapstructure:"MAILER"`
+	MailFrom           string        `mapstructure:"MAIL_FROM"`
+	MailOutbox         string        `mapstructure:"MAIL_OUTBOX_DIR"`
+	SMTPHost           string        `mapstructure:"SMTP_HOST"`
+	SMTPPort           int           `mapstructure:"SMTP_PORT"`
+	SMTPUsername       string        `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword       string        `mapstructure:"SMTP_PASSWORD"`
+	ShutdownDrainDelay time.Duration `mapstructure:"SHUTDOWN_DRAIN_DELAY"`
 }
 
 // LoadConfig reads configuration from file or environment variables.
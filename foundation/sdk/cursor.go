@@ -0,0 +1,85 @@
+package sdk
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor is returned for page tokens that were tampered with or belong to another list.
+var ErrInvalidCursor = errors.New("invalid page token")
+
+// Cursor is the sort key of the last row of a page; the next page starts right after it.
+type Cursor struct {
+	Time time.Time `json:"t"`
+	ID   int64     `json:"id"`
+}
+
+// EncodeCursor returns an opaque page token for the cursor, signed so clients cannot forge positions.
+// The scope names the list, so a token of one list is rejected by every other list.
+func EncodeCursor(scope string, cursor Cursor) string {
+	token, _ := EncodePageToken(scope, cursor)
+	return token
+}
+
+// DecodeCursor verifies a page token of the given list and returns its cursor.
+func DecodeCursor(scope string, token string) (Cursor, error) {
+	var cursor Cursor
+	err := DecodePageToken(scope, token, &cursor)
+	return cursor, err
+}
+
+// pageToken is the signed payload of a page token.
+type pageToken struct {
+	Scope string      `json:"s"`
+	Key   interface{} `json:"k"`
+}
+
+// EncodePageToken returns an opaque signed page token holding any JSON-encodable sort key.
+func EncodePageToken(scope string, key interface{}) (string, error) {
+	data, err := json.Marshal(pageToken{Scope: scope, Key: key})
+	if err != nil {
+		return "", err
+	}
+	payload := string(data)
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + base64.RawURLEncoding.EncodeToString(cursorMAC(payload)), nil
+}
+
+// DecodePageToken verifies a page token of the given list and decodes its sort key into key.
+func DecodePageToken(scope string, token string, key interface{}) error {
+	encodedPayload, encodedMAC, ok := strings.Cut(token, ".")
+	if !ok {
+		return ErrInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(encodedMAC)
+	if err != nil || !hmac.Equal(mac, cursorMAC(string(payload))) {
+		return ErrInvalidCursor
+	}
+
+	var decoded struct {
+		Scope string          `json:"s"`
+		Key   json.RawMessage `json:"k"`
+	}
+	if err := json.Unmarshal(payload, &decoded); err != nil || decoded.Scope != scope {
+		return ErrInvalidCursor
+	}
+	if err := json.Unmarshal(decoded.Key, key); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
+
+// cursorMAC signs a page token payload with a key derived from the token secret.
+func cursorMAC(payload string) []byte {
+	mac := hmac.New(sha256.New, []byte("cursor:"+jwtSecret))
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
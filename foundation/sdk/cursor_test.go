@@ -0,0 +1,38 @@
+package sdk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := Cursor{Time: time.Date(2024, time.May, 1, 12, 30, 0, 123456000, time.UTC), ID: 42}
+
+	decoded, err := DecodeCursor("transfers", EncodeCursor("transfers", cursor))
+	require.NoError(t, err)
+	require.Equal(t, cursor, decoded)
+}
+
+func TestCursorRejectsOtherScope(t *testing.T) {
+	token := EncodeCursor("accounts", Cursor{Time: time.Now(), ID: 1})
+
+	_, err := DecodeCursor("transfers", token)
+	require.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestCursorRejectsTampering(t *testing.T) {
+	token := EncodeCursor("transfers", Cursor{Time: time.Now(), ID: 1})
+	forged := EncodeCursor("transfers", Cursor{Time: time.Now(), ID: 99})
+
+	for _, bad := range []string{
+		"",
+		"not-a-token",
+		forged[:len(forged)-3] + token[len(token)-3:],
+		token[:len(token)/2] + "." + token[len(token)/2:],
+	} {
+		_, err := DecodeCursor("transfers", bad)
+		require.ErrorIs(t, err, ErrInvalidCursor, bad)
+	}
+}
@@ -27,6 +27,40 @@ func IsSupportedCurrency(currency string) bool {
 	return false
 }
 
+// currencyDecimals holds the number of minor-unit digits of every supported currency
+var currencyDecimals = map[string]int{
+	USD: 2,
+	EUR: 2,
+	CAD: 2,
+}
+
+// CurrencyDecimals returns the number of minor-unit digits of a currency, 2 if it is unknown
+func CurrencyDecimals(currency string) int {
+	if decimals, ok := currencyDecimals[currency]; ok {
+		return decimals
+	}
+	return 2
+}
+
+// FormatAmount formats an amount in minor units as a decimal string, e.g. 12345 USD as "123.45"
+func FormatAmount(amount int64, currency string) string {
+	decimals := CurrencyDecimals(currency)
+	if decimals == 0 {
+		return fmt.Sprintf("%d", amount)
+	}
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	scale := int64(1)
+	for i := 0; i < decimals; i++ {
+		scale *= 10
+	}
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/scale, decimals, amount%scale)
+}
+
 const alphabet = "abcdefghijklmnopqrstuvwxyz"
 
 func init() {
@@ -0,0 +1,9 @@
+DELETE FROM "transfers" WHERE "status" IN ('pending', 'failed');
+
+ALTER TABLE "transfers" DROP CONSTRAINT IF EXISTS "transfers_status_check";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "updated_at";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "failure_reason";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "status";
@@ -0,0 +1,15 @@
+ALTER TABLE "transfers" ADD COLUMN "status" varchar NOT NULL DEFAULT 'completed';
+
+ALTER TABLE "transfers" ALTER COLUMN "status" SET DEFAULT 'pending';
+
+ALTER TABLE "transfers" ADD COLUMN "failure_reason" varchar;
+
+ALTER TABLE "transfers" ADD COLUMN "updated_at" timestamptz NOT NULL DEFAULT (now());
+
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_status_check" CHECK ("status" IN ('pending', 'completed', 'failed', 'reversed'));
+
+CREATE INDEX ON "transfers" ("status");
+
+COMMENT ON COLUMN "transfers"."status" IS 'pending, completed, failed or reversed';
+
+COMMENT ON COLUMN "transfers"."failure_reason" IS 'set when status is failed';
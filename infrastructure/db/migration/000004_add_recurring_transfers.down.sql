@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS recurring_transfer_occurrences;
+DROP TABLE IF EXISTS recurring_transfers;
@@ -0,0 +1,57 @@
+CREATE TABLE "recurring_transfers" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "schedule" varchar NOT NULL,
+  "start_at" timestamptz NOT NULL,
+  "end_at" timestamptz,
+  "max_occurrences" integer,
+  "occurrence_count" integer NOT NULL DEFAULT 0,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "next_run_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "recurring_transfer_occurrences" (
+  "id" bigserial PRIMARY KEY,
+  "recurring_transfer_id" bigint NOT NULL,
+  "scheduled_for" timestamptz NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "transfer_id" bigint,
+  "failure_reason" varchar,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "recurring_transfers" ("owner");
+
+CREATE INDEX ON "recurring_transfers" ("status", "next_run_at");
+
+CREATE UNIQUE INDEX ON "recurring_transfer_occurrences" ("recurring_transfer_id", "scheduled_for");
+
+CREATE INDEX ON "recurring_transfer_occurrences" ("status");
+
+COMMENT ON COLUMN "recurring_transfers"."amount" IS 'must be positive';
+
+COMMENT ON COLUMN "recurring_transfers"."schedule" IS 'standard cron expression evaluated in UTC';
+
+COMMENT ON COLUMN "recurring_transfers"."status" IS 'active, paused, completed or deleted';
+
+COMMENT ON COLUMN "recurring_transfer_occurrences"."status" IS 'pending, executed or failed';
+
+ALTER TABLE "recurring_transfers" ADD CONSTRAINT "recurring_transfers_status_check" CHECK ("status" IN ('active', 'paused', 'completed', 'deleted'));
+
+ALTER TABLE "recurring_transfer_occurrences" ADD CONSTRAINT "recurring_transfer_occurrences_status_check" CHECK ("status" IN ('pending', 'executed', 'failed'));
+
+ALTER TABLE "recurring_transfers" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "recurring_transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "recurring_transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "recurring_transfer_occurrences" ADD FOREIGN KEY ("recurring_transfer_id") REFERENCES "recurring_transfers" ("id");
+
+ALTER TABLE "recurring_transfer_occurrences" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
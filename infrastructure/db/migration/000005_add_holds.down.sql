@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS hold_captures;
+DROP TABLE IF EXISTS holds;
@@ -0,0 +1,21 @@
+CREATE TABLE "account_policies" (
+  "account_id" bigint PRIMARY KEY,
+  "min_balance" bigint NOT NULL DEFAULT 0,
+  "overdraft_limit" bigint NOT NULL DEFAULT 0,
+  "unlimited_overdraft" bool NOT NULL DEFAULT false,
+  "frozen" bool NOT NULL DEFAULT false,
+  "updated_by" varchar NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "account_policies"."min_balance" IS 'lowest balance allowed before the overdraft limit applies';
+
+COMMENT ON COLUMN "account_policies"."overdraft_limit" IS 'how far below min_balance the balance may go';
+
+COMMENT ON COLUMN "account_policies"."unlimited_overdraft" IS 'system accounts that may go negative without limit';
+
+ALTER TABLE "account_policies" ADD CONSTRAINT "account_policies_overdraft_limit_check" CHECK ("overdraft_limit" >= 0);
+
+ALTER TABLE "account_policies" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "account_policies" ADD FOREIGN KEY ("updated_by") REFERENCES "users" ("username");
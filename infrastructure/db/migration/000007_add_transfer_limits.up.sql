@@ -0,0 +1,31 @@
+CREATE TABLE "transfer_limits" (
+  "id" bigserial PRIMARY KEY,
+  "scope" varchar NOT NULL,
+  "subject" varchar NOT NULL,
+  "per_transfer_amount" bigint,
+  "daily_amount" bigint,
+  "daily_count" int,
+  "monthly_amount" bigint,
+  "monthly_count" int,
+  "updated_by" varchar,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "transfer_limits" ("scope", "subject");
+
+CREATE INDEX ON "transfers" ("from_account_id", "created_at");
+
+COMMENT ON COLUMN "transfer_limits"."scope" IS 'role, user or account';
+
+COMMENT ON COLUMN "transfer_limits"."subject" IS 'role name, username or account id';
+
+COMMENT ON COLUMN "transfer_limits"."daily_amount" IS 'null means unlimited, as for every other limit column';
+
+ALTER TABLE "transfer_limits" ADD CONSTRAINT "transfer_limits_scope_check" CHECK ("scope" IN ('role', 'user', 'account'));
+
+ALTER TABLE "transfer_limits" ADD FOREIGN KEY ("updated_by") REFERENCES "users" ("username");
+
+INSERT INTO "transfer_limits" ("scope", "subject", "per_transfer_amount", "daily_amount", "daily_count", "monthly_amount", "monthly_count")
+VALUES
+  ('role', 'depositor', 1000000, 2500000, 50, 20000000, 500),
+  ('role', 'business', 10000000, 50000000, 1000, 500000000, 20000);
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS account_audit_logs;
+
+ALTER TABLE "account_policies" ADD COLUMN "frozen" bool NOT NULL DEFAULT false;
+
+UPDATE "account_policies" SET "frozen" = true
+FROM "accounts"
+WHERE "accounts"."id" = "account_policies"."account_id" AND "accounts"."status" = 'frozen';
+
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "accounts_status_check";
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "closed_at";
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "status";
@@ -0,0 +1,32 @@
+ALTER TABLE "accounts" ADD COLUMN "status" varchar NOT NULL DEFAULT 'active';
+
+ALTER TABLE "accounts" ADD COLUMN "closed_at" timestamptz;
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_status_check" CHECK ("status" IN ('active', 'frozen', 'closed'));
+
+COMMENT ON COLUMN "accounts"."status" IS 'active, frozen or closed';
+
+COMMENT ON COLUMN "accounts"."closed_at" IS 'set while status is closed';
+
+UPDATE "accounts" SET "status" = 'frozen'
+FROM "account_policies"
+WHERE "account_policies"."account_id" = "accounts"."id" AND "account_policies"."frozen";
+
+ALTER TABLE "account_policies" DROP COLUMN "frozen";
+
+CREATE TABLE "account_audit_logs" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "action" varchar NOT NULL,
+  "from_status" varchar NOT NULL,
+  "to_status" varchar NOT NULL,
+  "actor" varchar NOT NULL,
+  "reason" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "account_audit_logs" ("account_id", "created_at");
+
+ALTER TABLE "account_audit_logs" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "account_audit_logs" ADD FOREIGN KEY ("actor") REFERENCES "users" ("username");
@@ -0,0 +1,16 @@
+DROP INDEX IF EXISTS "accounts_owner_nickname_idx";
+
+DROP INDEX IF EXISTS "accounts_owner_currency_default_idx";
+
+DROP INDEX IF EXISTS "accounts_owner_currency_idx";
+
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "accounts_product_type_check";
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "is_default";
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "product_type";
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "nickname";
+
+-- Fails while an owner still has several accounts in one currency
+CREATE UNIQUE INDEX ON "accounts" ("owner", "currency");
@@ -0,0 +1,22 @@
+DROP INDEX IF EXISTS "accounts_owner_currency_idx";
+
+ALTER TABLE "accounts" ADD COLUMN "nickname" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "accounts" ADD COLUMN "product_type" varchar NOT NULL DEFAULT 'checking';
+
+ALTER TABLE "accounts" ADD COLUMN "is_default" bool NOT NULL DEFAULT false;
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_product_type_check" CHECK ("product_type" IN ('checking', 'savings', 'envelope'));
+
+COMMENT ON COLUMN "accounts"."nickname" IS 'unique per owner when set';
+
+COMMENT ON COLUMN "accounts"."is_default" IS 'at most one default account per owner and currency';
+
+-- Every existing account was the only one of its owner in its currency
+UPDATE "accounts" SET "is_default" = true;
+
+CREATE INDEX ON "accounts" ("owner", "currency");
+
+CREATE UNIQUE INDEX "accounts_owner_currency_default_idx" ON "accounts" ("owner", "currency") WHERE "is_default";
+
+CREATE UNIQUE INDEX "accounts_owner_nickname_idx" ON "accounts" ("owner", "nickname") WHERE "nickname" <> '';
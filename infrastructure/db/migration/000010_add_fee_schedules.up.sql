@@ -0,0 +1,42 @@
+CREATE TABLE "fee_schedules" (
+  "id" bigserial PRIMARY KEY,
+  "name" varchar NOT NULL,
+  "currency" varchar NOT NULL,
+  "product_type" varchar,
+  "flat_fee" bigint NOT NULL DEFAULT 0,
+  "percentage_bps" int NOT NULL DEFAULT 0,
+  "min_fee" bigint NOT NULL DEFAULT 0,
+  "max_fee" bigint,
+  "fee_account_id" bigint NOT NULL,
+  "active" bool NOT NULL DEFAULT true,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "transfer_fees" (
+  "id" bigserial PRIMARY KEY,
+  "transfer_id" bigint NOT NULL,
+  "fee_schedule_id" bigint NOT NULL,
+  "fee_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "fee_schedules" ("currency", "active");
+
+CREATE INDEX ON "transfer_fees" ("transfer_id");
+
+COMMENT ON COLUMN "fee_schedules"."product_type" IS 'null applies to every product type';
+
+COMMENT ON COLUMN "fee_schedules"."percentage_bps" IS 'percentage of the amount in basis points, clamped to min_fee and max_fee';
+
+COMMENT ON COLUMN "fee_schedules"."max_fee" IS 'null means no cap';
+
+ALTER TABLE "fee_schedules" ADD CONSTRAINT "fee_schedules_amounts_check" CHECK ("flat_fee" >= 0 AND "percentage_bps" >= 0 AND "min_fee" >= 0 AND ("max_fee" IS NULL OR "max_fee" >= "min_fee"));
+
+ALTER TABLE "fee_schedules" ADD FOREIGN KEY ("fee_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_fees" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfer_fees" ADD FOREIGN KEY ("fee_schedule_id") REFERENCES "fee_schedules" ("id");
+
+ALTER TABLE "transfer_fees" ADD FOREIGN KEY ("fee_account_id") REFERENCES "accounts" ("id");
@@ -0,0 +1,52 @@
+CREATE TABLE "interest_rates" (
+  "product_type" varchar PRIMARY KEY,
+  "annual_rate_bps" int NOT NULL,
+  "updated_by" varchar,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "interest_accruals" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "accrual_date" date NOT NULL,
+  "balance" bigint NOT NULL,
+  "annual_rate_bps" int NOT NULL,
+  "amount" bigint NOT NULL,
+  "posted_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "interest_postings" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "period_end" date NOT NULL,
+  "amount" bigint NOT NULL,
+  "account_transaction_id" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "interest_accruals" ("account_id", "accrual_date");
+
+CREATE INDEX ON "interest_accruals" ("posted_at", "accrual_date");
+
+CREATE UNIQUE INDEX ON "interest_postings" ("account_id", "period_end");
+
+COMMENT ON COLUMN "interest_rates"."annual_rate_bps" IS 'annual interest rate in basis points';
+
+COMMENT ON COLUMN "interest_accruals"."balance" IS 'balance the interest was accrued on';
+
+COMMENT ON COLUMN "interest_accruals"."posted_at" IS 'set once the amount is credited to the account';
+
+COMMENT ON COLUMN "interest_postings"."period_end" IS 'accruals before this date are included';
+
+ALTER TABLE "interest_rates" ADD CONSTRAINT "interest_rates_annual_rate_bps_check" CHECK ("annual_rate_bps" >= 0);
+
+ALTER TABLE "interest_rates" ADD FOREIGN KEY ("updated_by") REFERENCES "users" ("username");
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_postings" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_postings" ADD FOREIGN KEY ("account_transaction_id") REFERENCES "account_transactions" ("id");
+
+INSERT INTO "interest_rates" ("product_type", "annual_rate_bps") VALUES ('savings', 200);
@@ -0,0 +1,2 @@
+SELECT lo_unlink("file_oid"::oid) FROM "transaction_exports" WHERE "file_oid" IS NOT NULL;
+DROP TABLE IF EXISTS transaction_exports;
@@ -0,0 +1,28 @@
+CREATE TABLE "transaction_exports" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "account_id" bigint NOT NULL,
+  "format" varchar NOT NULL,
+  "start_at" timestamptz NOT NULL,
+  "end_at" timestamptz NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "file_oid" bigint,
+  "file_size" bigint,
+  "failure_reason" varchar,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "completed_at" timestamptz
+);
+
+CREATE INDEX ON "transaction_exports" ("owner");
+
+COMMENT ON COLUMN "transaction_exports"."format" IS 'csv, ofx or camt053';
+
+COMMENT ON COLUMN "transaction_exports"."status" IS 'pending, processing, completed or failed';
+
+COMMENT ON COLUMN "transaction_exports"."file_oid" IS 'large object holding the generated file';
+
+ALTER TABLE "transaction_exports" ADD CONSTRAINT "transaction_exports_status_check" CHECK ("status" IN ('pending', 'processing', 'completed', 'failed'));
+
+ALTER TABLE "transaction_exports" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "transaction_exports" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
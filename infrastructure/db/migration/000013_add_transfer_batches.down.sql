@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS transfer_batch_lines;
+DROP TABLE IF EXISTS transfer_batches;
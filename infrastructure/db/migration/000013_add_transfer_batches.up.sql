@@ -0,0 +1,50 @@
+CREATE TABLE "transfer_batches" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "mode" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "line_count" int NOT NULL,
+  "total_amount" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "completed_at" timestamptz
+);
+
+CREATE TABLE "transfer_batch_lines" (
+  "id" bigserial PRIMARY KEY,
+  "batch_id" bigint NOT NULL,
+  "line_number" int NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "transfer_id" bigint,
+  "failure_reason" varchar
+);
+
+CREATE INDEX ON "transfer_batches" ("owner");
+
+CREATE UNIQUE INDEX ON "transfer_batch_lines" ("batch_id", "line_number");
+
+COMMENT ON COLUMN "transfer_batches"."mode" IS 'all_or_nothing or best_effort';
+
+COMMENT ON COLUMN "transfer_batches"."status" IS 'pending, processing, completed, partially_completed or failed';
+
+COMMENT ON COLUMN "transfer_batch_lines"."status" IS 'pending, completed or failed';
+
+ALTER TABLE "transfer_batches" ADD CONSTRAINT "transfer_batches_mode_check" CHECK ("mode" IN ('all_or_nothing', 'best_effort'));
+
+ALTER TABLE "transfer_batches" ADD CONSTRAINT "transfer_batches_status_check" CHECK ("status" IN ('pending', 'processing', 'completed', 'partially_completed', 'failed'));
+
+ALTER TABLE "transfer_batch_lines" ADD CONSTRAINT "transfer_batch_lines_status_check" CHECK ("status" IN ('pending', 'completed', 'failed'));
+
+ALTER TABLE "transfer_batch_lines" ADD CONSTRAINT "transfer_batch_lines_amount_check" CHECK ("amount" > 0);
+
+ALTER TABLE "transfer_batches" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_batch_lines" ADD FOREIGN KEY ("batch_id") REFERENCES "transfer_batches" ("id");
+
+ALTER TABLE "transfer_batch_lines" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_batch_lines" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_batch_lines" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
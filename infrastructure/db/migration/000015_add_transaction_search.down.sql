@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS account_transactions_created_at_id_idx;
+DROP INDEX IF EXISTS transfers_status_created_at_idx;
+DROP INDEX IF EXISTS transfers_amount_idx;
+DROP INDEX IF EXISTS transfers_created_at_id_idx;
+DROP INDEX IF EXISTS transfers_to_tsvector_idx;
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "description";
@@ -0,0 +1,13 @@
+ALTER TABLE "transfers" ADD COLUMN "description" varchar NOT NULL DEFAULT '';
+
+CREATE INDEX ON "transfers" USING GIN (to_tsvector('simple', "description"));
+
+CREATE INDEX ON "transfers" ("created_at", "id");
+
+CREATE INDEX ON "transfers" ("amount");
+
+CREATE INDEX ON "transfers" ("status", "created_at");
+
+CREATE INDEX ON "account_transactions" ("created_at", "id");
+
+COMMENT ON COLUMN "transfers"."description" IS 'free text set by the sender, searchable';
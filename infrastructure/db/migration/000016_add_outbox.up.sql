@@ -0,0 +1,19 @@
+CREATE TABLE "outbox" (
+  "id" bigserial PRIMARY KEY,
+  "aggregate_type" varchar NOT NULL,
+  "aggregate_id" varchar NOT NULL,
+  "event_type" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "attempts" int NOT NULL DEFAULT 0,
+  "last_error" varchar,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "published_at" timestamptz
+);
+
+CREATE INDEX ON "outbox" ("aggregate_type", "aggregate_id", "id") WHERE "published_at" IS NULL;
+
+CREATE INDEX ON "outbox" ("published_at") WHERE "published_at" IS NOT NULL;
+
+COMMENT ON COLUMN "outbox"."event_type" IS 'task type the event is published as';
+
+COMMENT ON COLUMN "outbox"."published_at" IS 'set once the event was enqueued on the task manager';
@@ -0,0 +1,51 @@
+CREATE TABLE "webhook_subscriptions" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "url" varchar NOT NULL,
+  "event_types" varchar[] NOT NULL,
+  "secret" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "consecutive_failures" int NOT NULL DEFAULT 0,
+  "disabled_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "webhook_deliveries" (
+  "id" bigserial PRIMARY KEY,
+  "subscription_id" bigint NOT NULL,
+  "event_id" varchar NOT NULL,
+  "event_type" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "attempts" int NOT NULL DEFAULT 0,
+  "response_code" int,
+  "last_error" varchar,
+  "redelivery_of" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "last_attempt_at" timestamptz,
+  "delivered_at" timestamptz
+);
+
+CREATE INDEX ON "webhook_subscriptions" ("owner");
+
+CREATE INDEX ON "webhook_deliveries" ("subscription_id", "id");
+
+CREATE UNIQUE INDEX ON "webhook_deliveries" ("subscription_id", "event_id") WHERE "redelivery_of" IS NULL;
+
+COMMENT ON COLUMN "webhook_subscriptions"."event_types" IS 'event patterns such as transfer.completed or transfer.*';
+
+COMMENT ON COLUMN "webhook_subscriptions"."status" IS 'active or disabled';
+
+COMMENT ON COLUMN "webhook_deliveries"."status" IS 'pending, succeeded or failed';
+
+COMMENT ON COLUMN "webhook_deliveries"."response_code" IS 'HTTP status of the last attempt';
+
+ALTER TABLE "webhook_subscriptions" ADD CONSTRAINT "webhook_subscriptions_status_check" CHECK ("status" IN ('active', 'disabled'));
+
+ALTER TABLE "webhook_deliveries" ADD CONSTRAINT "webhook_deliveries_status_check" CHECK ("status" IN ('pending', 'succeeded', 'failed'));
+
+ALTER TABLE "webhook_subscriptions" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "webhook_deliveries" ADD FOREIGN KEY ("subscription_id") REFERENCES "webhook_subscriptions" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "webhook_deliveries" ADD FOREIGN KEY ("redelivery_of") REFERENCES "webhook_deliveries" ("id");
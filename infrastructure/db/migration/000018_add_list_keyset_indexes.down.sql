@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS account_audit_logs_account_id_id_idx;
+DROP INDEX IF EXISTS transfer_batches_owner_id_idx;
+DROP INDEX IF EXISTS recurring_transfers_owner_id_idx;
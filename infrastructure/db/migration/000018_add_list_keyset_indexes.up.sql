@@ -0,0 +1,5 @@
+CREATE INDEX ON "recurring_transfers" ("owner", "id");
+
+CREATE INDEX ON "transfer_batches" ("owner", "id");
+
+CREATE INDEX ON "account_audit_logs" ("account_id", "id");
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS outbox_id_idx;
+
+CREATE INDEX ON "outbox" ("aggregate_type", "aggregate_id", "id") WHERE "published_at" IS NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS outbox_aggregate_type_aggregate_id_id_idx;
+
+CREATE INDEX ON "outbox" ("id") WHERE "published_at" IS NULL;
@@ -0,0 +1,7 @@
+CREATE TABLE "interest_accrual_runs" (
+  "accrual_date" date PRIMARY KEY,
+  "accounts" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "interest_accrual_runs"."accounts" IS 'number of accruals recorded for the day';
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS transaction_exports_completed_at_idx;
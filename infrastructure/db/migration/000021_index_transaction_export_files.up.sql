@@ -0,0 +1 @@
+CREATE INDEX ON "transaction_exports" ("completed_at") WHERE "file_oid" IS NOT NULL;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS outbox_aggregate_type_aggregate_id_id_idx;
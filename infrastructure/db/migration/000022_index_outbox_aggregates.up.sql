@@ -0,0 +1 @@
+CREATE INDEX ON "outbox" ("aggregate_type", "aggregate_id", "id") WHERE "published_at" IS NULL;
@@ -0,0 +1 @@
+ALTER TABLE "transaction_exports" DROP COLUMN IF EXISTS "claimed_at";
@@ -0,0 +1,3 @@
+ALTER TABLE "transaction_exports" ADD COLUMN "claimed_at" timestamptz;
+
+COMMENT ON COLUMN "transaction_exports"."claimed_at" IS 'when a worker started generating the file';
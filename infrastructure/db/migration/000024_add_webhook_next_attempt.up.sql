@@ -0,0 +1,5 @@
+ALTER TABLE "webhook_deliveries" ADD COLUMN "next_attempt_at" timestamptz NOT NULL DEFAULT (now());
+
+CREATE INDEX ON "webhook_deliveries" ("next_attempt_at") WHERE "status" = 'pending';
+
+COMMENT ON COLUMN "webhook_deliveries"."next_attempt_at" IS 'when the next attempt of a pending delivery is due';
@@ -0,0 +1,4 @@
+ALTER TABLE "scheduled_transfers" DROP CONSTRAINT IF EXISTS "scheduled_transfers_status_check";
+ALTER TABLE "scheduled_transfers" ADD CONSTRAINT "scheduled_transfers_status_check" CHECK ("status" IN ('scheduled', 'executed', 'failed', 'cancelled'));
+
+COMMENT ON COLUMN "scheduled_transfers"."status" IS 'scheduled, executed, failed or cancelled';
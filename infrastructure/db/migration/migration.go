@@ -0,0 +1,62 @@
+// Package migration embeds the migrations applied with golang-migrate so the service
+// can tell whether the database schema is the one it was built for.
+package migration
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed *.up.sql
+var files embed.FS
+
+// LatestVersion returns the version of the newest migration, the one the schema must be at.
+func LatestVersion() (int64, error) {
+	names, err := fs.Glob(files, "*.up.sql")
+	if err != nil {
+		return 0, err
+	}
+	var latest int64
+	for _, name := range names {
+		prefix, _, _ := strings.Cut(name, "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid migration file name %q: %v", name, err)
+		}
+		latest = max(latest, version)
+	}
+	return latest, nil
+}
+
+// CheckVersion returns an error unless the migrations of the database are at LatestVersion and clean.
+func CheckVersion(ctx context.Context, pool *pgxpool.Pool) error {
+	want, err := LatestVersion()
+	if err != nil {
+		return err
+	}
+
+	var version int64
+	var dirty bool
+	err = pool.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("no migrations applied, expected version %d", want)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get migration version: %v", err)
+	}
+	if dirty {
+		return fmt.Errorf("migration %d failed and left the schema dirty", version)
+	}
+	if version != want {
+		return fmt.Errorf("schema is at version %d, expected %d", version, want)
+	}
+	return nil
+}
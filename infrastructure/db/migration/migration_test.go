@@ -0,0 +1,17 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatestVersion(t *testing.T) {
+	names, err := files.ReadDir(".")
+	require.NoError(t, err)
+	require.NotEmpty(t, names)
+
+	version, err := LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, int64(len(names)), version, "migrations must be numbered sequentially")
+}
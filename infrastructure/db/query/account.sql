@@ -8,6 +8,18 @@ INSERT INTO accounts (
   $1, $2, $3
 ) RETURNING *;
 
+-- name: CreateNamedAccount :one
+INSERT INTO accounts (
+  owner,
+  balance,
+  currency,
+  nickname,
+  product_type,
+  is_default
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
 -- name: GetAccount :one
 SELECT * FROM accounts
 WHERE id = $1 LIMIT 1;
@@ -17,12 +29,41 @@ SELECT * FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE;
 
+-- name: GetDefaultAccount :one
+SELECT * FROM accounts
+WHERE owner = $1 AND currency = $2 AND is_default
+LIMIT 1;
+
 -- name: ListAccounts :many
 SELECT * FROM accounts
-WHERE owner = $1
+WHERE owner = sqlc.arg(owner)
+  AND (sqlc.narg(currency)::varchar IS NULL OR currency = sqlc.narg(currency))
+  AND (sqlc.narg(product_type)::varchar IS NULL OR product_type = sqlc.narg(product_type))
+  AND (sqlc.narg(status)::varchar IS NULL OR status = sqlc.narg(status))
 ORDER BY id
-LIMIT $2
-OFFSET $3;
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: ListAccountsAfter :many
+SELECT * FROM accounts
+WHERE owner = sqlc.arg(owner)
+  AND (sqlc.narg(currency)::varchar IS NULL OR currency = sqlc.narg(currency))
+  AND (sqlc.narg(product_type)::varchar IS NULL OR product_type = sqlc.narg(product_type))
+  AND (sqlc.narg(status)::varchar IS NULL OR status = sqlc.narg(status))
+  AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit');
+
+-- name: ClearDefaultAccount :exec
+UPDATE accounts
+SET is_default = false
+WHERE owner = $1 AND currency = $2 AND is_default;
+
+-- name: SetDefaultAccount :one
+UPDATE accounts
+SET is_default = true
+WHERE id = $1
+RETURNING *;
 
 -- name: UpdateAccount :one
 UPDATE accounts
@@ -36,6 +77,14 @@ SET balance = balance + sqlc.arg(amount)
 WHERE id = sqlc.arg(id)
 RETURNING *;
 
+-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET
+  status = sqlc.arg(status),
+  closed_at = CASE WHEN sqlc.arg(status) = 'closed' THEN now() ELSE NULL END
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
 -- name: DeleteAccount :exec
 DELETE FROM accounts
 WHERE id = $1;
@@ -0,0 +1,26 @@
+-- name: CreateAccountAuditLog :one
+INSERT INTO account_audit_logs (
+  account_id,
+  action,
+  from_status,
+  to_status,
+  actor,
+  reason
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: ListAccountAuditLogs :many
+SELECT * FROM account_audit_logs
+WHERE account_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: ListAccountAuditLogsAfter :many
+-- Newest first, so a page continues with the ids below after_id; 0 starts at the newest.
+SELECT * FROM account_audit_logs
+WHERE account_id = sqlc.arg(account_id)
+  AND (sqlc.arg(after_id)::bigint = 0 OR id < sqlc.arg(after_id)::bigint)
+ORDER BY id DESC
+LIMIT sqlc.arg('limit');
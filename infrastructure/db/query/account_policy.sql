@@ -0,0 +1,22 @@
+-- name: GetAccountPolicy :one
+SELECT * FROM account_policies
+WHERE account_id = $1 LIMIT 1;
+
+-- name: UpsertAccountPolicy :one
+INSERT INTO account_policies (
+  account_id,
+  min_balance,
+  overdraft_limit,
+  unlimited_overdraft,
+  updated_by
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (account_id) DO UPDATE
+SET
+  min_balance = EXCLUDED.min_balance,
+  overdraft_limit = EXCLUDED.overdraft_limit,
+  unlimited_overdraft = EXCLUDED.unlimited_overdraft,
+  updated_by = EXCLUDED.updated_by,
+  updated_at = now()
+RETURNING *;
@@ -17,3 +17,31 @@ WHERE account_id = $1
 ORDER BY id
 LIMIT $2
 OFFSET $3;
+
+-- name: ListAccountTransactionsAfter :many
+SELECT * FROM account_transactions
+WHERE account_id = sqlc.arg(account_id)
+  AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit');
+
+-- name: GetAccountBalanceAt :one
+SELECT (a.balance - COALESCE((
+  SELECT SUM(t.amount) FROM account_transactions t
+  WHERE t.account_id = a.id AND t.created_at >= sqlc.arg(at)::timestamptz
+), 0))::bigint AS balance
+FROM accounts a
+WHERE a.id = sqlc.arg(account_id);
+
+-- name: ListAccountTransactionsInRange :many
+SELECT * FROM account_transactions
+WHERE account_id = sqlc.arg(account_id)
+  AND created_at >= sqlc.arg(start_at)::timestamptz
+  AND created_at < sqlc.arg(end_at)::timestamptz
+ORDER BY created_at, id;
+
+-- name: CountAccountTransactionsInRange :one
+SELECT COUNT(*) FROM account_transactions
+WHERE account_id = sqlc.arg(account_id)
+  AND created_at >= sqlc.arg(start_at)::timestamptz
+  AND created_at < sqlc.arg(end_at)::timestamptz;
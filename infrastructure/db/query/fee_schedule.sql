@@ -0,0 +1,53 @@
+-- name: CreateFeeSchedule :one
+INSERT INTO fee_schedules (
+  name,
+  currency,
+  product_type,
+  flat_fee,
+  percentage_bps,
+  min_fee,
+  max_fee,
+  fee_account_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: ListFeeSchedules :many
+SELECT * FROM fee_schedules
+ORDER BY id
+LIMIT $1
+OFFSET $2;
+
+-- name: ListFeeSchedulesAfter :many
+SELECT * FROM fee_schedules
+WHERE id > sqlc.arg(after_id)::bigint
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: ListApplicableFeeSchedules :many
+SELECT * FROM fee_schedules
+WHERE active
+  AND currency = sqlc.arg(currency)
+  AND (product_type IS NULL OR product_type = sqlc.arg(product_type))
+ORDER BY id;
+
+-- name: DeactivateFeeSchedule :one
+UPDATE fee_schedules
+SET active = false
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateTransferFee :one
+INSERT INTO transfer_fees (
+  transfer_id,
+  fee_schedule_id,
+  fee_account_id,
+  amount
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: ListTransferFees :many
+SELECT * FROM transfer_fees
+WHERE transfer_id = $1
+ORDER BY id;
@@ -0,0 +1,60 @@
+-- name: CreateHold :one
+INSERT INTO holds (
+  account_id,
+  to_account_id,
+  amount,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetHold :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1;
+
+-- name: GetHoldForUpdate :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: GetAccountHeldAmount :one
+SELECT COALESCE(SUM(amount - captured_amount), 0)::bigint FROM holds
+WHERE account_id = $1 AND status = 'active' AND expires_at > now();
+
+-- name: AddHoldCapturedAmount :one
+UPDATE holds
+SET
+  captured_amount = captured_amount + sqlc.arg(amount),
+  status = CASE WHEN captured_amount + sqlc.arg(amount) = amount THEN 'captured' ELSE status END,
+  updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateHoldStatus :one
+UPDATE holds
+SET
+  status = $2,
+  updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: ExpireHolds :execrows
+UPDATE holds
+SET
+  status = 'expired',
+  updated_at = now()
+WHERE status = 'active' AND expires_at <= now();
+
+-- name: CreateHoldCapture :one
+INSERT INTO hold_captures (
+  hold_id,
+  transfer_id,
+  amount
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: ListHoldCaptures :many
+SELECT * FROM hold_captures
+WHERE hold_id = $1
+ORDER BY id;
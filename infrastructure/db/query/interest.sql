@@ -0,0 +1,94 @@
+-- name: GetInterestRate :one
+SELECT * FROM interest_rates
+WHERE product_type = $1 LIMIT 1;
+
+-- name: ListInterestRates :many
+SELECT * FROM interest_rates
+ORDER BY product_type;
+
+-- name: UpsertInterestRate :one
+INSERT INTO interest_rates (
+  product_type,
+  annual_rate_bps,
+  updated_by
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (product_type) DO UPDATE
+SET
+  annual_rate_bps = EXCLUDED.annual_rate_bps,
+  updated_by = EXCLUDED.updated_by,
+  updated_at = now()
+RETURNING *;
+
+-- name: ListInterestBearingAccounts :many
+-- Balances are not filtered, the balance of the accrual day may differ from the current one.
+SELECT a.id, r.annual_rate_bps
+FROM accounts a
+JOIN interest_rates r ON r.product_type = a.product_type
+WHERE a.status IN ('active', 'frozen')
+  AND a.created_at < sqlc.arg(created_before)::timestamptz
+  AND r.annual_rate_bps > 0
+  AND a.id > sqlc.arg(after_id)
+ORDER BY a.id
+LIMIT sqlc.arg('limit');
+
+-- name: CreateInterestAccrual :execrows
+INSERT INTO interest_accruals (
+  account_id,
+  accrual_date,
+  balance,
+  annual_rate_bps,
+  amount
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (account_id, accrual_date) DO NOTHING;
+
+-- name: CreateInterestAccrualRun :exec
+INSERT INTO interest_accrual_runs (
+  accrual_date,
+  accounts
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (accrual_date) DO UPDATE
+SET accounts = interest_accrual_runs.accounts + EXCLUDED.accounts;
+
+-- name: GetLatestInterestAccrualRun :one
+SELECT accrual_date FROM interest_accrual_runs
+ORDER BY accrual_date DESC
+LIMIT 1;
+
+-- name: ListAccountsWithUnpostedInterest :many
+SELECT DISTINCT account_id FROM interest_accruals
+WHERE posted_at IS NULL
+  AND accrual_date < sqlc.arg(period_end)
+  AND account_id > sqlc.arg(after_id)
+ORDER BY account_id
+LIMIT sqlc.arg('limit');
+
+-- name: MarkInterestAccrualsPosted :many
+UPDATE interest_accruals
+SET posted_at = now()
+WHERE account_id = sqlc.arg(account_id)
+  AND posted_at IS NULL
+  AND accrual_date < sqlc.arg(period_end)
+RETURNING amount;
+
+-- name: CreateInterestPosting :one
+INSERT INTO interest_postings (
+  account_id,
+  period_end,
+  amount,
+  account_transaction_id
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: ListInterestAccruals :many
+SELECT * FROM interest_accruals
+WHERE account_id = $1
+ORDER BY accrual_date DESC
+LIMIT $2
+OFFSET $3;
@@ -0,0 +1,39 @@
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox (
+  aggregate_type,
+  aggregate_id,
+  event_type,
+  payload
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: ClaimOutboxEvents :many
+-- Locks the oldest unpublished event of every aggregate, so events of one aggregate are published in order.
+-- Events that waited for their aggregate before come last, so blocked aggregates cannot starve the others.
+SELECT * FROM outbox o
+WHERE o.published_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM outbox p
+    WHERE p.aggregate_type = o.aggregate_type
+      AND p.aggregate_id = o.aggregate_id
+      AND p.published_at IS NULL
+      AND p.id < o.id
+  )
+ORDER BY o.attempts, o.id
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkOutboxEventPublished :exec
+UPDATE outbox
+SET published_at = now(), attempts = attempts + 1
+WHERE id = $1;
+
+-- name: FailOutboxEvent :exec
+UPDATE outbox
+SET attempts = attempts + 1, last_error = $2
+WHERE id = $1;
+
+-- name: DeletePublishedOutboxEvents :execrows
+DELETE FROM outbox
+WHERE published_at < sqlc.arg(published_before)::timestamptz;
@@ -0,0 +1,93 @@
+-- name: CreateRecurringTransfer :one
+INSERT INTO recurring_transfers (
+  owner,
+  from_account_id,
+  to_account_id,
+  amount,
+  schedule,
+  start_at,
+  end_at,
+  max_occurrences,
+  next_run_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: GetRecurringTransfer :one
+SELECT * FROM recurring_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: GetRecurringTransferForUpdate :one
+SELECT * FROM recurring_transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListRecurringTransfers :many
+SELECT * FROM recurring_transfers
+WHERE owner = $1 AND status <> 'deleted'
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: ListRecurringTransfersAfter :many
+SELECT * FROM recurring_transfers
+WHERE owner = sqlc.arg(owner) AND status <> 'deleted'
+  AND id > sqlc.arg(after_id)::bigint
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: ListDueRecurringTransfers :many
+SELECT * FROM recurring_transfers
+WHERE status = 'active' AND next_run_at <= sqlc.arg(due_at)::timestamptz
+ORDER BY next_run_at
+LIMIT sqlc.arg('limit');
+
+-- name: AdvanceRecurringTransfer :one
+UPDATE recurring_transfers
+SET
+  occurrence_count = occurrence_count + 1,
+  next_run_at = sqlc.narg(next_run_at),
+  status = sqlc.arg(status),
+  updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateRecurringTransferStatus :one
+UPDATE recurring_transfers
+SET
+  status = sqlc.arg(status),
+  next_run_at = sqlc.narg(next_run_at),
+  updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CreateRecurringTransferOccurrence :one
+INSERT INTO recurring_transfer_occurrences (
+  recurring_transfer_id,
+  scheduled_for
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (recurring_transfer_id, scheduled_for) DO NOTHING
+RETURNING *;
+
+-- name: GetRecurringTransferOccurrenceForUpdate :one
+SELECT * FROM recurring_transfer_occurrences
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListPendingRecurringTransferOccurrences :many
+SELECT * FROM recurring_transfer_occurrences
+WHERE status = 'pending'
+ORDER BY id
+LIMIT $1;
+
+-- name: UpdateRecurringTransferOccurrence :one
+UPDATE recurring_transfer_occurrences
+SET
+  status = sqlc.arg(status),
+  transfer_id = sqlc.narg(transfer_id),
+  failure_reason = sqlc.narg(failure_reason),
+  updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
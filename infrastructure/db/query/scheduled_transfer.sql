@@ -0,0 +1,51 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers (
+  owner,
+  from_account_id,
+  to_account_id,
+  amount,
+  execute_at
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetScheduledTransfer :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: ListScheduledTransfers :many
+SELECT * FROM scheduled_transfers
+WHERE owner = $1
+ORDER BY execute_at, id
+LIMIT $2
+OFFSET $3;
+
+-- name: ListScheduledTransfersAfter :many
+SELECT * FROM scheduled_transfers
+WHERE owner = sqlc.arg(owner)
+  AND (execute_at, id) > (sqlc.arg(after_execute_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY execute_at, id
+LIMIT sqlc.arg('limit');
+
+-- name: GetScheduledTransferForUpdate :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: CancelScheduledTransfer :one
+UPDATE scheduled_transfers
+SET
+  status = 'cancelled',
+  updated_at = now()
+WHERE id = $1 AND owner = $2 AND status = 'scheduled'
+RETURNING *;
+
+-- name: UpdateScheduledTransferStatus :one
+UPDATE scheduled_transfers
+SET
+  status = sqlc.arg(status),
+  transfer_id = sqlc.narg(transfer_id),
+  failure_reason = sqlc.narg(failure_reason),
+  updated_at = now()
+WHERE id = sqlc.arg(id) AND status = 'scheduled'
+RETURNING *;
@@ -0,0 +1,68 @@
+-- name: CreateTransactionExport :one
+INSERT INTO transaction_exports (
+  owner,
+  account_id,
+  format,
+  start_at,
+  end_at
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetTransactionExport :one
+SELECT * FROM transaction_exports
+WHERE id = $1 LIMIT 1;
+
+-- name: ClaimTransactionExport :one
+-- Claims a pending export, or one whose worker claimed it before stale_before and presumably died.
+UPDATE transaction_exports
+SET status = 'processing', claimed_at = now()
+WHERE id = sqlc.arg(id)
+  AND (
+    status = 'pending'
+    OR (status = 'processing' AND COALESCE(claimed_at, created_at) < sqlc.arg(stale_before)::timestamptz)
+  )
+RETURNING *;
+
+-- name: ReleaseTransactionExport :one
+-- Returns a claimed export to pending, so the retried task can claim it again right away.
+UPDATE transaction_exports
+SET status = 'pending', claimed_at = NULL
+WHERE id = sqlc.arg(id) AND status = 'processing' AND claimed_at = sqlc.arg(claimed_at)
+RETURNING *;
+
+-- name: CompleteTransactionExport :one
+-- Only the worker holding the claim may complete the export.
+UPDATE transaction_exports
+SET
+  status = 'completed',
+  file_oid = sqlc.arg(file_oid),
+  file_size = sqlc.arg(file_size),
+  completed_at = now()
+WHERE id = sqlc.arg(id) AND status = 'processing' AND claimed_at = sqlc.arg(claimed_at)
+RETURNING *;
+
+-- name: FailTransactionExport :one
+-- Only the worker holding the claim may fail the export.
+UPDATE transaction_exports
+SET
+  status = 'failed',
+  failure_reason = sqlc.arg(failure_reason),
+  completed_at = now()
+WHERE id = sqlc.arg(id) AND status = 'processing' AND claimed_at = sqlc.arg(claimed_at)
+RETURNING *;
+
+-- name: ListExpiredTransactionExports :many
+-- Locks completed exports whose file was generated before completed_before.
+SELECT * FROM transaction_exports
+WHERE status = 'completed'
+  AND file_oid IS NOT NULL
+  AND completed_at < sqlc.arg(completed_before)::timestamptz
+ORDER BY completed_at
+LIMIT sqlc.arg('limit')
+FOR UPDATE SKIP LOCKED;
+
+-- name: ClearTransactionExportFile :exec
+UPDATE transaction_exports
+SET file_oid = NULL
+WHERE id = $1;
@@ -1,22 +1,49 @@
-
 -- name: CreateTransfer :one
 INSERT INTO transfers (
   from_account_id,
   to_account_id,
-  amount
+  amount,
+  status,
+  failure_reason,
+  description
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5, $6
 ) RETURNING *;
 
 -- name: GetTransfer :one
 SELECT * FROM transfers
 WHERE id = $1 LIMIT 1;
 
+-- name: GetTransferForUpdate :one
+SELECT * FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
 -- name: ListTransfers :many
 SELECT * FROM transfers
 WHERE 
-    from_account_id = $1 OR
-    to_account_id = $2
+    (from_account_id = sqlc.arg(from_account_id) OR
+    to_account_id = sqlc.arg(to_account_id)) AND
+    (sqlc.narg(status)::varchar IS NULL OR status = sqlc.narg(status))
 ORDER BY id
-LIMIT $3
-OFFSET $4;
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: ListTransfersAfter :many
+SELECT * FROM transfers
+WHERE
+    (from_account_id = sqlc.arg(account_id) OR
+    to_account_id = sqlc.arg(account_id)) AND
+    (sqlc.narg(status)::varchar IS NULL OR status = sqlc.narg(status)) AND
+    (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit');
+
+-- name: UpdateTransferStatus :one
+UPDATE transfers
+SET
+  status = sqlc.arg(status),
+  failure_reason = sqlc.narg(failure_reason),
+  updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
@@ -0,0 +1,86 @@
+-- name: CreateTransferBatch :one
+INSERT INTO transfer_batches (
+  owner,
+  mode,
+  line_count,
+  total_amount
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: CreateTransferBatchLine :one
+INSERT INTO transfer_batch_lines (
+  batch_id,
+  line_number,
+  from_account_id,
+  to_account_id,
+  amount
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetTransferBatch :one
+SELECT * FROM transfer_batches
+WHERE id = $1 LIMIT 1;
+
+-- name: ListTransferBatches :many
+SELECT * FROM transfer_batches
+WHERE owner = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: ListTransferBatchesAfter :many
+-- Newest first, so a page continues with the ids below after_id; 0 starts at the newest.
+SELECT * FROM transfer_batches
+WHERE owner = sqlc.arg(owner)
+  AND (sqlc.arg(after_id)::bigint = 0 OR id < sqlc.arg(after_id)::bigint)
+ORDER BY id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ClaimTransferBatch :one
+UPDATE transfer_batches
+SET status = 'processing'
+WHERE id = $1 AND status IN ('pending', 'processing')
+RETURNING *;
+
+-- name: CompleteTransferBatch :one
+UPDATE transfer_batches
+SET
+  status = sqlc.arg(status),
+  completed_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ListTransferBatchLines :many
+SELECT * FROM transfer_batch_lines
+WHERE batch_id = $1
+ORDER BY line_number;
+
+-- name: ListTransferBatchLinesForUpdate :many
+SELECT * FROM transfer_batch_lines
+WHERE batch_id = $1
+ORDER BY line_number
+FOR UPDATE;
+
+-- name: GetTransferBatchLineForUpdate :one
+SELECT * FROM transfer_batch_lines
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: CompleteTransferBatchLine :one
+UPDATE transfer_batch_lines
+SET
+  status = 'completed',
+  transfer_id = sqlc.arg(transfer_id),
+  failure_reason = NULL
+WHERE id = sqlc.arg(id) AND status = 'pending'
+RETURNING *;
+
+-- name: FailTransferBatchLine :one
+UPDATE transfer_batch_lines
+SET
+  status = 'failed',
+  failure_reason = sqlc.arg(failure_reason)
+WHERE id = sqlc.arg(id) AND status = 'pending'
+RETURNING *;
@@ -0,0 +1,62 @@
+-- name: GetTransferLimit :one
+SELECT * FROM transfer_limits
+WHERE scope = $1 AND subject = $2 LIMIT 1;
+
+-- name: UpsertTransferLimit :one
+INSERT INTO transfer_limits (
+  scope,
+  subject,
+  per_transfer_amount,
+  daily_amount,
+  daily_count,
+  monthly_amount,
+  monthly_count,
+  updated_by
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+)
+ON CONFLICT (scope, subject) DO UPDATE
+SET
+  per_transfer_amount = EXCLUDED.per_transfer_amount,
+  daily_amount = EXCLUDED.daily_amount,
+  daily_count = EXCLUDED.daily_count,
+  monthly_amount = EXCLUDED.monthly_amount,
+  monthly_count = EXCLUDED.monthly_count,
+  updated_by = EXCLUDED.updated_by,
+  updated_at = now()
+RETURNING *;
+
+-- name: DeleteTransferLimit :exec
+DELETE FROM transfer_limits
+WHERE scope = $1 AND subject = $2;
+
+-- name: LockOwnerTransferLimits :exec
+SELECT pg_advisory_xact_lock(hashtext(sqlc.arg(owner)::text));
+
+-- name: GetAccountTransferUsage :one
+SELECT
+  COALESCE(SUM(t.amount) FILTER (WHERE t.created_at >= sqlc.arg(day_start)::timestamptz), 0)::bigint AS daily_amount,
+  COUNT(*) FILTER (WHERE t.created_at >= sqlc.arg(day_start)::timestamptz) AS daily_count,
+  COALESCE(SUM(t.amount), 0)::bigint AS monthly_amount,
+  COUNT(*) AS monthly_count
+FROM transfers t
+JOIN accounts f ON f.id = t.from_account_id
+JOIN accounts d ON d.id = t.to_account_id
+WHERE t.from_account_id = sqlc.arg(account_id)
+  AND d.owner <> f.owner
+  AND t.status <> 'failed'
+  AND t.created_at >= sqlc.arg(month_start)::timestamptz;
+
+-- name: GetOwnerTransferUsage :one
+SELECT
+  COALESCE(SUM(t.amount) FILTER (WHERE t.created_at >= sqlc.arg(day_start)::timestamptz), 0)::bigint AS daily_amount,
+  COUNT(*) FILTER (WHERE t.created_at >= sqlc.arg(day_start)::timestamptz) AS daily_count,
+  COALESCE(SUM(t.amount), 0)::bigint AS monthly_amount,
+  COUNT(*) AS monthly_count
+FROM transfers t
+JOIN accounts a ON a.id = t.from_account_id
+JOIN accounts d ON d.id = t.to_account_id
+WHERE a.owner = sqlc.arg(owner)
+  AND d.owner <> a.owner
+  AND t.status <> 'failed'
+  AND t.created_at >= sqlc.arg(month_start)::timestamptz;
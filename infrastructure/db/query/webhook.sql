@@ -0,0 +1,116 @@
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (
+  owner,
+  url,
+  event_types,
+  secret
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetWebhookSubscription :one
+SELECT * FROM webhook_subscriptions
+WHERE id = $1 LIMIT 1;
+
+-- name: ListWebhookSubscriptions :many
+SELECT * FROM webhook_subscriptions
+WHERE owner = $1
+ORDER BY id;
+
+-- name: ListActiveWebhookSubscriptions :many
+SELECT * FROM webhook_subscriptions
+WHERE owner = ANY(sqlc.arg(owners)::varchar[]) AND status = 'active'
+ORDER BY id;
+
+-- name: DeleteWebhookSubscription :exec
+DELETE FROM webhook_subscriptions
+WHERE id = $1;
+
+-- name: EnableWebhookSubscription :one
+UPDATE webhook_subscriptions
+SET status = 'active', consecutive_failures = 0, disabled_at = NULL
+WHERE id = $1
+RETURNING *;
+
+-- name: RecordWebhookSuccess :exec
+UPDATE webhook_subscriptions
+SET consecutive_failures = 0
+WHERE id = $1;
+
+-- name: RecordWebhookFailure :one
+-- Disables the subscription once it failed max_failures times in a row.
+UPDATE webhook_subscriptions
+SET
+  consecutive_failures = consecutive_failures + 1,
+  status = CASE WHEN consecutive_failures + 1 >= sqlc.arg(max_failures)::int THEN 'disabled' ELSE status END,
+  disabled_at = CASE WHEN consecutive_failures + 1 >= sqlc.arg(max_failures)::int AND status = 'active' THEN now() ELSE disabled_at END
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CreateWebhookDelivery :one
+-- Returns the existing delivery if the event was already fanned out to the subscription.
+INSERT INTO webhook_deliveries (
+  subscription_id,
+  event_id,
+  event_type,
+  payload
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (subscription_id, event_id) WHERE redelivery_of IS NULL
+DO UPDATE SET event_id = EXCLUDED.event_id
+RETURNING *;
+
+-- name: CreateWebhookRedelivery :one
+INSERT INTO webhook_deliveries (
+  subscription_id,
+  event_id,
+  event_type,
+  payload,
+  redelivery_of
+)
+SELECT subscription_id, event_id, event_type, payload, id
+FROM webhook_deliveries d
+WHERE d.id = $1
+RETURNING *;
+
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries
+WHERE id = $1 LIMIT 1;
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE subscription_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: ListWebhookDeliveriesAfter :many
+-- Newest first, so a page continues with the ids below after_id; 0 starts at the newest.
+SELECT * FROM webhook_deliveries
+WHERE subscription_id = sqlc.arg(subscription_id)
+  AND (sqlc.arg(after_id)::bigint = 0 OR id < sqlc.arg(after_id)::bigint)
+ORDER BY id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: RecordWebhookDeliveryAttempt :one
+-- Records the outcome of an attempt; status stays pending while the delivery is retried.
+-- Only the attempt the delivery expects is recorded, so a repeated attempt is not counted twice.
+UPDATE webhook_deliveries
+SET
+  status = sqlc.arg(status),
+  attempts = attempts + 1,
+  response_code = sqlc.narg(response_code),
+  last_error = sqlc.narg(last_error),
+  last_attempt_at = now(),
+  next_attempt_at = sqlc.arg(next_attempt_at),
+  delivered_at = CASE WHEN sqlc.arg(status) = 'succeeded' THEN now() ELSE delivered_at END
+WHERE id = sqlc.arg(id) AND status = 'pending' AND attempts = sqlc.arg(attempts)
+RETURNING *;
+
+-- name: ListOverdueWebhookDeliveries :many
+-- Pending deliveries whose next attempt was due before due_before.
+SELECT * FROM webhook_deliveries
+WHERE status = 'pending' AND next_attempt_at < sqlc.arg(due_before)::timestamptz
+ORDER BY next_attempt_at
+LIMIT sqlc.arg('limit');
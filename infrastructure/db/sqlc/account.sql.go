@@ -7,13 +7,16 @@ package db
 
 import (
 	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts
 SET balance = balance + $1
 WHERE id = $2
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, status, closed_at, nickname, product_type, is_default
 `
 
 type AddAccountBalanceParams struct {
@@ -30,10 +33,31 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Status,
+		&i.ClosedAt,
+		&i.Nickname,
+		&i.ProductType,
+		&i.IsDefault,
 	)
 	return i, err
 }
 
+const clearDefaultAccount = `-- name: ClearDefaultAccount :exec
+UPDATE accounts
+SET is_default = false
+WHERE owner = $1 AND currency = $2 AND is_default
+`
+
+type ClearDefaultAccountParams struct {
+	Owner    string `json:"owner"`
+	Currency string `json:"currency"`
+}
+
+func (q *Queries) ClearDefaultAccount(ctx context.Context, arg ClearDefaultAccountParams) error {
+	_, err := q.db.Exec(ctx, clearDefaultAccount, arg.Owner, arg.Currency)
+	return err
+}
+
 const createAccount = `-- name: CreateAccount :one
 INSERT INTO accounts (
   owner,
@@ -41,7 +65,7 @@ INSERT INTO accounts (
   currency
 ) VALUES (
   $1, $2, $3
-) RETURNING id, owner, balance, currency, created_at
+) RETURNING id, owner, balance, currency, created_at, status, closed_at, nickname, product_type, is_default
 `
 
 type CreateAccountParams struct {
@@ -59,6 +83,58 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Status,
+		&i.ClosedAt,
+		&i.Nickname,
+		&i.ProductType,
+		&i.IsDefault,
+	)
+	return i, err
+}
+
+const createNamedAccount = `-- name: CreateNamedAccount :one
+INSERT INTO accounts (
+  owner,
+  balance,
+  currency,
+  nickname,
+  product_type,
+  is_default
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING id, owner, balance, currency, created_at, status, closed_at, nickname, product_type, is_default
+`
+
+type CreateNamedAccountParams struct {
+	Owner       string `json:"owner"`
+	Balance     int64  `json:"balance"`
+	Currency    string `json:"currency"`
+	Nickname    string `json:"nickname"`
+	ProductType string `json:"product_type"`
+	IsDefault   bool   `json:"is_default"`
+}
+
+func (q *Queries) CreateNamedAccount(ctx context.Context, arg CreateNamedAccountParams) (Account, error) {
+	row := q.db.QueryRow(ctx, createNamedAccount,
+		arg.Owner,
+		arg.Balance,
+		arg.Currency,
+		arg.Nickname,
+		arg.ProductType,
+		arg.IsDefault,
+	)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.Status,
+		&i.ClosedAt,
+		&i.Nickname,
+		&i.ProductType,
+		&i.IsDefault,
 	)
 	return i, err
 }
@@ -74,7 +150,7 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, status, closed_at, nickname, product_type, is_default FROM accounts
 WHERE id = $1 LIMIT 1
 `
 
@@ -87,12 +163,17 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Status,
+		&i.ClosedAt,
+		&i.Nickname,
+		&i.ProductType,
+		&i.IsDefault,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, status, closed_at, nickname, product_type, is_default FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE
 `
@@ -106,26 +187,73 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Status,
+		&i.ClosedAt,
+		&i.Nickname,
+		&i.ProductType,
+		&i.IsDefault,
+	)
+	return i, err
+}
+
+const getDefaultAccount = `-- name: GetDefaultAccount :one
+SELECT id, owner, balance, currency, created_at, status, closed_at, nickname, product_type, is_default FROM accounts
+WHERE owner = $1 AND currency = $2 AND is_default
+LIMIT 1
+`
+
+type GetDefaultAccountParams struct {
+	Owner    string `json:"owner"`
+	Currency string `json:"currency"`
+}
+
+func (q *Queries) GetDefaultAccount(ctx context.Context, arg GetDefaultAccountParams) (Account, error) {
+	row := q.db.QueryRow(ctx, getDefaultAccount, arg.Owner, arg.Currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.Status,
+		&i.ClosedAt,
+		&i.Nickname,
+		&i.ProductType,
+		&i.IsDefault,
 	)
 	return i, err
 }
 
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, status, closed_at, nickname, product_type, is_default FROM accounts
 WHERE owner = $1
+  AND ($2::varchar IS NULL OR currency = $2)
+  AND ($3::varchar IS NULL OR product_type = $3)
+  AND ($4::varchar IS NULL OR status = $4)
 ORDER BY id
-LIMIT $2
-OFFSET $3
+LIMIT $6
+OFFSET $5
 `
 
 type ListAccountsParams struct {
-	Owner  string `json:"owner"`
-	Limit  int32  `json:"limit"`
-	Offset int32  `json:"offset"`
+	Owner       string      `json:"owner"`
+	Currency    pgtype.Text `json:"currency"`
+	ProductType pgtype.Text `json:"product_type"`
+	Status      pgtype.Text `json:"status"`
+	Offset      int32       `json:"offset"`
+	Limit       int32       `json:"limit"`
 }
 
 func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
-	rows, err := q.db.Query(ctx, listAccounts, arg.Owner, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listAccounts,
+		arg.Owner,
+		arg.Currency,
+		arg.ProductType,
+		arg.Status,
+		arg.Offset,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -139,6 +267,11 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.Status,
+			&i.ClosedAt,
+			&i.Nickname,
+			&i.ProductType,
+			&i.IsDefault,
 		); err != nil {
 			return nil, err
 		}
@@ -150,11 +283,96 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 	return items, nil
 }
 
+const listAccountsAfter = `-- name: ListAccountsAfter :many
+SELECT id, owner, balance, currency, created_at, status, closed_at, nickname, product_type, is_default FROM accounts
+WHERE owner = $1
+  AND ($2::varchar IS NULL OR currency = $2)
+  AND ($3::varchar IS NULL OR product_type = $3)
+  AND ($4::varchar IS NULL OR status = $4)
+  AND (created_at, id) > ($5::timestamptz, $6::bigint)
+ORDER BY created_at, id
+LIMIT $7
+`
+
+type ListAccountsAfterParams struct {
+	Owner          string      `json:"owner"`
+	Currency       pgtype.Text `json:"currency"`
+	ProductType    pgtype.Text `json:"product_type"`
+	Status         pgtype.Text `json:"status"`
+	AfterCreatedAt time.Time   `json:"after_created_at"`
+	AfterID        int64       `json:"after_id"`
+	Limit          int32       `json:"limit"`
+}
+
+func (q *Queries) ListAccountsAfter(ctx context.Context, arg ListAccountsAfterParams) ([]Account, error) {
+	rows, err := q.db.Query(ctx, listAccountsAfter,
+		arg.Owner,
+		arg.Currency,
+		arg.ProductType,
+		arg.Status,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.Status,
+			&i.ClosedAt,
+			&i.Nickname,
+			&i.ProductType,
+			&i.IsDefault,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setDefaultAccount = `-- name: SetDefaultAccount :one
+UPDATE accounts
+SET is_default = true
+WHERE id = $1
+RETURNING id, owner, balance, currency, created_at, status, closed_at, nickname, product_type, is_default
+`
+
+func (q *Queries) SetDefaultAccount(ctx context.Context, id int64) (Account, error) {
+	row := q.db.QueryRow(ctx, setDefaultAccount, id)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.Status,
+		&i.ClosedAt,
+		&i.Nickname,
+		&i.ProductType,
+		&i.IsDefault,
+	)
+	return i, err
+}
+
 const updateAccount = `-- name: UpdateAccount :one
 UPDATE accounts
 SET balance = $2
 WHERE id = $1
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, status, closed_at, nickname, product_type, is_default
 `
 
 type UpdateAccountParams struct {
@@ -171,6 +389,43 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Status,
+		&i.ClosedAt,
+		&i.Nickname,
+		&i.ProductType,
+		&i.IsDefault,
+	)
+	return i, err
+}
+
+const updateAccountStatus = `-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET
+  status = $1,
+  closed_at = CASE WHEN $1 = 'closed' THEN now() ELSE NULL END
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, status, closed_at, nickname, product_type, is_default
+`
+
+type UpdateAccountStatusParams struct {
+	Status string `json:"status"`
+	ID     int64  `json:"id"`
+}
+
+func (q *Queries) UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error) {
+	row := q.db.QueryRow(ctx, updateAccountStatus, arg.Status, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.Status,
+		&i.ClosedAt,
+		&i.Nickname,
+		&i.ProductType,
+		&i.IsDefault,
 	)
 	return i, err
 }
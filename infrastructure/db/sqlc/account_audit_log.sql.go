@@ -0,0 +1,142 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: account_audit_log.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createAccountAuditLog = `-- name: CreateAccountAuditLog :one
+INSERT INTO account_audit_logs (
+  account_id,
+  action,
+  from_status,
+  to_status,
+  actor,
+  reason
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING id, account_id, action, from_status, to_status, actor, reason, created_at
+`
+
+type CreateAccountAuditLogParams struct {
+	AccountID  int64  `json:"account_id"`
+	Action     string `json:"action"`
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+	Actor      string `json:"actor"`
+	Reason     string `json:"reason"`
+}
+
+func (q *Queries) CreateAccountAuditLog(ctx context.Context, arg CreateAccountAuditLogParams) (AccountAuditLog, error) {
+	row := q.db.QueryRow(ctx, createAccountAuditLog,
+		arg.AccountID,
+		arg.Action,
+		arg.FromStatus,
+		arg.ToStatus,
+		arg.Actor,
+		arg.Reason,
+	)
+	var i AccountAuditLog
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Action,
+		&i.FromStatus,
+		&i.ToStatus,
+		&i.Actor,
+		&i.Reason,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listAccountAuditLogs = `-- name: ListAccountAuditLogs :many
+SELECT id, account_id, action, from_status, to_status, actor, reason, created_at FROM account_audit_logs
+WHERE account_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3
+`
+
+type ListAccountAuditLogsParams struct {
+	AccountID int64 `json:"account_id"`
+	Limit     int32 `json:"limit"`
+	Offset    int32 `json:"offset"`
+}
+
+func (q *Queries) ListAccountAuditLogs(ctx context.Context, arg ListAccountAuditLogsParams) ([]AccountAuditLog, error) {
+	rows, err := q.db.Query(ctx, listAccountAuditLogs, arg.AccountID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AccountAuditLog{}
+	for rows.Next() {
+		var i AccountAuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Action,
+			&i.FromStatus,
+			&i.ToStatus,
+			&i.Actor,
+			&i.Reason,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountAuditLogsAfter = `-- name: ListAccountAuditLogsAfter :many
+SELECT id, account_id, action, from_status, to_status, actor, reason, created_at FROM account_audit_logs
+WHERE account_id = $1
+  AND ($2::bigint = 0 OR id < $2::bigint)
+ORDER BY id DESC
+LIMIT $3
+`
+
+type ListAccountAuditLogsAfterParams struct {
+	AccountID int64 `json:"account_id"`
+	AfterID   int64 `json:"after_id"`
+	Limit     int32 `json:"limit"`
+}
+
+// Newest first, so a page continues with the ids below after_id; 0 starts at the newest.
+func (q *Queries) ListAccountAuditLogsAfter(ctx context.Context, arg ListAccountAuditLogsAfterParams) ([]AccountAuditLog, error) {
+	rows, err := q.db.Query(ctx, listAccountAuditLogsAfter, arg.AccountID, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AccountAuditLog{}
+	for rows.Next() {
+		var i AccountAuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Action,
+			&i.FromStatus,
+			&i.ToStatus,
+			&i.Actor,
+			&i.Reason,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
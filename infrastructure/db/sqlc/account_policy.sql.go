@@ -0,0 +1,77 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: account_policy.sql
+
+package db
+
+import (
+	"context"
+)
+
+const getAccountPolicy = `-- name: GetAccountPolicy :one
+SELECT account_id, min_balance, overdraft_limit, unlimited_overdraft, updated_by, updated_at FROM account_policies
+WHERE account_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetAccountPolicy(ctx context.Context, accountID int64) (AccountPolicy, error) {
+	row := q.db.QueryRow(ctx, getAccountPolicy, accountID)
+	var i AccountPolicy
+	err := row.Scan(
+		&i.AccountID,
+		&i.MinBalance,
+		&i.OverdraftLimit,
+		&i.UnlimitedOverdraft,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertAccountPolicy = `-- name: UpsertAccountPolicy :one
+INSERT INTO account_policies (
+  account_id,
+  min_balance,
+  overdraft_limit,
+  unlimited_overdraft,
+  updated_by
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (account_id) DO UPDATE
+SET
+  min_balance = EXCLUDED.min_balance,
+  overdraft_limit = EXCLUDED.overdraft_limit,
+  unlimited_overdraft = EXCLUDED.unlimited_overdraft,
+  updated_by = EXCLUDED.updated_by,
+  updated_at = now()
+RETURNING account_id, min_balance, overdraft_limit, unlimited_overdraft, updated_by, updated_at
+`
+
+type UpsertAccountPolicyParams struct {
+	AccountID          int64  `json:"account_id"`
+	MinBalance         int64  `json:"min_balance"`
+	OverdraftLimit     int64  `json:"overdraft_limit"`
+	UnlimitedOverdraft bool   `json:"unlimited_overdraft"`
+	UpdatedBy          string `json:"updated_by"`
+}
+
+func (q *Queries) UpsertAccountPolicy(ctx context.Context, arg UpsertAccountPolicyParams) (AccountPolicy, error) {
+	row := q.db.QueryRow(ctx, upsertAccountPolicy,
+		arg.AccountID,
+		arg.MinBalance,
+		arg.OverdraftLimit,
+		arg.UnlimitedOverdraft,
+		arg.UpdatedBy,
+	)
+	var i AccountPolicy
+	err := row.Scan(
+		&i.AccountID,
+		&i.MinBalance,
+		&i.OverdraftLimit,
+		&i.UnlimitedOverdraft,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -7,8 +7,29 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
+const countAccountTransactionsInRange = `-- name: CountAccountTransactionsInRange :one
+SELECT COUNT(*) FROM account_transactions
+WHERE account_id = $1
+  AND created_at >= $2::timestamptz
+  AND created_at < $3::timestamptz
+`
+
+type CountAccountTransactionsInRangeParams struct {
+	AccountID int64     `json:"account_id"`
+	StartAt   time.Time `json:"start_at"`
+	EndAt     time.Time `json:"end_at"`
+}
+
+func (q *Queries) CountAccountTransactionsInRange(ctx context.Context, arg CountAccountTransactionsInRangeParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countAccountTransactionsInRange, arg.AccountID, arg.StartAt, arg.EndAt)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createAccountTransactions = `-- name: CreateAccountTransactions :one
 INSERT INTO account_transactions (
   account_id,
@@ -35,6 +56,27 @@ func (q *Queries) CreateAccountTransactions(ctx context.Context, arg CreateAccou
 	return i, err
 }
 
+const getAccountBalanceAt = `-- name: GetAccountBalanceAt :one
+SELECT (a.balance - COALESCE((
+  SELECT SUM(t.amount) FROM account_transactions t
+  WHERE t.account_id = a.id AND t.created_at >= $1::timestamptz
+), 0))::bigint AS balance
+FROM accounts a
+WHERE a.id = $2
+`
+
+type GetAccountBalanceAtParams struct {
+	At        time.Time `json:"at"`
+	AccountID int64     `json:"account_id"`
+}
+
+func (q *Queries) GetAccountBalanceAt(ctx context.Context, arg GetAccountBalanceAtParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getAccountBalanceAt, arg.At, arg.AccountID)
+	var balance int64
+	err := row.Scan(&balance)
+	return balance, err
+}
+
 const getAccountTransactions = `-- name: GetAccountTransactions :one
 SELECT id, account_id, amount, created_at FROM account_transactions
 WHERE id = $1 LIMIT 1
@@ -90,3 +132,87 @@ func (q *Queries) ListAccountTransactions(ctx context.Context, arg ListAccountTr
 	}
 	return items, nil
 }
+
+const listAccountTransactionsAfter = `-- name: ListAccountTransactionsAfter :many
+SELECT id, account_id, amount, created_at FROM account_transactions
+WHERE account_id = $1
+  AND (created_at, id) > ($2::timestamptz, $3::bigint)
+ORDER BY created_at, id
+LIMIT $4
+`
+
+type ListAccountTransactionsAfterParams struct {
+	AccountID      int64     `json:"account_id"`
+	AfterCreatedAt time.Time `json:"after_created_at"`
+	AfterID        int64     `json:"after_id"`
+	Limit          int32     `json:"limit"`
+}
+
+func (q *Queries) ListAccountTransactionsAfter(ctx context.Context, arg ListAccountTransactionsAfterParams) ([]AccountTransaction, error) {
+	rows, err := q.db.Query(ctx, listAccountTransactionsAfter,
+		arg.AccountID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AccountTransaction{}
+	for rows.Next() {
+		var i AccountTransaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountTransactionsInRange = `-- name: ListAccountTransactionsInRange :many
+SELECT id, account_id, amount, created_at FROM account_transactions
+WHERE account_id = $1
+  AND created_at >= $2::timestamptz
+  AND created_at < $3::timestamptz
+ORDER BY created_at, id
+`
+
+type ListAccountTransactionsInRangeParams struct {
+	AccountID int64     `json:"account_id"`
+	StartAt   time.Time `json:"start_at"`
+	EndAt     time.Time `json:"end_at"`
+}
+
+func (q *Queries) ListAccountTransactionsInRange(ctx context.Context, arg ListAccountTransactionsInRangeParams) ([]AccountTransaction, error) {
+	rows, err := q.db.Query(ctx, listAccountTransactionsInRange, arg.AccountID, arg.StartAt, arg.EndAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AccountTransaction{}
+	for rows.Next() {
+		var i AccountTransaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
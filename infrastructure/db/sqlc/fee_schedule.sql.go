@@ -0,0 +1,295 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: fee_schedule.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createFeeSchedule = `-- name: CreateFeeSchedule :one
+INSERT INTO fee_schedules (
+  name,
+  currency,
+  product_type,
+  flat_fee,
+  percentage_bps,
+  min_fee,
+  max_fee,
+  fee_account_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING id, name, currency, product_type, flat_fee, percentage_bps, min_fee, max_fee, fee_account_id, active, created_at
+`
+
+type CreateFeeScheduleParams struct {
+	Name          string      `json:"name"`
+	Currency      string      `json:"currency"`
+	ProductType   pgtype.Text `json:"product_type"`
+	FlatFee       int64       `json:"flat_fee"`
+	PercentageBps int32       `json:"percentage_bps"`
+	MinFee        int64       `json:"min_fee"`
+	MaxFee        pgtype.Int8 `json:"max_fee"`
+	FeeAccountID  int64       `json:"fee_account_id"`
+}
+
+func (q *Queries) CreateFeeSchedule(ctx context.Context, arg CreateFeeScheduleParams) (FeeSchedule, error) {
+	row := q.db.QueryRow(ctx, createFeeSchedule,
+		arg.Name,
+		arg.Currency,
+		arg.ProductType,
+		arg.FlatFee,
+		arg.PercentageBps,
+		arg.MinFee,
+		arg.MaxFee,
+		arg.FeeAccountID,
+	)
+	var i FeeSchedule
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Currency,
+		&i.ProductType,
+		&i.FlatFee,
+		&i.PercentageBps,
+		&i.MinFee,
+		&i.MaxFee,
+		&i.FeeAccountID,
+		&i.Active,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createTransferFee = `-- name: CreateTransferFee :one
+INSERT INTO transfer_fees (
+  transfer_id,
+  fee_schedule_id,
+  fee_account_id,
+  amount
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING id, transfer_id, fee_schedule_id, fee_account_id, amount, created_at
+`
+
+type CreateTransferFeeParams struct {
+	TransferID    int64 `json:"transfer_id"`
+	FeeScheduleID int64 `json:"fee_schedule_id"`
+	FeeAccountID  int64 `json:"fee_account_id"`
+	Amount        int64 `json:"amount"`
+}
+
+func (q *Queries) CreateTransferFee(ctx context.Context, arg CreateTransferFeeParams) (TransferFee, error) {
+	row := q.db.QueryRow(ctx, createTransferFee,
+		arg.TransferID,
+		arg.FeeScheduleID,
+		arg.FeeAccountID,
+		arg.Amount,
+	)
+	var i TransferFee
+	err := row.Scan(
+		&i.ID,
+		&i.TransferID,
+		&i.FeeScheduleID,
+		&i.FeeAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deactivateFeeSchedule = `-- name: DeactivateFeeSchedule :one
+UPDATE fee_schedules
+SET active = false
+WHERE id = $1
+RETURNING id, name, currency, product_type, flat_fee, percentage_bps, min_fee, max_fee, fee_account_id, active, created_at
+`
+
+func (q *Queries) DeactivateFeeSchedule(ctx context.Context, id int64) (FeeSchedule, error) {
+	row := q.db.QueryRow(ctx, deactivateFeeSchedule, id)
+	var i FeeSchedule
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Currency,
+		&i.ProductType,
+		&i.FlatFee,
+		&i.PercentageBps,
+		&i.MinFee,
+		&i.MaxFee,
+		&i.FeeAccountID,
+		&i.Active,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listApplicableFeeSchedules = `-- name: ListApplicableFeeSchedules :many
+SELECT id, name, currency, product_type, flat_fee, percentage_bps, min_fee, max_fee, fee_account_id, active, created_at FROM fee_schedules
+WHERE active
+  AND currency = $1
+  AND (product_type IS NULL OR product_type = $2)
+ORDER BY id
+`
+
+type ListApplicableFeeSchedulesParams struct {
+	Currency    string      `json:"currency"`
+	ProductType pgtype.Text `json:"product_type"`
+}
+
+func (q *Queries) ListApplicableFeeSchedules(ctx context.Context, arg ListApplicableFeeSchedulesParams) ([]FeeSchedule, error) {
+	rows, err := q.db.Query(ctx, listApplicableFeeSchedules, arg.Currency, arg.ProductType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FeeSchedule{}
+	for rows.Next() {
+		var i FeeSchedule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Currency,
+			&i.ProductType,
+			&i.FlatFee,
+			&i.PercentageBps,
+			&i.MinFee,
+			&i.MaxFee,
+			&i.FeeAccountID,
+			&i.Active,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFeeSchedules = `-- name: ListFeeSchedules :many
+SELECT id, name, currency, product_type, flat_fee, percentage_bps, min_fee, max_fee, fee_account_id, active, created_at FROM fee_schedules
+ORDER BY id
+LIMIT $1
+OFFSET $2
+`
+
+type ListFeeSchedulesParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+func (q *Queries) ListFeeSchedules(ctx context.Context, arg ListFeeSchedulesParams) ([]FeeSchedule, error) {
+	rows, err := q.db.Query(ctx, listFeeSchedules, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FeeSchedule{}
+	for rows.Next() {
+		var i FeeSchedule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Currency,
+			&i.ProductType,
+			&i.FlatFee,
+			&i.PercentageBps,
+			&i.MinFee,
+			&i.MaxFee,
+			&i.FeeAccountID,
+			&i.Active,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFeeSchedulesAfter = `-- name: ListFeeSchedulesAfter :many
+SELECT id, name, currency, product_type, flat_fee, percentage_bps, min_fee, max_fee, fee_account_id, active, created_at FROM fee_schedules
+WHERE id > $1::bigint
+ORDER BY id
+LIMIT $2
+`
+
+type ListFeeSchedulesAfterParams struct {
+	AfterID int64 `json:"after_id"`
+	Limit   int32 `json:"limit"`
+}
+
+func (q *Queries) ListFeeSchedulesAfter(ctx context.Context, arg ListFeeSchedulesAfterParams) ([]FeeSchedule, error) {
+	rows, err := q.db.Query(ctx, listFeeSchedulesAfter, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FeeSchedule{}
+	for rows.Next() {
+		var i FeeSchedule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Currency,
+			&i.ProductType,
+			&i.FlatFee,
+			&i.PercentageBps,
+			&i.MinFee,
+			&i.MaxFee,
+			&i.FeeAccountID,
+			&i.Active,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransferFees = `-- name: ListTransferFees :many
+SELECT id, transfer_id, fee_schedule_id, fee_account_id, amount, created_at FROM transfer_fees
+WHERE transfer_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListTransferFees(ctx context.Context, transferID int64) ([]TransferFee, error) {
+	rows, err := q.db.Query(ctx, listTransferFees, transferID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TransferFee{}
+	for rows.Next() {
+		var i TransferFee
+		if err := rows.Scan(
+			&i.ID,
+			&i.TransferID,
+			&i.FeeScheduleID,
+			&i.FeeAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,248 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: hold.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const addHoldCapturedAmount = `-- name: AddHoldCapturedAmount :one
+UPDATE holds
+SET
+  captured_amount = captured_amount + $1,
+  status = CASE WHEN captured_amount + $1 = amount THEN 'captured' ELSE status END,
+  updated_at = now()
+WHERE id = $2
+RETURNING id, account_id, to_account_id, amount, captured_amount, status, expires_at, created_at, updated_at
+`
+
+type AddHoldCapturedAmountParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddHoldCapturedAmount(ctx context.Context, arg AddHoldCapturedAmountParams) (Hold, error) {
+	row := q.db.QueryRow(ctx, addHoldCapturedAmount, arg.Amount, arg.ID)
+	var i Hold
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CapturedAmount,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createHold = `-- name: CreateHold :one
+INSERT INTO holds (
+  account_id,
+  to_account_id,
+  amount,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING id, account_id, to_account_id, amount, captured_amount, status, expires_at, created_at, updated_at
+`
+
+type CreateHoldParams struct {
+	AccountID   int64     `json:"account_id"`
+	ToAccountID int64     `json:"to_account_id"`
+	Amount      int64     `json:"amount"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error) {
+	row := q.db.QueryRow(ctx, createHold,
+		arg.AccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ExpiresAt,
+	)
+	var i Hold
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CapturedAmount,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createHoldCapture = `-- name: CreateHoldCapture :one
+INSERT INTO hold_captures (
+  hold_id,
+  transfer_id,
+  amount
+) VALUES (
+  $1, $2, $3
+) RETURNING id, hold_id, transfer_id, amount, created_at
+`
+
+type CreateHoldCaptureParams struct {
+	HoldID     int64 `json:"hold_id"`
+	TransferID int64 `json:"transfer_id"`
+	Amount     int64 `json:"amount"`
+}
+
+func (q *Queries) CreateHoldCapture(ctx context.Context, arg CreateHoldCaptureParams) (HoldCapture, error) {
+	row := q.db.QueryRow(ctx, createHoldCapture, arg.HoldID, arg.TransferID, arg.Amount)
+	var i HoldCapture
+	err := row.Scan(
+		&i.ID,
+		&i.HoldID,
+		&i.TransferID,
+		&i.Amount,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const expireHolds = `-- name: ExpireHolds :execrows
+UPDATE holds
+SET
+  status = 'expired',
+  updated_at = now()
+WHERE status = 'active' AND expires_at <= now()
+`
+
+func (q *Queries) ExpireHolds(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, expireHolds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getAccountHeldAmount = `-- name: GetAccountHeldAmount :one
+SELECT COALESCE(SUM(amount - captured_amount), 0)::bigint FROM holds
+WHERE account_id = $1 AND status = 'active' AND expires_at > now()
+`
+
+func (q *Queries) GetAccountHeldAmount(ctx context.Context, accountID int64) (int64, error) {
+	row := q.db.QueryRow(ctx, getAccountHeldAmount, accountID)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const getHold = `-- name: GetHold :one
+SELECT id, account_id, to_account_id, amount, captured_amount, status, expires_at, created_at, updated_at FROM holds
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetHold(ctx context.Context, id int64) (Hold, error) {
+	row := q.db.QueryRow(ctx, getHold, id)
+	var i Hold
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CapturedAmount,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getHoldForUpdate = `-- name: GetHoldForUpdate :one
+SELECT id, account_id, to_account_id, amount, captured_amount, status, expires_at, created_at, updated_at FROM holds
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetHoldForUpdate(ctx context.Context, id int64) (Hold, error) {
+	row := q.db.QueryRow(ctx, getHoldForUpdate, id)
+	var i Hold
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CapturedAmount,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listHoldCaptures = `-- name: ListHoldCaptures :many
+SELECT id, hold_id, transfer_id, amount, created_at FROM hold_captures
+WHERE hold_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListHoldCaptures(ctx context.Context, holdID int64) ([]HoldCapture, error) {
+	rows, err := q.db.Query(ctx, listHoldCaptures, holdID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []HoldCapture{}
+	for rows.Next() {
+		var i HoldCapture
+		if err := rows.Scan(
+			&i.ID,
+			&i.HoldID,
+			&i.TransferID,
+			&i.Amount,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateHoldStatus = `-- name: UpdateHoldStatus :one
+UPDATE holds
+SET
+  status = $2,
+  updated_at = now()
+WHERE id = $1
+RETURNING id, account_id, to_account_id, amount, captured_amount, status, expires_at, created_at, updated_at
+`
+
+type UpdateHoldStatusParams struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+}
+
+func (q *Queries) UpdateHoldStatus(ctx context.Context, arg UpdateHoldStatusParams) (Hold, error) {
+	row := q.db.QueryRow(ctx, updateHoldStatus, arg.ID, arg.Status)
+	var i Hold
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CapturedAmount,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
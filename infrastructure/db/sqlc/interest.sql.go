@@ -0,0 +1,356 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: interest.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createInterestAccrual = `-- name: CreateInterestAccrual :execrows
+INSERT INTO interest_accruals (
+  account_id,
+  accrual_date,
+  balance,
+  annual_rate_bps,
+  amount
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (account_id, accrual_date) DO NOTHING
+`
+
+type CreateInterestAccrualParams struct {
+	AccountID     int64       `json:"account_id"`
+	AccrualDate   pgtype.Date `json:"accrual_date"`
+	Balance       int64       `json:"balance"`
+	AnnualRateBps int32       `json:"annual_rate_bps"`
+	Amount        int64       `json:"amount"`
+}
+
+func (q *Queries) CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (int64, error) {
+	result, err := q.db.Exec(ctx, createInterestAccrual,
+		arg.AccountID,
+		arg.AccrualDate,
+		arg.Balance,
+		arg.AnnualRateBps,
+		arg.Amount,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const createInterestAccrualRun = `-- name: CreateInterestAccrualRun :exec
+INSERT INTO interest_accrual_runs (
+  accrual_date,
+  accounts
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (accrual_date) DO UPDATE
+SET accounts = interest_accrual_runs.accounts + EXCLUDED.accounts
+`
+
+type CreateInterestAccrualRunParams struct {
+	AccrualDate pgtype.Date `json:"accrual_date"`
+	Accounts    int64       `json:"accounts"`
+}
+
+func (q *Queries) CreateInterestAccrualRun(ctx context.Context, arg CreateInterestAccrualRunParams) error {
+	_, err := q.db.Exec(ctx, createInterestAccrualRun, arg.AccrualDate, arg.Accounts)
+	return err
+}
+
+const createInterestPosting = `-- name: CreateInterestPosting :one
+INSERT INTO interest_postings (
+  account_id,
+  period_end,
+  amount,
+  account_transaction_id
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING id, account_id, period_end, amount, account_transaction_id, created_at
+`
+
+type CreateInterestPostingParams struct {
+	AccountID            int64       `json:"account_id"`
+	PeriodEnd            pgtype.Date `json:"period_end"`
+	Amount               int64       `json:"amount"`
+	AccountTransactionID int64       `json:"account_transaction_id"`
+}
+
+func (q *Queries) CreateInterestPosting(ctx context.Context, arg CreateInterestPostingParams) (InterestPosting, error) {
+	row := q.db.QueryRow(ctx, createInterestPosting,
+		arg.AccountID,
+		arg.PeriodEnd,
+		arg.Amount,
+		arg.AccountTransactionID,
+	)
+	var i InterestPosting
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.PeriodEnd,
+		&i.Amount,
+		&i.AccountTransactionID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getInterestRate = `-- name: GetInterestRate :one
+SELECT product_type, annual_rate_bps, updated_by, updated_at FROM interest_rates
+WHERE product_type = $1 LIMIT 1
+`
+
+func (q *Queries) GetInterestRate(ctx context.Context, productType string) (InterestRate, error) {
+	row := q.db.QueryRow(ctx, getInterestRate, productType)
+	var i InterestRate
+	err := row.Scan(
+		&i.ProductType,
+		&i.AnnualRateBps,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getLatestInterestAccrualRun = `-- name: GetLatestInterestAccrualRun :one
+SELECT accrual_date FROM interest_accrual_runs
+ORDER BY accrual_date DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestInterestAccrualRun(ctx context.Context) (pgtype.Date, error) {
+	row := q.db.QueryRow(ctx, getLatestInterestAccrualRun)
+	var accrual_date pgtype.Date
+	err := row.Scan(&accrual_date)
+	return accrual_date, err
+}
+
+const listAccountsWithUnpostedInterest = `-- name: ListAccountsWithUnpostedInterest :many
+SELECT DISTINCT account_id FROM interest_accruals
+WHERE posted_at IS NULL
+  AND accrual_date < $1
+  AND account_id > $2
+ORDER BY account_id
+LIMIT $3
+`
+
+type ListAccountsWithUnpostedInterestParams struct {
+	PeriodEnd pgtype.Date `json:"period_end"`
+	AfterID   int64       `json:"after_id"`
+	Limit     int32       `json:"limit"`
+}
+
+func (q *Queries) ListAccountsWithUnpostedInterest(ctx context.Context, arg ListAccountsWithUnpostedInterestParams) ([]int64, error) {
+	rows, err := q.db.Query(ctx, listAccountsWithUnpostedInterest, arg.PeriodEnd, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var account_id int64
+		if err := rows.Scan(&account_id); err != nil {
+			return nil, err
+		}
+		items = append(items, account_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInterestAccruals = `-- name: ListInterestAccruals :many
+SELECT id, account_id, accrual_date, balance, annual_rate_bps, amount, posted_at, created_at FROM interest_accruals
+WHERE account_id = $1
+ORDER BY accrual_date DESC
+LIMIT $2
+OFFSET $3
+`
+
+type ListInterestAccrualsParams struct {
+	AccountID int64 `json:"account_id"`
+	Limit     int32 `json:"limit"`
+	Offset    int32 `json:"offset"`
+}
+
+func (q *Queries) ListInterestAccruals(ctx context.Context, arg ListInterestAccrualsParams) ([]InterestAccrual, error) {
+	rows, err := q.db.Query(ctx, listInterestAccruals, arg.AccountID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []InterestAccrual{}
+	for rows.Next() {
+		var i InterestAccrual
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.AccrualDate,
+			&i.Balance,
+			&i.AnnualRateBps,
+			&i.Amount,
+			&i.PostedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInterestBearingAccounts = `-- name: ListInterestBearingAccounts :many
+SELECT a.id, r.annual_rate_bps
+FROM accounts a
+JOIN interest_rates r ON r.product_type = a.product_type
+WHERE a.status IN ('active', 'frozen')
+  AND a.created_at < $1::timestamptz
+  AND r.annual_rate_bps > 0
+  AND a.id > $2
+ORDER BY a.id
+LIMIT $3
+`
+
+type ListInterestBearingAccountsParams struct {
+	CreatedBefore time.Time `json:"created_before"`
+	AfterID       int64     `json:"after_id"`
+	Limit         int32     `json:"limit"`
+}
+
+type ListInterestBearingAccountsRow struct {
+	ID            int64 `json:"id"`
+	AnnualRateBps int32 `json:"annual_rate_bps"`
+}
+
+// Balances are not filtered, the balance of the accrual day may differ from the current one.
+func (q *Queries) ListInterestBearingAccounts(ctx context.Context, arg ListInterestBearingAccountsParams) ([]ListInterestBearingAccountsRow, error) {
+	rows, err := q.db.Query(ctx, listInterestBearingAccounts, arg.CreatedBefore, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListInterestBearingAccountsRow{}
+	for rows.Next() {
+		var i ListInterestBearingAccountsRow
+		if err := rows.Scan(&i.ID, &i.AnnualRateBps); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInterestRates = `-- name: ListInterestRates :many
+SELECT product_type, annual_rate_bps, updated_by, updated_at FROM interest_rates
+ORDER BY product_type
+`
+
+func (q *Queries) ListInterestRates(ctx context.Context) ([]InterestRate, error) {
+	rows, err := q.db.Query(ctx, listInterestRates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []InterestRate{}
+	for rows.Next() {
+		var i InterestRate
+		if err := rows.Scan(
+			&i.ProductType,
+			&i.AnnualRateBps,
+			&i.UpdatedBy,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markInterestAccrualsPosted = `-- name: MarkInterestAccrualsPosted :many
+UPDATE interest_accruals
+SET posted_at = now()
+WHERE account_id = $1
+  AND posted_at IS NULL
+  AND accrual_date < $2
+RETURNING amount
+`
+
+type MarkInterestAccrualsPostedParams struct {
+	AccountID int64       `json:"account_id"`
+	PeriodEnd pgtype.Date `json:"period_end"`
+}
+
+func (q *Queries) MarkInterestAccrualsPosted(ctx context.Context, arg MarkInterestAccrualsPostedParams) ([]int64, error) {
+	rows, err := q.db.Query(ctx, markInterestAccrualsPosted, arg.AccountID, arg.PeriodEnd)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var amount int64
+		if err := rows.Scan(&amount); err != nil {
+			return nil, err
+		}
+		items = append(items, amount)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertInterestRate = `-- name: UpsertInterestRate :one
+INSERT INTO interest_rates (
+  product_type,
+  annual_rate_bps,
+  updated_by
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (product_type) DO UPDATE
+SET
+  annual_rate_bps = EXCLUDED.annual_rate_bps,
+  updated_by = EXCLUDED.updated_by,
+  updated_at = now()
+RETURNING product_type, annual_rate_bps, updated_by, updated_at
+`
+
+type UpsertInterestRateParams struct {
+	ProductType   string      `json:"product_type"`
+	AnnualRateBps int32       `json:"annual_rate_bps"`
+	UpdatedBy     pgtype.Text `json:"updated_by"`
+}
+
+func (q *Queries) UpsertInterestRate(ctx context.Context, arg UpsertInterestRateParams) (InterestRate, error) {
+	row := q.db.QueryRow(ctx, upsertInterestRate, arg.ProductType, arg.AnnualRateBps, arg.UpdatedBy)
+	var i InterestRate
+	err := row.Scan(
+		&i.ProductType,
+		&i.AnnualRateBps,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
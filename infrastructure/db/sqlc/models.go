@@ -11,30 +11,222 @@ import (
 )
 
 type Account struct {
-	ID        int64              `json:"id"`
-	Owner     string             `json:"owner"`
-	Balance   int64              `json:"balance"`
-	Currency  string             `json:"currency"`
+	ID        int64     `json:"id"`
+	Owner     string    `json:"owner"`
+	Balance   int64     `json:"balance"`
+	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	// active, frozen or closed
+	Status string `json:"status"`
+	// set while status is closed
+	ClosedAt pgtype.Timestamptz `json:"closed_at"`
+	// unique per owner when set
+	Nickname    string `json:"nickname"`
+	ProductType string `json:"product_type"`
+	// at most one default account per owner and currency
+	IsDefault bool `json:"is_default"`
+}
+
+type AccountAuditLog struct {
+	ID         int64     `json:"id"`
+	AccountID  int64     `json:"account_id"`
+	Action     string    `json:"action"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Actor      string    `json:"actor"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type AccountPolicy struct {
+	AccountID int64 `json:"account_id"`
+	// lowest balance allowed before the overdraft limit applies
+	MinBalance int64 `json:"min_balance"`
+	// how far below min_balance the balance may go
+	OverdraftLimit int64 `json:"overdraft_limit"`
+	// system accounts that may go negative without limit
+	UnlimitedOverdraft bool      `json:"unlimited_overdraft"`
+	UpdatedBy          string    `json:"updated_by"`
+	UpdatedAt          time.Time `json:"updated_at"`
 }
 
 type AccountTransaction struct {
 	ID        int64 `json:"id"`
 	AccountID int64 `json:"account_id"`
 	// can be negative or positive
-	Amount    int64              `json:"amount"`
+	Amount    int64     `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type FeeSchedule struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Currency string `json:"currency"`
+	// null applies to every product type
+	ProductType pgtype.Text `json:"product_type"`
+	FlatFee     int64       `json:"flat_fee"`
+	// percentage of the amount in basis points, clamped to min_fee and max_fee
+	PercentageBps int32 `json:"percentage_bps"`
+	MinFee        int64 `json:"min_fee"`
+	// null means no cap
+	MaxFee       pgtype.Int8 `json:"max_fee"`
+	FeeAccountID int64       `json:"fee_account_id"`
+	Active       bool        `json:"active"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+type Hold struct {
+	ID          int64 `json:"id"`
+	AccountID   int64 `json:"account_id"`
+	ToAccountID int64 `json:"to_account_id"`
+	// must be positive
+	Amount int64 `json:"amount"`
+	// never exceeds amount
+	CapturedAmount int64 `json:"captured_amount"`
+	// active, captured, released or expired
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type HoldCapture struct {
+	ID         int64     `json:"id"`
+	HoldID     int64     `json:"hold_id"`
+	TransferID int64     `json:"transfer_id"`
+	Amount     int64     `json:"amount"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type InterestAccrual struct {
+	ID          int64       `json:"id"`
+	AccountID   int64       `json:"account_id"`
+	AccrualDate pgtype.Date `json:"accrual_date"`
+	// balance the interest was accrued on
+	Balance       int64 `json:"balance"`
+	AnnualRateBps int32 `json:"annual_rate_bps"`
+	Amount        int64 `json:"amount"`
+	// set once the amount is credited to the account
+	PostedAt  pgtype.Timestamptz `json:"posted_at"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+type InterestAccrualRun struct {
+	AccrualDate pgtype.Date `json:"accrual_date"`
+	// number of accruals recorded for the day
+	Accounts  int64     `json:"accounts"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type InterestPosting struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	// accruals before this date are included
+	PeriodEnd            pgtype.Date `json:"period_end"`
+	Amount               int64       `json:"amount"`
+	AccountTransactionID int64       `json:"account_transaction_id"`
+	CreatedAt            time.Time   `json:"created_at"`
+}
+
+type InterestRate struct {
+	ProductType string `json:"product_type"`
+	// annual interest rate in basis points
+	AnnualRateBps int32       `json:"annual_rate_bps"`
+	UpdatedBy     pgtype.Text `json:"updated_by"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+}
+
+type Outbox struct {
+	ID            int64  `json:"id"`
+	AggregateType string `json:"aggregate_type"`
+	AggregateID   string `json:"aggregate_id"`
+	// task type the event is published as
+	EventType string      `json:"event_type"`
+	Payload   []byte      `json:"payload"`
+	Attempts  int32       `json:"attempts"`
+	LastError pgtype.Text `json:"last_error"`
+	CreatedAt time.Time   `json:"created_at"`
+	// set once the event was enqueued on the task manager
+	PublishedAt pgtype.Timestamptz `json:"published_at"`
+}
+
+type RecurringTransfer struct {
+	ID            int64  `json:"id"`
+	Owner         string `json:"owner"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	// must be positive
+	Amount int64 `json:"amount"`
+	// standard cron expression evaluated in UTC
+	Schedule        string             `json:"schedule"`
+	StartAt         time.Time          `json:"start_at"`
+	EndAt           pgtype.Timestamptz `json:"end_at"`
+	MaxOccurrences  pgtype.Int4        `json:"max_occurrences"`
+	OccurrenceCount int32              `json:"occurrence_count"`
+	// active, paused, completed or deleted
+	Status    string             `json:"status"`
+	NextRunAt pgtype.Timestamptz `json:"next_run_at"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+type RecurringTransferOccurrence struct {
+	ID                  int64     `json:"id"`
+	RecurringTransferID int64     `json:"recurring_transfer_id"`
+	ScheduledFor        time.Time `json:"scheduled_for"`
+	// pending, executed or failed
+	Status        string      `json:"status"`
+	TransferID    pgtype.Int8 `json:"transfer_id"`
+	FailureReason pgtype.Text `json:"failure_reason"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+}
+
+type ScheduledTransfer struct {
+	ID            int64  `json:"id"`
+	Owner         string `json:"owner"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	// must be positive
+	Amount    int64     `json:"amount"`
+	ExecuteAt time.Time `json:"execute_at"`
+	// scheduled, executed, failed or cancelled
+	Status        string      `json:"status"`
+	TransferID    pgtype.Int8 `json:"transfer_id"`
+	FailureReason pgtype.Text `json:"failure_reason"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+}
+
 type Session struct {
-	ID           pgtype.UUID        `json:"id"`
-	Username     string             `json:"username"`
-	RefreshToken string             `json:"refresh_token"`
-	UserAgent    string             `json:"user_agent"`
-	ClientIp     string             `json:"client_ip"`
-	IsBlocked    bool               `json:"is_blocked"`
-	ExpiresAt    time.Time `json:"expires_at"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID           pgtype.UUID `json:"id"`
+	Username     string      `json:"username"`
+	RefreshToken string      `json:"refresh_token"`
+	UserAgent    string      `json:"user_agent"`
+	ClientIp     string      `json:"client_ip"`
+	IsBlocked    bool        `json:"is_blocked"`
+	ExpiresAt    time.Time   `json:"expires_at"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+type TransactionExport struct {
+	ID        int64  `json:"id"`
+	Owner     string `json:"owner"`
+	AccountID int64  `json:"account_id"`
+	// csv, ofx or camt053
+	Format  string    `json:"format"`
+	StartAt time.Time `json:"start_at"`
+	EndAt   time.Time `json:"end_at"`
+	// pending, processing, completed or failed
+	Status string `json:"status"`
+	// large object holding the generated file
+	FileOid       pgtype.Int8        `json:"file_oid"`
+	FileSize      pgtype.Int8        `json:"file_size"`
+	FailureReason pgtype.Text        `json:"failure_reason"`
+	CreatedAt     time.Time          `json:"created_at"`
+	CompletedAt   pgtype.Timestamptz `json:"completed_at"`
+	// when a worker started generating the file
+	ClaimedAt pgtype.Timestamptz `json:"claimed_at"`
 }
 
 type Transfer struct {
@@ -42,17 +234,109 @@ type Transfer struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
 	// must be positive
-	Amount    int64              `json:"amount"`
+	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// pending, completed, failed or reversed
+	Status string `json:"status"`
+	// set when status is failed
+	FailureReason pgtype.Text `json:"failure_reason"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+	// free text set by the sender, searchable
+	Description string `json:"description"`
+}
+
+type TransferBatch struct {
+	ID    int64  `json:"id"`
+	Owner string `json:"owner"`
+	// all_or_nothing or best_effort
+	Mode string `json:"mode"`
+	// pending, processing, completed, partially_completed or failed
+	Status      string             `json:"status"`
+	LineCount   int32              `json:"line_count"`
+	TotalAmount int64              `json:"total_amount"`
+	CreatedAt   time.Time          `json:"created_at"`
+	CompletedAt pgtype.Timestamptz `json:"completed_at"`
+}
+
+type TransferBatchLine struct {
+	ID            int64 `json:"id"`
+	BatchID       int64 `json:"batch_id"`
+	LineNumber    int32 `json:"line_number"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	Amount        int64 `json:"amount"`
+	// pending, completed or failed
+	Status        string      `json:"status"`
+	TransferID    pgtype.Int8 `json:"transfer_id"`
+	FailureReason pgtype.Text `json:"failure_reason"`
+}
+
+type TransferFee struct {
+	ID            int64     `json:"id"`
+	TransferID    int64     `json:"transfer_id"`
+	FeeScheduleID int64     `json:"fee_schedule_id"`
+	FeeAccountID  int64     `json:"fee_account_id"`
+	Amount        int64     `json:"amount"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type TransferLimit struct {
+	ID int64 `json:"id"`
+	// role, user or account
+	Scope string `json:"scope"`
+	// role name, username or account id
+	Subject           string      `json:"subject"`
+	PerTransferAmount pgtype.Int8 `json:"per_transfer_amount"`
+	// null means unlimited, as for every other limit column
+	DailyAmount   pgtype.Int8 `json:"daily_amount"`
+	DailyCount    pgtype.Int4 `json:"daily_count"`
+	MonthlyAmount pgtype.Int8 `json:"monthly_amount"`
+	MonthlyCount  pgtype.Int4 `json:"monthly_count"`
+	UpdatedBy     pgtype.Text `json:"updated_by"`
+	UpdatedAt     time.Time   `json:"updated_at"`
 }
 
 type User struct {
-	Username          string             `json:"username"`
-	Role              string             `json:"role"`
-	HashedPassword    string             `json:"hashed_password"`
-	FullName          string             `json:"full_name"`
-	Email             string             `json:"email"`
-	IsEmailVerified   bool               `json:"is_email_verified"`
+	Username          string    `json:"username"`
+	Role              string    `json:"role"`
+	HashedPassword    string    `json:"hashed_password"`
+	FullName          string    `json:"full_name"`
+	Email             string    `json:"email"`
+	IsEmailVerified   bool      `json:"is_email_verified"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
 }
+
+type WebhookDelivery struct {
+	ID             int64  `json:"id"`
+	SubscriptionID int64  `json:"subscription_id"`
+	EventID        string `json:"event_id"`
+	EventType      string `json:"event_type"`
+	Payload        []byte `json:"payload"`
+	// pending, succeeded or failed
+	Status   string `json:"status"`
+	Attempts int32  `json:"attempts"`
+	// HTTP status of the last attempt
+	ResponseCode  pgtype.Int4        `json:"response_code"`
+	LastError     pgtype.Text        `json:"last_error"`
+	RedeliveryOf  pgtype.Int8        `json:"redelivery_of"`
+	CreatedAt     time.Time          `json:"created_at"`
+	LastAttemptAt pgtype.Timestamptz `json:"last_attempt_at"`
+	DeliveredAt   pgtype.Timestamptz `json:"delivered_at"`
+	// when the next attempt of a pending delivery is due
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+}
+
+type WebhookSubscription struct {
+	ID    int64  `json:"id"`
+	Owner string `json:"owner"`
+	Url   string `json:"url"`
+	// event patterns such as transfer.completed or transfer.*
+	EventTypes []string `json:"event_types"`
+	Secret     string   `json:"secret"`
+	// active or disabled
+	Status              string             `json:"status"`
+	ConsecutiveFailures int32              `json:"consecutive_failures"`
+	DisabledAt          pgtype.Timestamptz `json:"disabled_at"`
+	CreatedAt           time.Time          `json:"created_at"`
+}
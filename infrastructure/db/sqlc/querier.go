@@ -6,29 +6,156 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AddHoldCapturedAmount(ctx context.Context, arg AddHoldCapturedAmountParams) (Hold, error)
+	AdvanceRecurringTransfer(ctx context.Context, arg AdvanceRecurringTransferParams) (RecurringTransfer, error)
+	CancelScheduledTransfer(ctx context.Context, arg CancelScheduledTransferParams) (ScheduledTransfer, error)
+	// Locks the oldest unpublished event of every aggregate, so events of one aggregate are published in order.
+	// Events that waited for their aggregate before come last, so blocked aggregates cannot starve the others.
+	ClaimOutboxEvents(ctx context.Context, limit int32) ([]Outbox, error)
+	// Claims a pending export, or one whose worker claimed it before stale_before and presumably died.
+	ClaimTransactionExport(ctx context.Context, arg ClaimTransactionExportParams) (TransactionExport, error)
+	ClaimTransferBatch(ctx context.Context, id int64) (TransferBatch, error)
+	ClearDefaultAccount(ctx context.Context, arg ClearDefaultAccountParams) error
+	ClearTransactionExportFile(ctx context.Context, id int64) error
+	// Only the worker holding the claim may complete the export.
+	CompleteTransactionExport(ctx context.Context, arg CompleteTransactionExportParams) (TransactionExport, error)
+	CompleteTransferBatch(ctx context.Context, arg CompleteTransferBatchParams) (TransferBatch, error)
+	CompleteTransferBatchLine(ctx context.Context, arg CompleteTransferBatchLineParams) (TransferBatchLine, error)
+	CountAccountTransactionsInRange(ctx context.Context, arg CountAccountTransactionsInRangeParams) (int64, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAccountAuditLog(ctx context.Context, arg CreateAccountAuditLogParams) (AccountAuditLog, error)
 	CreateAccountTransactions(ctx context.Context, arg CreateAccountTransactionsParams) (AccountTransaction, error)
+	CreateFeeSchedule(ctx context.Context, arg CreateFeeScheduleParams) (FeeSchedule, error)
+	CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error)
+	CreateHoldCapture(ctx context.Context, arg CreateHoldCaptureParams) (HoldCapture, error)
+	CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (int64, error)
+	CreateInterestAccrualRun(ctx context.Context, arg CreateInterestAccrualRunParams) error
+	CreateInterestPosting(ctx context.Context, arg CreateInterestPostingParams) (InterestPosting, error)
+	CreateNamedAccount(ctx context.Context, arg CreateNamedAccountParams) (Account, error)
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (Outbox, error)
+	CreateRecurringTransfer(ctx context.Context, arg CreateRecurringTransferParams) (RecurringTransfer, error)
+	CreateRecurringTransferOccurrence(ctx context.Context, arg CreateRecurringTransferOccurrenceParams) (RecurringTransferOccurrence, error)
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateTransactionExport(ctx context.Context, arg CreateTransactionExportParams) (TransactionExport, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateTransferBatch(ctx context.Context, arg CreateTransferBatchParams) (TransferBatch, error)
+	CreateTransferBatchLine(ctx context.Context, arg CreateTransferBatchLineParams) (TransferBatchLine, error)
+	CreateTransferFee(ctx context.Context, arg CreateTransferFeeParams) (TransferFee, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	// Returns the existing delivery if the event was already fanned out to the subscription.
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (WebhookDelivery, error)
+	CreateWebhookRedelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error)
+	DeactivateFeeSchedule(ctx context.Context, id int64) (FeeSchedule, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	DeletePublishedOutboxEvents(ctx context.Context, publishedBefore time.Time) (int64, error)
+	DeleteTransferLimit(ctx context.Context, arg DeleteTransferLimitParams) error
+	DeleteWebhookSubscription(ctx context.Context, id int64) error
+	EnableWebhookSubscription(ctx context.Context, id int64) (WebhookSubscription, error)
+	ExpireHolds(ctx context.Context) (int64, error)
+	FailOutboxEvent(ctx context.Context, arg FailOutboxEventParams) error
+	// Only the worker holding the claim may fail the export.
+	FailTransactionExport(ctx context.Context, arg FailTransactionExportParams) (TransactionExport, error)
+	FailTransferBatchLine(ctx context.Context, arg FailTransferBatchLineParams) (TransferBatchLine, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	GetAccountBalanceAt(ctx context.Context, arg GetAccountBalanceAtParams) (int64, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAccountHeldAmount(ctx context.Context, accountID int64) (int64, error)
+	GetAccountPolicy(ctx context.Context, accountID int64) (AccountPolicy, error)
 	GetAccountTransactions(ctx context.Context, id int64) (AccountTransaction, error)
+	GetAccountTransferUsage(ctx context.Context, arg GetAccountTransferUsageParams) (GetAccountTransferUsageRow, error)
+	GetDefaultAccount(ctx context.Context, arg GetDefaultAccountParams) (Account, error)
+	GetHold(ctx context.Context, id int64) (Hold, error)
+	GetHoldForUpdate(ctx context.Context, id int64) (Hold, error)
+	GetInterestRate(ctx context.Context, productType string) (InterestRate, error)
+	GetLatestInterestAccrualRun(ctx context.Context) (pgtype.Date, error)
+	GetOwnerTransferUsage(ctx context.Context, arg GetOwnerTransferUsageParams) (GetOwnerTransferUsageRow, error)
+	GetRecurringTransfer(ctx context.Context, id int64) (RecurringTransfer, error)
+	GetRecurringTransferForUpdate(ctx context.Context, id int64) (RecurringTransfer, error)
+	GetRecurringTransferOccurrenceForUpdate(ctx context.Context, id int64) (RecurringTransferOccurrence, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetScheduledTransferForUpdate(ctx context.Context, id int64) (ScheduledTransfer, error)
 	GetSession(ctx context.Context, id pgtype.UUID) (Session, error)
+	GetTransactionExport(ctx context.Context, id int64) (TransactionExport, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferBatch(ctx context.Context, id int64) (TransferBatch, error)
+	GetTransferBatchLineForUpdate(ctx context.Context, id int64) (TransferBatchLine, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
+	GetTransferLimit(ctx context.Context, arg GetTransferLimitParams) (TransferLimit, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	GetWebhookSubscription(ctx context.Context, id int64) (WebhookSubscription, error)
+	ListAccountAuditLogs(ctx context.Context, arg ListAccountAuditLogsParams) ([]AccountAuditLog, error)
+	// Newest first, so a page continues with the ids below after_id; 0 starts at the newest.
+	ListAccountAuditLogsAfter(ctx context.Context, arg ListAccountAuditLogsAfterParams) ([]AccountAuditLog, error)
 	ListAccountTransactions(ctx context.Context, arg ListAccountTransactionsParams) ([]AccountTransaction, error)
+	ListAccountTransactionsAfter(ctx context.Context, arg ListAccountTransactionsAfterParams) ([]AccountTransaction, error)
+	ListAccountTransactionsInRange(ctx context.Context, arg ListAccountTransactionsInRangeParams) ([]AccountTransaction, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListAccountsAfter(ctx context.Context, arg ListAccountsAfterParams) ([]Account, error)
+	ListAccountsWithUnpostedInterest(ctx context.Context, arg ListAccountsWithUnpostedInterestParams) ([]int64, error)
+	ListActiveWebhookSubscriptions(ctx context.Context, owners []string) ([]WebhookSubscription, error)
+	ListApplicableFeeSchedules(ctx context.Context, arg ListApplicableFeeSchedulesParams) ([]FeeSchedule, error)
+	ListDueRecurringTransfers(ctx context.Context, arg ListDueRecurringTransfersParams) ([]RecurringTransfer, error)
+	// Locks completed exports whose file was generated before completed_before.
+	ListExpiredTransactionExports(ctx context.Context, arg ListExpiredTransactionExportsParams) ([]TransactionExport, error)
+	ListFeeSchedules(ctx context.Context, arg ListFeeSchedulesParams) ([]FeeSchedule, error)
+	ListFeeSchedulesAfter(ctx context.Context, arg ListFeeSchedulesAfterParams) ([]FeeSchedule, error)
+	ListHoldCaptures(ctx context.Context, holdID int64) ([]HoldCapture, error)
+	ListInterestAccruals(ctx context.Context, arg ListInterestAccrualsParams) ([]InterestAccrual, error)
+	// Balances are not filtered, the balance of the accrual day may differ from the current one.
+	ListInterestBearingAccounts(ctx context.Context, arg ListInterestBearingAccountsParams) ([]ListInterestBearingAccountsRow, error)
+	ListInterestRates(ctx context.Context) ([]InterestRate, error)
+	// Pending deliveries whose next attempt was due before due_before.
+	ListOverdueWebhookDeliveries(ctx context.Context, arg ListOverdueWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListPendingRecurringTransferOccurrences(ctx context.Context, limit int32) ([]RecurringTransferOccurrence, error)
+	ListRecurringTransfers(ctx context.Context, arg ListRecurringTransfersParams) ([]RecurringTransfer, error)
+	ListRecurringTransfersAfter(ctx context.Context, arg ListRecurringTransfersAfterParams) ([]RecurringTransfer, error)
+	ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error)
+	ListScheduledTransfersAfter(ctx context.Context, arg ListScheduledTransfersAfterParams) ([]ScheduledTransfer, error)
+	ListTransferBatchLines(ctx context.Context, batchID int64) ([]TransferBatchLine, error)
+	ListTransferBatchLinesForUpdate(ctx context.Context, batchID int64) ([]TransferBatchLine, error)
+	ListTransferBatches(ctx context.Context, arg ListTransferBatchesParams) ([]TransferBatch, error)
+	// Newest first, so a page continues with the ids below after_id; 0 starts at the newest.
+	ListTransferBatchesAfter(ctx context.Context, arg ListTransferBatchesAfterParams) ([]TransferBatch, error)
+	ListTransferFees(ctx context.Context, transferID int64) ([]TransferFee, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	ListTransfersAfter(ctx context.Context, arg ListTransfersAfterParams) ([]Transfer, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	// Newest first, so a page continues with the ids below after_id; 0 starts at the newest.
+	ListWebhookDeliveriesAfter(ctx context.Context, arg ListWebhookDeliveriesAfterParams) ([]WebhookDelivery, error)
+	ListWebhookSubscriptions(ctx context.Context, owner string) ([]WebhookSubscription, error)
+	LockOwnerTransferLimits(ctx context.Context, owner string) error
+	MarkInterestAccrualsPosted(ctx context.Context, arg MarkInterestAccrualsPostedParams) ([]int64, error)
+	MarkOutboxEventPublished(ctx context.Context, id int64) error
+	// Records the outcome of an attempt; status stays pending while the delivery is retried.
+	// Only the attempt the delivery expects is recorded, so a repeated attempt is not counted twice.
+	RecordWebhookDeliveryAttempt(ctx context.Context, arg RecordWebhookDeliveryAttemptParams) (WebhookDelivery, error)
+	// Disables the subscription once it failed max_failures times in a row.
+	RecordWebhookFailure(ctx context.Context, arg RecordWebhookFailureParams) (WebhookSubscription, error)
+	RecordWebhookSuccess(ctx context.Context, id int64) error
+	// Returns a claimed export to pending, so the retried task can claim it again right away.
+	ReleaseTransactionExport(ctx context.Context, arg ReleaseTransactionExportParams) (TransactionExport, error)
+	SetDefaultAccount(ctx context.Context, id int64) (Account, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error)
+	UpdateHoldStatus(ctx context.Context, arg UpdateHoldStatusParams) (Hold, error)
+	UpdateRecurringTransferOccurrence(ctx context.Context, arg UpdateRecurringTransferOccurrenceParams) (RecurringTransferOccurrence, error)
+	UpdateRecurringTransferStatus(ctx context.Context, arg UpdateRecurringTransferStatusParams) (RecurringTransfer, error)
+	UpdateScheduledTransferStatus(ctx context.Context, arg UpdateScheduledTransferStatusParams) (ScheduledTransfer, error)
+	UpdateTransferStatus(ctx context.Context, arg UpdateTransferStatusParams) (Transfer, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	UpsertAccountPolicy(ctx context.Context, arg UpsertAccountPolicyParams) (AccountPolicy, error)
+	UpsertInterestRate(ctx context.Context, arg UpsertInterestRateParams) (InterestRate, error)
+	UpsertTransferLimit(ctx context.Context, arg UpsertTransferLimitParams) (TransferLimit, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,479 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: recurring_transfer.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const advanceRecurringTransfer = `-- name: AdvanceRecurringTransfer :one
+UPDATE recurring_transfers
+SET
+  occurrence_count = occurrence_count + 1,
+  next_run_at = $1,
+  status = $2,
+  updated_at = now()
+WHERE id = $3
+RETURNING id, owner, from_account_id, to_account_id, amount, schedule, start_at, end_at, max_occurrences, occurrence_count, status, next_run_at, created_at, updated_at
+`
+
+type AdvanceRecurringTransferParams struct {
+	NextRunAt pgtype.Timestamptz `json:"next_run_at"`
+	Status    string             `json:"status"`
+	ID        int64              `json:"id"`
+}
+
+func (q *Queries) AdvanceRecurringTransfer(ctx context.Context, arg AdvanceRecurringTransferParams) (RecurringTransfer, error) {
+	row := q.db.QueryRow(ctx, advanceRecurringTransfer, arg.NextRunAt, arg.Status, arg.ID)
+	var i RecurringTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Schedule,
+		&i.StartAt,
+		&i.EndAt,
+		&i.MaxOccurrences,
+		&i.OccurrenceCount,
+		&i.Status,
+		&i.NextRunAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createRecurringTransfer = `-- name: CreateRecurringTransfer :one
+INSERT INTO recurring_transfers (
+  owner,
+  from_account_id,
+  to_account_id,
+  amount,
+  schedule,
+  start_at,
+  end_at,
+  max_occurrences,
+  next_run_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING id, owner, from_account_id, to_account_id, amount, schedule, start_at, end_at, max_occurrences, occurrence_count, status, next_run_at, created_at, updated_at
+`
+
+type CreateRecurringTransferParams struct {
+	Owner          string             `json:"owner"`
+	FromAccountID  int64              `json:"from_account_id"`
+	ToAccountID    int64              `json:"to_account_id"`
+	Amount         int64              `json:"amount"`
+	Schedule       string             `json:"schedule"`
+	StartAt        time.Time          `json:"start_at"`
+	EndAt          pgtype.Timestamptz `json:"end_at"`
+	MaxOccurrences pgtype.Int4        `json:"max_occurrences"`
+	NextRunAt      pgtype.Timestamptz `json:"next_run_at"`
+}
+
+func (q *Queries) CreateRecurringTransfer(ctx context.Context, arg CreateRecurringTransferParams) (RecurringTransfer, error) {
+	row := q.db.QueryRow(ctx, createRecurringTransfer,
+		arg.Owner,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Schedule,
+		arg.StartAt,
+		arg.EndAt,
+		arg.MaxOccurrences,
+		arg.NextRunAt,
+	)
+	var i RecurringTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Schedule,
+		&i.StartAt,
+		&i.EndAt,
+		&i.MaxOccurrences,
+		&i.OccurrenceCount,
+		&i.Status,
+		&i.NextRunAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createRecurringTransferOccurrence = `-- name: CreateRecurringTransferOccurrence :one
+INSERT INTO recurring_transfer_occurrences (
+  recurring_transfer_id,
+  scheduled_for
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (recurring_transfer_id, scheduled_for) DO NOTHING
+RETURNING id, recurring_transfer_id, scheduled_for, status, transfer_id, failure_reason, created_at, updated_at
+`
+
+type CreateRecurringTransferOccurrenceParams struct {
+	RecurringTransferID int64     `json:"recurring_transfer_id"`
+	ScheduledFor        time.Time `json:"scheduled_for"`
+}
+
+func (q *Queries) CreateRecurringTransferOccurrence(ctx context.Context, arg CreateRecurringTransferOccurrenceParams) (RecurringTransferOccurrence, error) {
+	row := q.db.QueryRow(ctx, createRecurringTransferOccurrence, arg.RecurringTransferID, arg.ScheduledFor)
+	var i RecurringTransferOccurrence
+	err := row.Scan(
+		&i.ID,
+		&i.RecurringTransferID,
+		&i.ScheduledFor,
+		&i.Status,
+		&i.TransferID,
+		&i.FailureReason,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getRecurringTransfer = `-- name: GetRecurringTransfer :one
+SELECT id, owner, from_account_id, to_account_id, amount, schedule, start_at, end_at, max_occurrences, occurrence_count, status, next_run_at, created_at, updated_at FROM recurring_transfers
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetRecurringTransfer(ctx context.Context, id int64) (RecurringTransfer, error) {
+	row := q.db.QueryRow(ctx, getRecurringTransfer, id)
+	var i RecurringTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Schedule,
+		&i.StartAt,
+		&i.EndAt,
+		&i.MaxOccurrences,
+		&i.OccurrenceCount,
+		&i.Status,
+		&i.NextRunAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getRecurringTransferForUpdate = `-- name: GetRecurringTransferForUpdate :one
+SELECT id, owner, from_account_id, to_account_id, amount, schedule, start_at, end_at, max_occurrences, occurrence_count, status, next_run_at, created_at, updated_at FROM recurring_transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetRecurringTransferForUpdate(ctx context.Context, id int64) (RecurringTransfer, error) {
+	row := q.db.QueryRow(ctx, getRecurringTransferForUpdate, id)
+	var i RecurringTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Schedule,
+		&i.StartAt,
+		&i.EndAt,
+		&i.MaxOccurrences,
+		&i.OccurrenceCount,
+		&i.Status,
+		&i.NextRunAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getRecurringTransferOccurrenceForUpdate = `-- name: GetRecurringTransferOccurrenceForUpdate :one
+SELECT id, recurring_transfer_id, scheduled_for, status, transfer_id, failure_reason, created_at, updated_at FROM recurring_transfer_occurrences
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetRecurringTransferOccurrenceForUpdate(ctx context.Context, id int64) (RecurringTransferOccurrence, error) {
+	row := q.db.QueryRow(ctx, getRecurringTransferOccurrenceForUpdate, id)
+	var i RecurringTransferOccurrence
+	err := row.Scan(
+		&i.ID,
+		&i.RecurringTransferID,
+		&i.ScheduledFor,
+		&i.Status,
+		&i.TransferID,
+		&i.FailureReason,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listDueRecurringTransfers = `-- name: ListDueRecurringTransfers :many
+SELECT id, owner, from_account_id, to_account_id, amount, schedule, start_at, end_at, max_occurrences, occurrence_count, status, next_run_at, created_at, updated_at FROM recurring_transfers
+WHERE status = 'active' AND next_run_at <= $1::timestamptz
+ORDER BY next_run_at
+LIMIT $2
+`
+
+type ListDueRecurringTransfersParams struct {
+	DueAt time.Time `json:"due_at"`
+	Limit int32     `json:"limit"`
+}
+
+func (q *Queries) ListDueRecurringTransfers(ctx context.Context, arg ListDueRecurringTransfersParams) ([]RecurringTransfer, error) {
+	rows, err := q.db.Query(ctx, listDueRecurringTransfers, arg.DueAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []RecurringTransfer{}
+	for rows.Next() {
+		var i RecurringTransfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.Schedule,
+			&i.StartAt,
+			&i.EndAt,
+			&i.MaxOccurrences,
+			&i.OccurrenceCount,
+			&i.Status,
+			&i.NextRunAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPendingRecurringTransferOccurrences = `-- name: ListPendingRecurringTransferOccurrences :many
+SELECT id, recurring_transfer_id, scheduled_for, status, transfer_id, failure_reason, created_at, updated_at FROM recurring_transfer_occurrences
+WHERE status = 'pending'
+ORDER BY id
+LIMIT $1
+`
+
+func (q *Queries) ListPendingRecurringTransferOccurrences(ctx context.Context, limit int32) ([]RecurringTransferOccurrence, error) {
+	rows, err := q.db.Query(ctx, listPendingRecurringTransferOccurrences, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []RecurringTransferOccurrence{}
+	for rows.Next() {
+		var i RecurringTransferOccurrence
+		if err := rows.Scan(
+			&i.ID,
+			&i.RecurringTransferID,
+			&i.ScheduledFor,
+			&i.Status,
+			&i.TransferID,
+			&i.FailureReason,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecurringTransfers = `-- name: ListRecurringTransfers :many
+SELECT id, owner, from_account_id, to_account_id, amount, schedule, start_at, end_at, max_occurrences, occurrence_count, status, next_run_at, created_at, updated_at FROM recurring_transfers
+WHERE owner = $1 AND status <> 'deleted'
+ORDER BY id
+LIMIT $2
+OFFSET $3
+`
+
+type ListRecurringTransfersParams struct {
+	Owner  string `json:"owner"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+}
+
+func (q *Queries) ListRecurringTransfers(ctx context.Context, arg ListRecurringTransfersParams) ([]RecurringTransfer, error) {
+	rows, err := q.db.Query(ctx, listRecurringTransfers, arg.Owner, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []RecurringTransfer{}
+	for rows.Next() {
+		var i RecurringTransfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.Schedule,
+			&i.StartAt,
+			&i.EndAt,
+			&i.MaxOccurrences,
+			&i.OccurrenceCount,
+			&i.Status,
+			&i.NextRunAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecurringTransfersAfter = `-- name: ListRecurringTransfersAfter :many
+SELECT id, owner, from_account_id, to_account_id, amount, schedule, start_at, end_at, max_occurrences, occurrence_count, status, next_run_at, created_at, updated_at FROM recurring_transfers
+WHERE owner = $1 AND status <> 'deleted'
+  AND id > $2::bigint
+ORDER BY id
+LIMIT $3
+`
+
+type ListRecurringTransfersAfterParams struct {
+	Owner   string `json:"owner"`
+	AfterID int64  `json:"after_id"`
+	Limit   int32  `json:"limit"`
+}
+
+func (q *Queries) ListRecurringTransfersAfter(ctx context.Context, arg ListRecurringTransfersAfterParams) ([]RecurringTransfer, error) {
+	rows, err := q.db.Query(ctx, listRecurringTransfersAfter, arg.Owner, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []RecurringTransfer{}
+	for rows.Next() {
+		var i RecurringTransfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.Schedule,
+			&i.StartAt,
+			&i.EndAt,
+			&i.MaxOccurrences,
+			&i.OccurrenceCount,
+			&i.Status,
+			&i.NextRunAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateRecurringTransferOccurrence = `-- name: UpdateRecurringTransferOccurrence :one
+UPDATE recurring_transfer_occurrences
+SET
+  status = $1,
+  transfer_id = $2,
+  failure_reason = $3,
+  updated_at = now()
+WHERE id = $4
+RETURNING id, recurring_transfer_id, scheduled_for, status, transfer_id, failure_reason, created_at, updated_at
+`
+
+type UpdateRecurringTransferOccurrenceParams struct {
+	Status        string      `json:"status"`
+	TransferID    pgtype.Int8 `json:"transfer_id"`
+	FailureReason pgtype.Text `json:"failure_reason"`
+	ID            int64       `json:"id"`
+}
+
+func (q *Queries) UpdateRecurringTransferOccurrence(ctx context.Context, arg UpdateRecurringTransferOccurrenceParams) (RecurringTransferOccurrence, error) {
+	row := q.db.QueryRow(ctx, updateRecurringTransferOccurrence,
+		arg.Status,
+		arg.TransferID,
+		arg.FailureReason,
+		arg.ID,
+	)
+	var i RecurringTransferOccurrence
+	err := row.Scan(
+		&i.ID,
+		&i.RecurringTransferID,
+		&i.ScheduledFor,
+		&i.Status,
+		&i.TransferID,
+		&i.FailureReason,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateRecurringTransferStatus = `-- name: UpdateRecurringTransferStatus :one
+UPDATE recurring_transfers
+SET
+  status = $1,
+  next_run_at = $2,
+  updated_at = now()
+WHERE id = $3
+RETURNING id, owner, from_account_id, to_account_id, amount, schedule, start_at, end_at, max_occurrences, occurrence_count, status, next_run_at, created_at, updated_at
+`
+
+type UpdateRecurringTransferStatusParams struct {
+	Status    string             `json:"status"`
+	NextRunAt pgtype.Timestamptz `json:"next_run_at"`
+	ID        int64              `json:"id"`
+}
+
+func (q *Queries) UpdateRecurringTransferStatus(ctx context.Context, arg UpdateRecurringTransferStatusParams) (RecurringTransfer, error) {
+	row := q.db.QueryRow(ctx, updateRecurringTransferStatus, arg.Status, arg.NextRunAt, arg.ID)
+	var i RecurringTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Schedule,
+		&i.StartAt,
+		&i.EndAt,
+		&i.MaxOccurrences,
+		&i.OccurrenceCount,
+		&i.Status,
+		&i.NextRunAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
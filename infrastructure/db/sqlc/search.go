@@ -0,0 +1,164 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// searchTransactionsResults lists transfers and account transactions as one result set.
+// A transfer is seen from the searched account when one is given, otherwise from its sender,
+// so amount is negative for money leaving account_id.
+const searchTransactionsResults = `
+WITH results AS (
+  SELECT
+    'transfer'::varchar AS kind,
+    t.id,
+    CASE WHEN t.to_account_id = $2::bigint THEN t.to_account_id ELSE t.from_account_id END AS account_id,
+    CASE WHEN t.to_account_id = $2::bigint THEN t.from_account_id ELSE t.to_account_id END AS counterparty_account_id,
+    CASE WHEN t.to_account_id = $2::bigint THEN t.amount ELSE -t.amount END AS amount,
+    t.status,
+    t.description,
+    t.created_at
+  FROM transfers t
+  WHERE ($1::varchar IS NULL
+      OR t.from_account_id IN (SELECT id FROM accounts WHERE owner = $1::varchar)
+      OR t.to_account_id IN (SELECT id FROM accounts WHERE owner = $1::varchar))
+    AND ($2::bigint IS NULL OR t.from_account_id = $2::bigint OR t.to_account_id = $2::bigint)
+    AND ($3::bigint IS NULL
+      OR ($2::bigint IS NULL AND (t.from_account_id = $3::bigint OR t.to_account_id = $3::bigint))
+      OR (t.from_account_id = $2::bigint AND t.to_account_id = $3::bigint)
+      OR (t.to_account_id = $2::bigint AND t.from_account_id = $3::bigint))
+    AND ($8::varchar IS NULL OR t.status = $8::varchar)
+    AND ($9::varchar IS NULL OR to_tsvector('simple', t.description) @@ plainto_tsquery('simple', $9::varchar))
+  UNION ALL
+  SELECT
+    'entry'::varchar,
+    e.id,
+    e.account_id,
+    NULL::bigint,
+    e.amount,
+    'posted'::varchar,
+    ''::varchar,
+    e.created_at
+  FROM account_transactions e
+  WHERE ($1::varchar IS NULL OR e.account_id IN (SELECT id FROM accounts WHERE owner = $1::varchar))
+    AND ($2::bigint IS NULL OR e.account_id = $2::bigint)
+    AND $3::bigint IS NULL
+    AND ($8::varchar IS NULL OR $8::varchar = 'posted')
+    AND $9::varchar IS NULL
+)
+SELECT kind, id, account_id, counterparty_account_id, amount, status, description, created_at
+FROM results
+WHERE ($4::bigint IS NULL OR abs(amount) >= $4::bigint)
+  AND ($5::bigint IS NULL OR abs(amount) <= $5::bigint)
+  AND ($6::timestamptz IS NULL OR created_at >= $6::timestamptz)
+  AND ($7::timestamptz IS NULL OR created_at < $7::timestamptz)
+  AND ($10::varchar IS NULL OR kind = $10::varchar)
+`
+
+// TransactionSearchResult is a transfer or an account transaction found by SearchTransactions.
+type TransactionSearchResult struct {
+	Kind                  string      `json:"kind"`
+	ID                    int64       `json:"id"`
+	AccountID             int64       `json:"account_id"`
+	CounterpartyAccountID pgtype.Int8 `json:"counterparty_account_id"`
+	Amount                int64       `json:"amount"`
+	Status                string      `json:"status"`
+	Description           string      `json:"description"`
+	CreatedAt             time.Time   `json:"created_at"`
+}
+
+// TransactionSearchKey is the sort key of a search result; results are ordered by
+// created_at or the absolute amount, then by kind and id.
+type TransactionSearchKey struct {
+	CreatedAt time.Time `json:"created_at"`
+	Amount    int64     `json:"amount"`
+	Kind      string    `json:"kind"`
+	ID        int64     `json:"id"`
+}
+
+type SearchTransactionsParams struct {
+	Owner                 pgtype.Text           `json:"owner"`
+	AccountID             pgtype.Int8           `json:"account_id"`
+	CounterpartyAccountID pgtype.Int8           `json:"counterparty_account_id"`
+	MinAmount             pgtype.Int8           `json:"min_amount"`
+	MaxAmount             pgtype.Int8           `json:"max_amount"`
+	StartAt               pgtype.Timestamptz    `json:"start_at"`
+	EndAt                 pgtype.Timestamptz    `json:"end_at"`
+	Status                pgtype.Text           `json:"status"`
+	Query                 pgtype.Text           `json:"query"`
+	Kind                  pgtype.Text           `json:"kind"`
+	SortByAmount          bool                  `json:"sort_by_amount"`
+	Descending            bool                  `json:"descending"`
+	After                 *TransactionSearchKey `json:"after"`
+	Limit                 int32                 `json:"limit"`
+}
+
+// SearchTransactions filters transfers and account transactions together and returns them
+// in keyset order. The sort column and direction vary, so the query is assembled here
+// instead of being generated by sqlc.
+func (q *Queries) SearchTransactions(ctx context.Context, arg SearchTransactionsParams) ([]TransactionSearchResult, error) {
+	args := []interface{}{
+		arg.Owner,
+		arg.AccountID,
+		arg.CounterpartyAccountID,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.StartAt,
+		arg.EndAt,
+		arg.Status,
+		arg.Query,
+		arg.Kind,
+	}
+
+	sortColumn, direction, comparison := "created_at", "ASC", ">"
+	if arg.SortByAmount {
+		sortColumn = "abs(amount)"
+	}
+	if arg.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	query := searchTransactionsResults
+	if arg.After != nil {
+		var sortValue interface{} = arg.After.CreatedAt
+		cast := "timestamptz"
+		if arg.SortByAmount {
+			sortValue, cast = arg.After.Amount, "bigint"
+		}
+		args = append(args, sortValue, arg.After.Kind, arg.After.ID)
+		query += fmt.Sprintf("  AND (%s, kind, id) %s ($11::%s, $12::varchar, $13::bigint)\n", sortColumn, comparison, cast)
+	}
+	args = append(args, arg.Limit)
+	query += fmt.Sprintf("ORDER BY %[1]s %[2]s, kind %[2]s, id %[2]s\nLIMIT $%[3]d", sortColumn, direction, len(args))
+
+	rows, err := q.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TransactionSearchResult{}
+	for rows.Next() {
+		var i TransactionSearchResult
+		if err := rows.Scan(
+			&i.Kind,
+			&i.ID,
+			&i.AccountID,
+			&i.CounterpartyAccountID,
+			&i.Amount,
+			&i.Status,
+			&i.Description,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
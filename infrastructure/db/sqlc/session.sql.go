@@ -8,6 +8,7 @@ package db
 import (
 	"context"
 	"time"
+
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
@@ -26,13 +27,13 @@ INSERT INTO sessions (
 `
 
 type CreateSessionParams struct {
-	ID           pgtype.UUID        `json:"id"`
-	Username     string             `json:"username"`
-	RefreshToken string             `json:"refresh_token"`
-	UserAgent    string             `json:"user_agent"`
-	ClientIp     string             `json:"client_ip"`
-	IsBlocked    bool               `json:"is_blocked"`
-	ExpiresAt    time.Time `json:"expires_at"`
+	ID           pgtype.UUID `json:"id"`
+	Username     string      `json:"username"`
+	RefreshToken string      `json:"refresh_token"`
+	UserAgent    string      `json:"user_agent"`
+	ClientIp     string      `json:"client_ip"`
+	IsBlocked    bool        `json:"is_blocked"`
+	ExpiresAt    time.Time   `json:"expires_at"`
 }
 
 func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
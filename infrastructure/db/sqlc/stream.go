@@ -0,0 +1,30 @@
+package db
+
+import (
+	"context"
+)
+
+// StreamAccountTransactionsInRange calls fn for every row of ListAccountTransactionsInRange
+// as it is read, so large histories never have to fit in memory.
+func (q *Queries) StreamAccountTransactionsInRange(ctx context.Context, arg ListAccountTransactionsInRangeParams, fn func(AccountTransaction) error) error {
+	rows, err := q.db.Query(ctx, listAccountTransactionsInRange, arg.AccountID, arg.StartAt, arg.EndAt)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var i AccountTransaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+		); err != nil {
+			return err
+		}
+		if err := fn(i); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,302 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: transaction_export.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const claimTransactionExport = `-- name: ClaimTransactionExport :one
+UPDATE transaction_exports
+SET status = 'processing', claimed_at = now()
+WHERE id = $1
+  AND (
+    status = 'pending'
+    OR (status = 'processing' AND COALESCE(claimed_at, created_at) < $2::timestamptz)
+  )
+RETURNING id, owner, account_id, format, start_at, end_at, status, file_oid, file_size, failure_reason, created_at, completed_at, claimed_at
+`
+
+type ClaimTransactionExportParams struct {
+	ID          int64     `json:"id"`
+	StaleBefore time.Time `json:"stale_before"`
+}
+
+// Claims a pending export, or one whose worker claimed it before stale_before and presumably died.
+func (q *Queries) ClaimTransactionExport(ctx context.Context, arg ClaimTransactionExportParams) (TransactionExport, error) {
+	row := q.db.QueryRow(ctx, claimTransactionExport, arg.ID, arg.StaleBefore)
+	var i TransactionExport
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.AccountID,
+		&i.Format,
+		&i.StartAt,
+		&i.EndAt,
+		&i.Status,
+		&i.FileOid,
+		&i.FileSize,
+		&i.FailureReason,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.ClaimedAt,
+	)
+	return i, err
+}
+
+const clearTransactionExportFile = `-- name: ClearTransactionExportFile :exec
+UPDATE transaction_exports
+SET file_oid = NULL
+WHERE id = $1
+`
+
+func (q *Queries) ClearTransactionExportFile(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, clearTransactionExportFile, id)
+	return err
+}
+
+const completeTransactionExport = `-- name: CompleteTransactionExport :one
+UPDATE transaction_exports
+SET
+  status = 'completed',
+  file_oid = $1,
+  file_size = $2,
+  completed_at = now()
+WHERE id = $3 AND status = 'processing' AND claimed_at = $4
+RETURNING id, owner, account_id, format, start_at, end_at, status, file_oid, file_size, failure_reason, created_at, completed_at, claimed_at
+`
+
+type CompleteTransactionExportParams struct {
+	FileOid   pgtype.Int8        `json:"file_oid"`
+	FileSize  pgtype.Int8        `json:"file_size"`
+	ID        int64              `json:"id"`
+	ClaimedAt pgtype.Timestamptz `json:"claimed_at"`
+}
+
+// Only the worker holding the claim may complete the export.
+func (q *Queries) CompleteTransactionExport(ctx context.Context, arg CompleteTransactionExportParams) (TransactionExport, error) {
+	row := q.db.QueryRow(ctx, completeTransactionExport,
+		arg.FileOid,
+		arg.FileSize,
+		arg.ID,
+		arg.ClaimedAt,
+	)
+	var i TransactionExport
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.AccountID,
+		&i.Format,
+		&i.StartAt,
+		&i.EndAt,
+		&i.Status,
+		&i.FileOid,
+		&i.FileSize,
+		&i.FailureReason,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.ClaimedAt,
+	)
+	return i, err
+}
+
+const createTransactionExport = `-- name: CreateTransactionExport :one
+INSERT INTO transaction_exports (
+  owner,
+  account_id,
+  format,
+  start_at,
+  end_at
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING id, owner, account_id, format, start_at, end_at, status, file_oid, file_size, failure_reason, created_at, completed_at, claimed_at
+`
+
+type CreateTransactionExportParams struct {
+	Owner     string    `json:"owner"`
+	AccountID int64     `json:"account_id"`
+	Format    string    `json:"format"`
+	StartAt   time.Time `json:"start_at"`
+	EndAt     time.Time `json:"end_at"`
+}
+
+func (q *Queries) CreateTransactionExport(ctx context.Context, arg CreateTransactionExportParams) (TransactionExport, error) {
+	row := q.db.QueryRow(ctx, createTransactionExport,
+		arg.Owner,
+		arg.AccountID,
+		arg.Format,
+		arg.StartAt,
+		arg.EndAt,
+	)
+	var i TransactionExport
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.AccountID,
+		&i.Format,
+		&i.StartAt,
+		&i.EndAt,
+		&i.Status,
+		&i.FileOid,
+		&i.FileSize,
+		&i.FailureReason,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.ClaimedAt,
+	)
+	return i, err
+}
+
+const failTransactionExport = `-- name: FailTransactionExport :one
+UPDATE transaction_exports
+SET
+  status = 'failed',
+  failure_reason = $1,
+  completed_at = now()
+WHERE id = $2 AND status = 'processing' AND claimed_at = $3
+RETURNING id, owner, account_id, format, start_at, end_at, status, file_oid, file_size, failure_reason, created_at, completed_at, claimed_at
+`
+
+type FailTransactionExportParams struct {
+	FailureReason pgtype.Text        `json:"failure_reason"`
+	ID            int64              `json:"id"`
+	ClaimedAt     pgtype.Timestamptz `json:"claimed_at"`
+}
+
+// Only the worker holding the claim may fail the export.
+func (q *Queries) FailTransactionExport(ctx context.Context, arg FailTransactionExportParams) (TransactionExport, error) {
+	row := q.db.QueryRow(ctx, failTransactionExport, arg.FailureReason, arg.ID, arg.ClaimedAt)
+	var i TransactionExport
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.AccountID,
+		&i.Format,
+		&i.StartAt,
+		&i.EndAt,
+		&i.Status,
+		&i.FileOid,
+		&i.FileSize,
+		&i.FailureReason,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.ClaimedAt,
+	)
+	return i, err
+}
+
+const getTransactionExport = `-- name: GetTransactionExport :one
+SELECT id, owner, account_id, format, start_at, end_at, status, file_oid, file_size, failure_reason, created_at, completed_at, claimed_at FROM transaction_exports
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetTransactionExport(ctx context.Context, id int64) (TransactionExport, error) {
+	row := q.db.QueryRow(ctx, getTransactionExport, id)
+	var i TransactionExport
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.AccountID,
+		&i.Format,
+		&i.StartAt,
+		&i.EndAt,
+		&i.Status,
+		&i.FileOid,
+		&i.FileSize,
+		&i.FailureReason,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.ClaimedAt,
+	)
+	return i, err
+}
+
+const listExpiredTransactionExports = `-- name: ListExpiredTransactionExports :many
+SELECT id, owner, account_id, format, start_at, end_at, status, file_oid, file_size, failure_reason, created_at, completed_at, claimed_at FROM transaction_exports
+WHERE status = 'completed'
+  AND file_oid IS NOT NULL
+  AND completed_at < $1::timestamptz
+ORDER BY completed_at
+LIMIT $2
+FOR UPDATE SKIP LOCKED
+`
+
+type ListExpiredTransactionExportsParams struct {
+	CompletedBefore time.Time `json:"completed_before"`
+	Limit           int32     `json:"limit"`
+}
+
+// Locks completed exports whose file was generated before completed_before.
+func (q *Queries) ListExpiredTransactionExports(ctx context.Context, arg ListExpiredTransactionExportsParams) ([]TransactionExport, error) {
+	rows, err := q.db.Query(ctx, listExpiredTransactionExports, arg.CompletedBefore, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TransactionExport{}
+	for rows.Next() {
+		var i TransactionExport
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.AccountID,
+			&i.Format,
+			&i.StartAt,
+			&i.EndAt,
+			&i.Status,
+			&i.FileOid,
+			&i.FileSize,
+			&i.FailureReason,
+			&i.CreatedAt,
+			&i.CompletedAt,
+			&i.ClaimedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const releaseTransactionExport = `-- name: ReleaseTransactionExport :one
+UPDATE transaction_exports
+SET status = 'pending', claimed_at = NULL
+WHERE id = $1 AND status = 'processing' AND claimed_at = $2
+RETURNING id, owner, account_id, format, start_at, end_at, status, file_oid, file_size, failure_reason, created_at, completed_at, claimed_at
+`
+
+type ReleaseTransactionExportParams struct {
+	ID        int64              `json:"id"`
+	ClaimedAt pgtype.Timestamptz `json:"claimed_at"`
+}
+
+// Returns a claimed export to pending, so the retried task can claim it again right away.
+func (q *Queries) ReleaseTransactionExport(ctx context.Context, arg ReleaseTransactionExportParams) (TransactionExport, error) {
+	row := q.db.QueryRow(ctx, releaseTransactionExport, arg.ID, arg.ClaimedAt)
+	var i TransactionExport
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.AccountID,
+		&i.Format,
+		&i.StartAt,
+		&i.EndAt,
+		&i.Status,
+		&i.FileOid,
+		&i.FileSize,
+		&i.FailureReason,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.ClaimedAt,
+	)
+	return i, err
+}
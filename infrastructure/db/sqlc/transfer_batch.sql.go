@@ -0,0 +1,414 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: transfer_batch.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const claimTransferBatch = `-- name: ClaimTransferBatch :one
+UPDATE transfer_batches
+SET status = 'processing'
+WHERE id = $1 AND status IN ('pending', 'processing')
+RETURNING id, owner, mode, status, line_count, total_amount, created_at, completed_at
+`
+
+func (q *Queries) ClaimTransferBatch(ctx context.Context, id int64) (TransferBatch, error) {
+	row := q.db.QueryRow(ctx, claimTransferBatch, id)
+	var i TransferBatch
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Mode,
+		&i.Status,
+		&i.LineCount,
+		&i.TotalAmount,
+		&i.CreatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const completeTransferBatch = `-- name: CompleteTransferBatch :one
+UPDATE transfer_batches
+SET
+  status = $1,
+  completed_at = now()
+WHERE id = $2
+RETURNING id, owner, mode, status, line_count, total_amount, created_at, completed_at
+`
+
+type CompleteTransferBatchParams struct {
+	Status string `json:"status"`
+	ID     int64  `json:"id"`
+}
+
+func (q *Queries) CompleteTransferBatch(ctx context.Context, arg CompleteTransferBatchParams) (TransferBatch, error) {
+	row := q.db.QueryRow(ctx, completeTransferBatch, arg.Status, arg.ID)
+	var i TransferBatch
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Mode,
+		&i.Status,
+		&i.LineCount,
+		&i.TotalAmount,
+		&i.CreatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const completeTransferBatchLine = `-- name: CompleteTransferBatchLine :one
+UPDATE transfer_batch_lines
+SET
+  status = 'completed',
+  transfer_id = $1,
+  failure_reason = NULL
+WHERE id = $2 AND status = 'pending'
+RETURNING id, batch_id, line_number, from_account_id, to_account_id, amount, status, transfer_id, failure_reason
+`
+
+type CompleteTransferBatchLineParams struct {
+	TransferID pgtype.Int8 `json:"transfer_id"`
+	ID         int64       `json:"id"`
+}
+
+func (q *Queries) CompleteTransferBatchLine(ctx context.Context, arg CompleteTransferBatchLineParams) (TransferBatchLine, error) {
+	row := q.db.QueryRow(ctx, completeTransferBatchLine, arg.TransferID, arg.ID)
+	var i TransferBatchLine
+	err := row.Scan(
+		&i.ID,
+		&i.BatchID,
+		&i.LineNumber,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Status,
+		&i.TransferID,
+		&i.FailureReason,
+	)
+	return i, err
+}
+
+const createTransferBatch = `-- name: CreateTransferBatch :one
+INSERT INTO transfer_batches (
+  owner,
+  mode,
+  line_count,
+  total_amount
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING id, owner, mode, status, line_count, total_amount, created_at, completed_at
+`
+
+type CreateTransferBatchParams struct {
+	Owner       string `json:"owner"`
+	Mode        string `json:"mode"`
+	LineCount   int32  `json:"line_count"`
+	TotalAmount int64  `json:"total_amount"`
+}
+
+func (q *Queries) CreateTransferBatch(ctx context.Context, arg CreateTransferBatchParams) (TransferBatch, error) {
+	row := q.db.QueryRow(ctx, createTransferBatch,
+		arg.Owner,
+		arg.Mode,
+		arg.LineCount,
+		arg.TotalAmount,
+	)
+	var i TransferBatch
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Mode,
+		&i.Status,
+		&i.LineCount,
+		&i.TotalAmount,
+		&i.CreatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const createTransferBatchLine = `-- name: CreateTransferBatchLine :one
+INSERT INTO transfer_batch_lines (
+  batch_id,
+  line_number,
+  from_account_id,
+  to_account_id,
+  amount
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING id, batch_id, line_number, from_account_id, to_account_id, amount, status, transfer_id, failure_reason
+`
+
+type CreateTransferBatchLineParams struct {
+	BatchID       int64 `json:"batch_id"`
+	LineNumber    int32 `json:"line_number"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	Amount        int64 `json:"amount"`
+}
+
+func (q *Queries) CreateTransferBatchLine(ctx context.Context, arg CreateTransferBatchLineParams) (TransferBatchLine, error) {
+	row := q.db.QueryRow(ctx, createTransferBatchLine,
+		arg.BatchID,
+		arg.LineNumber,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+	)
+	var i TransferBatchLine
+	err := row.Scan(
+		&i.ID,
+		&i.BatchID,
+		&i.LineNumber,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Status,
+		&i.TransferID,
+		&i.FailureReason,
+	)
+	return i, err
+}
+
+const failTransferBatchLine = `-- name: FailTransferBatchLine :one
+UPDATE transfer_batch_lines
+SET
+  status = 'failed',
+  failure_reason = $1
+WHERE id = $2 AND status = 'pending'
+RETURNING id, batch_id, line_number, from_account_id, to_account_id, amount, status, transfer_id, failure_reason
+`
+
+type FailTransferBatchLineParams struct {
+	FailureReason pgtype.Text `json:"failure_reason"`
+	ID            int64       `json:"id"`
+}
+
+func (q *Queries) FailTransferBatchLine(ctx context.Context, arg FailTransferBatchLineParams) (TransferBatchLine, error) {
+	row := q.db.QueryRow(ctx, failTransferBatchLine, arg.FailureReason, arg.ID)
+	var i TransferBatchLine
+	err := row.Scan(
+		&i.ID,
+		&i.BatchID,
+		&i.LineNumber,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Status,
+		&i.TransferID,
+		&i.FailureReason,
+	)
+	return i, err
+}
+
+const getTransferBatch = `-- name: GetTransferBatch :one
+SELECT id, owner, mode, status, line_count, total_amount, created_at, completed_at FROM transfer_batches
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetTransferBatch(ctx context.Context, id int64) (TransferBatch, error) {
+	row := q.db.QueryRow(ctx, getTransferBatch, id)
+	var i TransferBatch
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Mode,
+		&i.Status,
+		&i.LineCount,
+		&i.TotalAmount,
+		&i.CreatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const getTransferBatchLineForUpdate = `-- name: GetTransferBatchLineForUpdate :one
+SELECT id, batch_id, line_number, from_account_id, to_account_id, amount, status, transfer_id, failure_reason FROM transfer_batch_lines
+WHERE id = $1 LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetTransferBatchLineForUpdate(ctx context.Context, id int64) (TransferBatchLine, error) {
+	row := q.db.QueryRow(ctx, getTransferBatchLineForUpdate, id)
+	var i TransferBatchLine
+	err := row.Scan(
+		&i.ID,
+		&i.BatchID,
+		&i.LineNumber,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Status,
+		&i.TransferID,
+		&i.FailureReason,
+	)
+	return i, err
+}
+
+const listTransferBatchLines = `-- name: ListTransferBatchLines :many
+SELECT id, batch_id, line_number, from_account_id, to_account_id, amount, status, transfer_id, failure_reason FROM transfer_batch_lines
+WHERE batch_id = $1
+ORDER BY line_number
+`
+
+func (q *Queries) ListTransferBatchLines(ctx context.Context, batchID int64) ([]TransferBatchLine, error) {
+	rows, err := q.db.Query(ctx, listTransferBatchLines, batchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TransferBatchLine{}
+	for rows.Next() {
+		var i TransferBatchLine
+		if err := rows.Scan(
+			&i.ID,
+			&i.BatchID,
+			&i.LineNumber,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.Status,
+			&i.TransferID,
+			&i.FailureReason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransferBatchLinesForUpdate = `-- name: ListTransferBatchLinesForUpdate :many
+SELECT id, batch_id, line_number, from_account_id, to_account_id, amount, status, transfer_id, failure_reason FROM transfer_batch_lines
+WHERE batch_id = $1
+ORDER BY line_number
+FOR UPDATE
+`
+
+func (q *Queries) ListTransferBatchLinesForUpdate(ctx context.Context, batchID int64) ([]TransferBatchLine, error) {
+	rows, err := q.db.Query(ctx, listTransferBatchLinesForUpdate, batchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TransferBatchLine{}
+	for rows.Next() {
+		var i TransferBatchLine
+		if err := rows.Scan(
+			&i.ID,
+			&i.BatchID,
+			&i.LineNumber,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.Status,
+			&i.TransferID,
+			&i.FailureReason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransferBatches = `-- name: ListTransferBatches :many
+SELECT id, owner, mode, status, line_count, total_amount, created_at, completed_at FROM transfer_batches
+WHERE owner = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3
+`
+
+type ListTransferBatchesParams struct {
+	Owner  string `json:"owner"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+}
+
+func (q *Queries) ListTransferBatches(ctx context.Context, arg ListTransferBatchesParams) ([]TransferBatch, error) {
+	rows, err := q.db.Query(ctx, listTransferBatches, arg.Owner, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TransferBatch{}
+	for rows.Next() {
+		var i TransferBatch
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Mode,
+			&i.Status,
+			&i.LineCount,
+			&i.TotalAmount,
+			&i.CreatedAt,
+			&i.CompletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransferBatchesAfter = `-- name: ListTransferBatchesAfter :many
+SELECT id, owner, mode, status, line_count, total_amount, created_at, completed_at FROM transfer_batches
+WHERE owner = $1
+  AND ($2::bigint = 0 OR id < $2::bigint)
+ORDER BY id DESC
+LIMIT $3
+`
+
+type ListTransferBatchesAfterParams struct {
+	Owner   string `json:"owner"`
+	AfterID int64  `json:"after_id"`
+	Limit   int32  `json:"limit"`
+}
+
+// Newest first, so a page continues with the ids below after_id; 0 starts at the newest.
+func (q *Queries) ListTransferBatchesAfter(ctx context.Context, arg ListTransferBatchesAfterParams) ([]TransferBatch, error) {
+	rows, err := q.db.Query(ctx, listTransferBatchesAfter, arg.Owner, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TransferBatch{}
+	for rows.Next() {
+		var i TransferBatch
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Mode,
+			&i.Status,
+			&i.LineCount,
+			&i.TotalAmount,
+			&i.CreatedAt,
+			&i.CompletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,208 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: transfer_limit.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const deleteTransferLimit = `-- name: DeleteTransferLimit :exec
+DELETE FROM transfer_limits
+WHERE scope = $1 AND subject = $2
+`
+
+type DeleteTransferLimitParams struct {
+	Scope   string `json:"scope"`
+	Subject string `json:"subject"`
+}
+
+func (q *Queries) DeleteTransferLimit(ctx context.Context, arg DeleteTransferLimitParams) error {
+	_, err := q.db.Exec(ctx, deleteTransferLimit, arg.Scope, arg.Subject)
+	return err
+}
+
+const getAccountTransferUsage = `-- name: GetAccountTransferUsage :one
+SELECT
+  COALESCE(SUM(t.amount) FILTER (WHERE t.created_at >= $1::timestamptz), 0)::bigint AS daily_amount,
+  COUNT(*) FILTER (WHERE t.created_at >= $1::timestamptz) AS daily_count,
+  COALESCE(SUM(t.amount), 0)::bigint AS monthly_amount,
+  COUNT(*) AS monthly_count
+FROM transfers t
+JOIN accounts f ON f.id = t.from_account_id
+JOIN accounts d ON d.id = t.to_account_id
+WHERE t.from_account_id = $2
+  AND d.owner <> f.owner
+  AND t.status <> 'failed'
+  AND t.created_at >= $3::timestamptz
+`
+
+type GetAccountTransferUsageParams struct {
+	DayStart   time.Time `json:"day_start"`
+	AccountID  int64     `json:"account_id"`
+	MonthStart time.Time `json:"month_start"`
+}
+
+type GetAccountTransferUsageRow struct {
+	DailyAmount   int64 `json:"daily_amount"`
+	DailyCount    int64 `json:"daily_count"`
+	MonthlyAmount int64 `json:"monthly_amount"`
+	MonthlyCount  int64 `json:"monthly_count"`
+}
+
+func (q *Queries) GetAccountTransferUsage(ctx context.Context, arg GetAccountTransferUsageParams) (GetAccountTransferUsageRow, error) {
+	row := q.db.QueryRow(ctx, getAccountTransferUsage, arg.DayStart, arg.AccountID, arg.MonthStart)
+	var i GetAccountTransferUsageRow
+	err := row.Scan(
+		&i.DailyAmount,
+		&i.DailyCount,
+		&i.MonthlyAmount,
+		&i.MonthlyCount,
+	)
+	return i, err
+}
+
+const getOwnerTransferUsage = `-- name: GetOwnerTransferUsage :one
+SELECT
+  COALESCE(SUM(t.amount) FILTER (WHERE t.created_at >= $1::timestamptz), 0)::bigint AS daily_amount,
+  COUNT(*) FILTER (WHERE t.created_at >= $1::timestamptz) AS daily_count,
+  COALESCE(SUM(t.amount), 0)::bigint AS monthly_amount,
+  COUNT(*) AS monthly_count
+FROM transfers t
+JOIN accounts a ON a.id = t.from_account_id
+JOIN accounts d ON d.id = t.to_account_id
+WHERE a.owner = $2
+  AND d.owner <> a.owner
+  AND t.status <> 'failed'
+  AND t.created_at >= $3::timestamptz
+`
+
+type GetOwnerTransferUsageParams struct {
+	DayStart   time.Time `json:"day_start"`
+	Owner      string    `json:"owner"`
+	MonthStart time.Time `json:"month_start"`
+}
+
+type GetOwnerTransferUsageRow struct {
+	DailyAmount   int64 `json:"daily_amount"`
+	DailyCount    int64 `json:"daily_count"`
+	MonthlyAmount int64 `json:"monthly_amount"`
+	MonthlyCount  int64 `json:"monthly_count"`
+}
+
+func (q *Queries) GetOwnerTransferUsage(ctx context.Context, arg GetOwnerTransferUsageParams) (GetOwnerTransferUsageRow, error) {
+	row := q.db.QueryRow(ctx, getOwnerTransferUsage, arg.DayStart, arg.Owner, arg.MonthStart)
+	var i GetOwnerTransferUsageRow
+	err := row.Scan(
+		&i.DailyAmount,
+		&i.DailyCount,
+		&i.MonthlyAmount,
+		&i.MonthlyCount,
+	)
+	return i, err
+}
+
+const getTransferLimit = `-- name: GetTransferLimit :one
+SELECT id, scope, subject, per_transfer_amount, daily_amount, daily_count, monthly_amount, monthly_count, updated_by, updated_at FROM transfer_limits
+WHERE scope = $1 AND subject = $2 LIMIT 1
+`
+
+type GetTransferLimitParams struct {
+	Scope   string `json:"scope"`
+	Subject string `json:"subject"`
+}
+
+func (q *Queries) GetTransferLimit(ctx context.Context, arg GetTransferLimitParams) (TransferLimit, error) {
+	row := q.db.QueryRow(ctx, getTransferLimit, arg.Scope, arg.Subject)
+	var i TransferLimit
+	err := row.Scan(
+		&i.ID,
+		&i.Scope,
+		&i.Subject,
+		&i.PerTransferAmount,
+		&i.DailyAmount,
+		&i.DailyCount,
+		&i.MonthlyAmount,
+		&i.MonthlyCount,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const lockOwnerTransferLimits = `-- name: LockOwnerTransferLimits :exec
+SELECT pg_advisory_xact_lock(hashtext($1::text))
+`
+
+func (q *Queries) LockOwnerTransferLimits(ctx context.Context, owner string) error {
+	_, err := q.db.Exec(ctx, lockOwnerTransferLimits, owner)
+	return err
+}
+
+const upsertTransferLimit = `-- name: UpsertTransferLimit :one
+INSERT INTO transfer_limits (
+  scope,
+  subject,
+  per_transfer_amount,
+  daily_amount,
+  daily_count,
+  monthly_amount,
+  monthly_count,
+  updated_by
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+)
+ON CONFLICT (scope, subject) DO UPDATE
+SET
+  per_transfer_amount = EXCLUDED.per_transfer_amount,
+  daily_amount = EXCLUDED.daily_amount,
+  daily_count = EXCLUDED.daily_count,
+  monthly_amount = EXCLUDED.monthly_amount,
+  monthly_count = EXCLUDED.monthly_count,
+  updated_by = EXCLUDED.updated_by,
+  updated_at = now()
+RETURNING id, scope, subject, per_transfer_amount, daily_amount, daily_count, monthly_amount, monthly_count, updated_by, updated_at
+`
+
+type UpsertTransferLimitParams struct {
+	Scope             string      `json:"scope"`
+	Subject           string      `json:"subject"`
+	PerTransferAmount pgtype.Int8 `json:"per_transfer_amount"`
+	DailyAmount       pgtype.Int8 `json:"daily_amount"`
+	DailyCount        pgtype.Int4 `json:"daily_count"`
+	MonthlyAmount     pgtype.Int8 `json:"monthly_amount"`
+	MonthlyCount      pgtype.Int4 `json:"monthly_count"`
+	UpdatedBy         pgtype.Text `json:"updated_by"`
+}
+
+func (q *Queries) UpsertTransferLimit(ctx context.Context, arg UpsertTransferLimitParams) (TransferLimit, error) {
+	row := q.db.QueryRow(ctx, upsertTransferLimit,
+		arg.Scope,
+		arg.Subject,
+		arg.PerTransferAmount,
+		arg.DailyAmount,
+		arg.DailyCount,
+		arg.MonthlyAmount,
+		arg.MonthlyCount,
+		arg.UpdatedBy,
+	)
+	var i TransferLimit
+	err := row.Scan(
+		&i.ID,
+		&i.Scope,
+		&i.Subject,
+		&i.PerTransferAmount,
+		&i.DailyAmount,
+		&i.DailyCount,
+		&i.MonthlyAmount,
+		&i.MonthlyCount,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
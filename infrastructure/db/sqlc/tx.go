@@ -53,6 +53,7 @@ func (store *SQLStore) HandleFundsTransfer(ctx context.Context, arg HandleFundsT
 			FromAccountID: arg.FromAccountID,
 			ToAccountID:   arg.ToAccountID,
 			Amount:        arg.Amount,
+			Status:        "completed",
 		})
 		if err != nil {
 			return err
@@ -0,0 +1,538 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: webhook.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createWebhookDelivery = `-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (
+  subscription_id,
+  event_id,
+  event_type,
+  payload
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (subscription_id, event_id) WHERE redelivery_of IS NULL
+DO UPDATE SET event_id = EXCLUDED.event_id
+RETURNING id, subscription_id, event_id, event_type, payload, status, attempts, response_code, last_error, redelivery_of, created_at, last_attempt_at, delivered_at, next_attempt_at
+`
+
+type CreateWebhookDeliveryParams struct {
+	SubscriptionID int64  `json:"subscription_id"`
+	EventID        string `json:"event_id"`
+	EventType      string `json:"event_type"`
+	Payload        []byte `json:"payload"`
+}
+
+// Returns the existing delivery if the event was already fanned out to the subscription.
+func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (WebhookDelivery, error) {
+	row := q.db.QueryRow(ctx, createWebhookDelivery,
+		arg.SubscriptionID,
+		arg.EventID,
+		arg.EventType,
+		arg.Payload,
+	)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.SubscriptionID,
+		&i.EventID,
+		&i.EventType,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.ResponseCode,
+		&i.LastError,
+		&i.RedeliveryOf,
+		&i.CreatedAt,
+		&i.LastAttemptAt,
+		&i.DeliveredAt,
+		&i.NextAttemptAt,
+	)
+	return i, err
+}
+
+const createWebhookRedelivery = `-- name: CreateWebhookRedelivery :one
+INSERT INTO webhook_deliveries (
+  subscription_id,
+  event_id,
+  event_type,
+  payload,
+  redelivery_of
+)
+SELECT subscription_id, event_id, event_type, payload, id
+FROM webhook_deliveries d
+WHERE d.id = $1
+RETURNING id, subscription_id, event_id, event_type, payload, status, attempts, response_code, last_error, redelivery_of, created_at, last_attempt_at, delivered_at, next_attempt_at
+`
+
+func (q *Queries) CreateWebhookRedelivery(ctx context.Context, id int64) (WebhookDelivery, error) {
+	row := q.db.QueryRow(ctx, createWebhookRedelivery, id)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.SubscriptionID,
+		&i.EventID,
+		&i.EventType,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.ResponseCode,
+		&i.LastError,
+		&i.RedeliveryOf,
+		&i.CreatedAt,
+		&i.LastAttemptAt,
+		&i.DeliveredAt,
+		&i.NextAttemptAt,
+	)
+	return i, err
+}
+
+const createWebhookSubscription = `-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (
+  owner,
+  url,
+  event_types,
+  secret
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING id, owner, url, event_types, secret, status, consecutive_failures, disabled_at, created_at
+`
+
+type CreateWebhookSubscriptionParams struct {
+	Owner      string   `json:"owner"`
+	Url        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Secret     string   `json:"secret"`
+}
+
+func (q *Queries) CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error) {
+	row := q.db.QueryRow(ctx, createWebhookSubscription,
+		arg.Owner,
+		arg.Url,
+		arg.EventTypes,
+		arg.Secret,
+	)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Url,
+		&i.EventTypes,
+		&i.Secret,
+		&i.Status,
+		&i.ConsecutiveFailures,
+		&i.DisabledAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteWebhookSubscription = `-- name: DeleteWebhookSubscription :exec
+DELETE FROM webhook_subscriptions
+WHERE id = $1
+`
+
+func (q *Queries) DeleteWebhookSubscription(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, deleteWebhookSubscription, id)
+	return err
+}
+
+const enableWebhookSubscription = `-- name: EnableWebhookSubscription :one
+UPDATE webhook_subscriptions
+SET status = 'active', consecutive_failures = 0, disabled_at = NULL
+WHERE id = $1
+RETURNING id, owner, url, event_types, secret, status, consecutive_failures, disabled_at, created_at
+`
+
+func (q *Queries) EnableWebhookSubscription(ctx context.Context, id int64) (WebhookSubscription, error) {
+	row := q.db.QueryRow(ctx, enableWebhookSubscription, id)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Url,
+		&i.EventTypes,
+		&i.Secret,
+		&i.Status,
+		&i.ConsecutiveFailures,
+		&i.DisabledAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getWebhookDelivery = `-- name: GetWebhookDelivery :one
+SELECT id, subscription_id, event_id, event_type, payload, status, attempts, response_code, last_error, redelivery_of, created_at, last_attempt_at, delivered_at, next_attempt_at FROM webhook_deliveries
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error) {
+	row := q.db.QueryRow(ctx, getWebhookDelivery, id)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.SubscriptionID,
+		&i.EventID,
+		&i.EventType,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.ResponseCode,
+		&i.LastError,
+		&i.RedeliveryOf,
+		&i.CreatedAt,
+		&i.LastAttemptAt,
+		&i.DeliveredAt,
+		&i.NextAttemptAt,
+	)
+	return i, err
+}
+
+const getWebhookSubscription = `-- name: GetWebhookSubscription :one
+SELECT id, owner, url, event_types, secret, status, consecutive_failures, disabled_at, created_at FROM webhook_subscriptions
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetWebhookSubscription(ctx context.Context, id int64) (WebhookSubscription, error) {
+	row := q.db.QueryRow(ctx, getWebhookSubscription, id)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Url,
+		&i.EventTypes,
+		&i.Secret,
+		&i.Status,
+		&i.ConsecutiveFailures,
+		&i.DisabledAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listActiveWebhookSubscriptions = `-- name: ListActiveWebhookSubscriptions :many
+SELECT id, owner, url, event_types, secret, status, consecutive_failures, disabled_at, created_at FROM webhook_subscriptions
+WHERE owner = ANY($1::varchar[]) AND status = 'active'
+ORDER BY id
+`
+
+func (q *Queries) ListActiveWebhookSubscriptions(ctx context.Context, owners []string) ([]WebhookSubscription, error) {
+	rows, err := q.db.Query(ctx, listActiveWebhookSubscriptions, owners)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WebhookSubscription{}
+	for rows.Next() {
+		var i WebhookSubscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Url,
+			&i.EventTypes,
+			&i.Secret,
+			&i.Status,
+			&i.ConsecutiveFailures,
+			&i.DisabledAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOverdueWebhookDeliveries = `-- name: ListOverdueWebhookDeliveries :many
+SELECT id, subscription_id, event_id, event_type, payload, status, attempts, response_code, last_error, redelivery_of, created_at, last_attempt_at, delivered_at, next_attempt_at FROM webhook_deliveries
+WHERE status = 'pending' AND next_attempt_at < $1::timestamptz
+ORDER BY next_attempt_at
+LIMIT $2
+`
+
+type ListOverdueWebhookDeliveriesParams struct {
+	DueBefore time.Time `json:"due_before"`
+	Limit     int32     `json:"limit"`
+}
+
+// Pending deliveries whose next attempt was due before due_before.
+func (q *Queries) ListOverdueWebhookDeliveries(ctx context.Context, arg ListOverdueWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.Query(ctx, listOverdueWebhookDeliveries, arg.DueBefore, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WebhookDelivery{}
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.EventID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.ResponseCode,
+			&i.LastError,
+			&i.RedeliveryOf,
+			&i.CreatedAt,
+			&i.LastAttemptAt,
+			&i.DeliveredAt,
+			&i.NextAttemptAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookDeliveries = `-- name: ListWebhookDeliveries :many
+SELECT id, subscription_id, event_id, event_type, payload, status, attempts, response_code, last_error, redelivery_of, created_at, last_attempt_at, delivered_at, next_attempt_at FROM webhook_deliveries
+WHERE subscription_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3
+`
+
+type ListWebhookDeliveriesParams struct {
+	SubscriptionID int64 `json:"subscription_id"`
+	Limit          int32 `json:"limit"`
+	Offset         int32 `json:"offset"`
+}
+
+func (q *Queries) ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.Query(ctx, listWebhookDeliveries, arg.SubscriptionID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WebhookDelivery{}
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.EventID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.ResponseCode,
+			&i.LastError,
+			&i.RedeliveryOf,
+			&i.CreatedAt,
+			&i.LastAttemptAt,
+			&i.DeliveredAt,
+			&i.NextAttemptAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookDeliveriesAfter = `-- name: ListWebhookDeliveriesAfter :many
+SELECT id, subscription_id, event_id, event_type, payload, status, attempts, response_code, last_error, redelivery_of, created_at, last_attempt_at, delivered_at, next_attempt_at FROM webhook_deliveries
+WHERE subscription_id = $1
+  AND ($2::bigint = 0 OR id < $2::bigint)
+ORDER BY id DESC
+LIMIT $3
+`
+
+type ListWebhookDeliveriesAfterParams struct {
+	SubscriptionID int64 `json:"subscription_id"`
+	AfterID        int64 `json:"after_id"`
+	Limit          int32 `json:"limit"`
+}
+
+// Newest first, so a page continues with the ids below after_id; 0 starts at the newest.
+func (q *Queries) ListWebhookDeliveriesAfter(ctx context.Context, arg ListWebhookDeliveriesAfterParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.Query(ctx, listWebhookDeliveriesAfter, arg.SubscriptionID, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WebhookDelivery{}
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.EventID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.ResponseCode,
+			&i.LastError,
+			&i.RedeliveryOf,
+			&i.CreatedAt,
+			&i.LastAttemptAt,
+			&i.DeliveredAt,
+			&i.NextAttemptAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookSubscriptions = `-- name: ListWebhookSubscriptions :many
+SELECT id, owner, url, event_types, secret, status, consecutive_failures, disabled_at, created_at FROM webhook_subscriptions
+WHERE owner = $1
+ORDER BY id
+`
+
+func (q *Queries) ListWebhookSubscriptions(ctx context.Context, owner string) ([]WebhookSubscription, error) {
+	rows, err := q.db.Query(ctx, listWebhookSubscriptions, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WebhookSubscription{}
+	for rows.Next() {
+		var i WebhookSubscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Url,
+			&i.EventTypes,
+			&i.Secret,
+			&i.Status,
+			&i.ConsecutiveFailures,
+			&i.DisabledAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recordWebhookDeliveryAttempt = `-- name: RecordWebhookDeliveryAttempt :one
+UPDATE webhook_deliveries
+SET
+  status = $1,
+  attempts = attempts + 1,
+  response_code = $2,
+  last_error = $3,
+  last_attempt_at = now(),
+  next_attempt_at = $4,
+  delivered_at = CASE WHEN $1 = 'succeeded' THEN now() ELSE delivered_at END
+WHERE id = $5 AND status = 'pending' AND attempts = $6
+RETURNING id, subscription_id, event_id, event_type, payload, status, attempts, response_code, last_error, redelivery_of, created_at, last_attempt_at, delivered_at, next_attempt_at
+`
+
+type RecordWebhookDeliveryAttemptParams struct {
+	Status        string      `json:"status"`
+	ResponseCode  pgtype.Int4 `json:"response_code"`
+	LastError     pgtype.Text `json:"last_error"`
+	NextAttemptAt time.Time   `json:"next_attempt_at"`
+	ID            int64       `json:"id"`
+	Attempts      int32       `json:"attempts"`
+}
+
+// Records the outcome of an attempt; status stays pending while the delivery is retried.
+// Only the attempt the delivery expects is recorded, so a repeated attempt is not counted twice.
+func (q *Queries) RecordWebhookDeliveryAttempt(ctx context.Context, arg RecordWebhookDeliveryAttemptParams) (WebhookDelivery, error) {
+	row := q.db.QueryRow(ctx, recordWebhookDeliveryAttempt,
+		arg.Status,
+		arg.ResponseCode,
+		arg.LastError,
+		arg.NextAttemptAt,
+		arg.ID,
+		arg.Attempts,
+	)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.SubscriptionID,
+		&i.EventID,
+		&i.EventType,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.ResponseCode,
+		&i.LastError,
+		&i.RedeliveryOf,
+		&i.CreatedAt,
+		&i.LastAttemptAt,
+		&i.DeliveredAt,
+		&i.NextAttemptAt,
+	)
+	return i, err
+}
+
+const recordWebhookFailure = `-- name: RecordWebhookFailure :one
+UPDATE webhook_subscriptions
+SET
+  consecutive_failures = consecutive_failures + 1,
+  status = CASE WHEN consecutive_failures + 1 >= $1::int THEN 'disabled' ELSE status END,
+  disabled_at = CASE WHEN consecutive_failures + 1 >= $1::int AND status = 'active' THEN now() ELSE disabled_at END
+WHERE id = $2
+RETURNING id, owner, url, event_types, secret, status, consecutive_failures, disabled_at, created_at
+`
+
+type RecordWebhookFailureParams struct {
+	MaxFailures int32 `json:"max_failures"`
+	ID          int64 `json:"id"`
+}
+
+// Disables the subscription once it failed max_failures times in a row.
+func (q *Queries) RecordWebhookFailure(ctx context.Context, arg RecordWebhookFailureParams) (WebhookSubscription, error) {
+	row := q.db.QueryRow(ctx, recordWebhookFailure, arg.MaxFailures, arg.ID)
+	var i WebhookSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Url,
+		&i.EventTypes,
+		&i.Secret,
+		&i.Status,
+		&i.ConsecutiveFailures,
+		&i.DisabledAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const recordWebhookSuccess = `-- name: RecordWebhookSuccess :exec
+UPDATE webhook_subscriptions
+SET consecutive_failures = 0
+WHERE id = $1
+`
+
+func (q *Queries) RecordWebhookSuccess(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, recordWebhookSuccess, id)
+	return err
+}